@@ -0,0 +1,10 @@
+#!/bin/bash
+CURRENT_PATH="$(cd "$(dirname "${BASH_SOURCE[0]}")" >/dev/null 2>&1 && pwd)"
+
+# usage: configure_scheduler_pwr_n_transfer.sh THRESHOLD
+
+# shellcheck disable=SC1090
+source "$CURRENT_PATH"/env/bin/activate
+"$CURRENT_PATH"/env/bin/python "$CURRENT_PATH"/configure_scheduler.py \
+  --hosts-file "$CURRENT_PATH"/hosts.txt \
+  --scheduler "PowerOfNTransferScheduler 1 $1 1"
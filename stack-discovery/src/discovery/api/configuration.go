@@ -67,6 +67,8 @@ func ConfigurationSet(w http.ResponseWriter, r *http.Request) {
 	config2.SetMachineIp(newConfiguration.MachineIp)
 	config2.SetMachineFogNetId(newConfiguration.MachineGroupName)
 	config2.SetInitServers(newConfiguration.InitServers)
+	config2.SetMachineProtocolVersion(newConfiguration.ProtocolVersion)
+	config2.SetMachineCapabilities(newConfiguration.Capabilities)
 	db.AddInitServers(newConfiguration.InitServers)
 
 	// save configuration to file
@@ -23,26 +23,33 @@ import (
 	"discovery/db"
 	"discovery/errors"
 	"discovery/log"
+	"discovery/mtls"
+	"discovery/signature"
+	"discovery/swim"
 	"discovery/types"
 	"discovery/utils"
 	"encoding/json"
 	"io"
 	"net"
 	"net/http"
+	"strconv"
 )
 
 func ServersGetList(w http.ResponseWriter, r *http.Request) {
 	// add the requestor's ip if it is a machine
-	if r.Header.Get("User-Agent") == utils.UserAgentMachine {
+	if isMachine(r) {
 		clientIp := net.ParseIP(utils.IsolateIPFromPort(r.Header.Get(config.GetParamIp)))
 		if len(clientIp) > 0 {
 			log.Log.Debug("Machine %s requested list, adding/updating my list", clientIp)
+			protocolVersion, _ := strconv.Atoi(r.Header.Get(config.GetParamProtocolVersion))
 			err := db.MachineAdd(&types.Machine{
-				IP:        r.Header.Get(config.GetParamIp),
-				Name:      r.Header.Get(config.GetParamName),
-				GroupName: r.Header.Get(config.GetParamGroupName),
-				Alive:     true,
-				DeadPolls: 0,
+				IP:              r.Header.Get(config.GetParamIp),
+				Name:            r.Header.Get(config.GetParamName),
+				GroupName:       r.Header.Get(config.GetParamGroupName),
+				Alive:           true,
+				DeadPolls:       0,
+				ProtocolVersion: protocolVersion,
+				Capabilities:    types.DecodeCapabilities(r.Header.Get(config.GetParamCapabilities)),
 			}, true)
 			if err != nil {
 				log.Log.Debugf("Cannot add machine %s: %s", r.Header.Get(config.GetParamIp), err.Error())
@@ -73,10 +80,30 @@ func ServersGetList(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set(config.GetParamIp, config.GetMachineIp())
 	w.Header().Set(config.GetParamName, config.GetMachineId())
 	w.Header().Set(config.GetParamGroupName, config.GetMachineGroupName())
+	w.Header().Set(config.GetParamProtocolVersion, strconv.Itoa(config.GetMachineProtocolVersion()))
+	w.Header().Set(config.GetParamCapabilities, types.EncodeCapabilities(config.GetMachineCapabilities()))
 
 	_, _ = io.WriteString(w, string(out))
 }
 
+// isMachine tells if the request comes from another machine, which is authenticated with its certificate and its
+// signature when mtls and signatures are enabled, and with the user agent otherwise
+func isMachine(r *http.Request) bool {
+	if mtls.Enabled() && !mtls.IsPeerVerified(r.TLS) {
+		return false
+	}
+	if signature.Enabled() {
+		if err := signature.VerifyRequest(r); err != nil {
+			log.Log.Debugf("Request from %s is not signed by a machine: %s", r.RemoteAddr, err)
+			return false
+		}
+	}
+	if mtls.Enabled() || signature.Enabled() {
+		return true
+	}
+	return r.Header.Get("User-Agent") == utils.UserAgentMachine
+}
+
 func ServersReset(w http.ResponseWriter, r *http.Request) {
 	err := db.MachineRemoveAll()
 	if err != nil {
@@ -84,6 +111,9 @@ func ServersReset(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(500)
 		return
 	}
+	if config.GetMembership() == config.MembershipSwim {
+		swim.Reset()
+	}
 
 	w.WriteHeader(200)
 }
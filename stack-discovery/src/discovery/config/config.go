@@ -31,6 +31,8 @@ const ConfigurationFileName = "p2p_faas-discovery.json"
 const GetParamIp = "p2pfaas-machine-ip"
 const GetParamName = "p2pfaas-machine-name"
 const GetParamGroupName = "p2pfaas-machine-group-name"
+const GetParamProtocolVersion = "p2pfaas-machine-protocol-version"
+const GetParamCapabilities = "p2pfaas-machine-capabilities"
 
 const DefaultDataPath = "/data"
 const DefaultListeningHost = "0.0.0.0"
@@ -39,6 +41,29 @@ const DefaultPollTime = 120      // seconds
 const DefaultPollTimeoutTime = 5 // seconds
 const DefaultIfaceName = "eth0"
 
+// DefaultTlsCaPath is the certificate of the cluster CA, which signs the certificates of all the services
+const DefaultTlsCaPath = "/tls/ca.crt"
+const DefaultTlsCertPath = "/tls/tls.crt"
+const DefaultTlsKeyPath = "/tls/tls.key"
+const DefaultTlsReloadInterval = 30000 // ms
+
+// DefaultSignatureWindow is the maximum age of a signed request, older requests are rejected as replays
+const DefaultSignatureWindow = 30000 // ms
+
+// MembershipPolling polls every known machine over http and exchanges the machines lists with it
+const MembershipPolling = "polling"
+
+// MembershipSwim probes random machines over udp with the SWIM protocol and piggybacks the membership changes on the
+// probes
+const MembershipSwim = "swim"
+
+const DefaultMembership = MembershipPolling
+const DefaultSwimPort = 19000
+const DefaultSwimProbeInterval = 1000 // ms
+const DefaultSwimProbeTimeout = 300   // ms
+const DefaultSwimIndirectProbes = 3
+const DefaultSwimSuspicionTimeout = 5000 // ms
+
 // DefaultMachineDeadPollsRemovingThreshold tells the number of times we need to poll the machine for removing it from the db
 const DefaultMachineDeadPollsRemovingThreshold = 20
 
@@ -51,6 +76,19 @@ const EnvListeningPort = "P2PFAAS_LISTENING_PORT"
 const EnvPollTimeout = "P2PFAAS_POLL_TIMEOUT"
 const EnvMachineDeadPollsRemovingThreshold = "P2PFAAS_MACHINE_DEAD_POLLS_THRESHOLD"
 const EnvDefaultIface = "P2PFAAS_DEFAULT_IFACE"
+const EnvTlsEnabled = "P2PFAAS_TLS_ENABLED"
+const EnvTlsCaPath = "P2PFAAS_TLS_CA_PATH"
+const EnvTlsCertPath = "P2PFAAS_TLS_CERT_PATH"
+const EnvTlsKeyPath = "P2PFAAS_TLS_KEY_PATH"
+const EnvTlsReloadInterval = "P2PFAAS_TLS_RELOAD_INTERVAL_MS"
+const EnvClusterKeys = "P2PFAAS_CLUSTER_KEYS"
+const EnvSignatureWindow = "P2PFAAS_SIGNATURE_WINDOW_MS"
+const EnvMembership = "P2PFAAS_MEMBERSHIP"
+const EnvSwimPort = "P2PFAAS_SWIM_PORT"
+const EnvSwimProbeInterval = "P2PFAAS_SWIM_PROBE_INTERVAL_MS"
+const EnvSwimProbeTimeout = "P2PFAAS_SWIM_PROBE_TIMEOUT_MS"
+const EnvSwimIndirectProbes = "P2PFAAS_SWIM_INDIRECT_PROBES"
+const EnvSwimSuspicionTimeout = "P2PFAAS_SWIM_SUSPICION_TIMEOUT_MS"
 
 const RunningEnvironmentProduction = "production"
 const RunningEnvironmentDevelopment = "development"
@@ -26,6 +26,7 @@ import (
 	"io/ioutil"
 	"os"
 	"strconv"
+	"strings"
 )
 
 type ConfigurationStatic struct {
@@ -37,6 +38,27 @@ type ConfigurationStatic struct {
 	MachineDeadPollsRemovingThreshold uint   `json:"machine_dead_polls_removing_threshold" bson:"machine_dead_polls_removing_threshold"`
 	RunningEnvironment                string `json:"running_environment" bson:"running_environment"`
 	DefaultIface                      string `json:"default_iface" bson:"default_iface"`
+	TlsEnabled                        bool   `json:"tls_enabled" bson:"tls_enabled"`
+	TlsCaPath                         string `json:"tls_ca_path" bson:"tls_ca_path"`
+	TlsCertPath                       string `json:"tls_cert_path" bson:"tls_cert_path"`
+	TlsKeyPath                        string `json:"tls_key_path" bson:"tls_key_path"`
+	TlsReloadInterval                 uint   `json:"tls_reload_interval" bson:"tls_reload_interval"`
+	// ClusterKeys are secret, so they are never serialized
+	ClusterKeys     []ClusterKey `json:"-" bson:"-"`
+	SignatureWindow uint         `json:"signature_window" bson:"signature_window"`
+	// Membership is the engine which keeps the list of the machines, polling or swim
+	Membership           string `json:"membership" bson:"membership"`
+	SwimPort             uint   `json:"swim_port" bson:"swim_port"`
+	SwimProbeInterval    uint   `json:"swim_probe_interval" bson:"swim_probe_interval"`
+	SwimProbeTimeout     uint   `json:"swim_probe_timeout" bson:"swim_probe_timeout"`
+	SwimIndirectProbes   uint   `json:"swim_indirect_probes" bson:"swim_indirect_probes"`
+	SwimSuspicionTimeout uint   `json:"swim_suspicion_timeout" bson:"swim_suspicion_timeout"`
+}
+
+// ClusterKey is a secret shared by all the nodes of the cluster for signing the requests between them
+type ClusterKey struct {
+	Id     string
+	Secret string
 }
 
 type ConfigurationDynamic struct {
@@ -44,6 +66,9 @@ type ConfigurationDynamic struct {
 	MachineId        string   `json:"machine_id" bson:"machine_id"`
 	MachineGroupName string   `json:"machine_group_name" bson:"machine_group_name"`
 	InitServers      []string `json:"init_servers" bson:"init_servers"`
+	// ProtocolVersion and Capabilities are the ones of the scheduler of the machine, which sets them at its start
+	ProtocolVersion int      `json:"protocol_version" bson:"protocol_version"`
+	Capabilities    []string `json:"capabilities" bson:"capabilities"`
 }
 
 /*
@@ -119,6 +144,97 @@ func InitConfigurationStatic() {
 			configurationStatic.MachineDeadPollsRemovingThreshold = uint(listPort)
 		}
 	}
+
+	if envVar := os.Getenv(EnvTlsEnabled); envVar != "" {
+		enabled, err := strconv.ParseBool(envVar)
+		if err == nil {
+			configurationStatic.TlsEnabled = enabled
+		}
+	}
+
+	if envVar := os.Getenv(EnvTlsCaPath); envVar != "" {
+		configurationStatic.TlsCaPath = envVar
+	}
+
+	if envVar := os.Getenv(EnvTlsCertPath); envVar != "" {
+		configurationStatic.TlsCertPath = envVar
+	}
+
+	if envVar := os.Getenv(EnvTlsKeyPath); envVar != "" {
+		configurationStatic.TlsKeyPath = envVar
+	}
+
+	if envVar := os.Getenv(EnvTlsReloadInterval); envVar != "" {
+		interval, err := strconv.Atoi(envVar)
+		if err == nil && interval >= 0 {
+			configurationStatic.TlsReloadInterval = uint(interval)
+		}
+	}
+
+	// keys are in the form id:secret, the first one signs and all of them verify, so that keys can be rotated
+	if envVar := os.Getenv(EnvClusterKeys); envVar != "" {
+		for _, key := range strings.Split(envVar, ",") {
+			separator := strings.Index(key, ":")
+			if separator <= 0 || separator == len(key)-1 {
+				log.Log.Warningf("Cluster key must be in the form id:secret, ignoring it")
+				continue
+			}
+			configurationStatic.ClusterKeys = append(configurationStatic.ClusterKeys, ClusterKey{
+				Id:     key[:separator],
+				Secret: key[separator+1:],
+			})
+		}
+	}
+
+	if envVar := os.Getenv(EnvSignatureWindow); envVar != "" {
+		window, err := strconv.Atoi(envVar)
+		if err == nil && window > 0 {
+			configurationStatic.SignatureWindow = uint(window)
+		}
+	}
+
+	if envVar := os.Getenv(EnvMembership); envVar != "" {
+		if envVar == MembershipPolling || envVar == MembershipSwim {
+			configurationStatic.Membership = envVar
+		} else {
+			log.Log.Warningf("Membership %s is not valid, using %s", envVar, configurationStatic.Membership)
+		}
+	}
+
+	if envVar := os.Getenv(EnvSwimPort); envVar != "" {
+		port, err := strconv.Atoi(envVar)
+		if err == nil && port > 0 {
+			configurationStatic.SwimPort = uint(port)
+		}
+	}
+
+	if envVar := os.Getenv(EnvSwimProbeInterval); envVar != "" {
+		interval, err := strconv.Atoi(envVar)
+		if err == nil && interval > 0 {
+			configurationStatic.SwimProbeInterval = uint(interval)
+		}
+	}
+
+	if envVar := os.Getenv(EnvSwimProbeTimeout); envVar != "" {
+		timeout, err := strconv.Atoi(envVar)
+		if err == nil && timeout > 0 {
+			configurationStatic.SwimProbeTimeout = uint(timeout)
+		}
+	}
+
+	if envVar := os.Getenv(EnvSwimIndirectProbes); envVar != "" {
+		probes, err := strconv.Atoi(envVar)
+		if err == nil && probes >= 0 {
+			configurationStatic.SwimIndirectProbes = uint(probes)
+		}
+	}
+
+	if envVar := os.Getenv(EnvSwimSuspicionTimeout); envVar != "" {
+		timeout, err := strconv.Atoi(envVar)
+		if err == nil && timeout > 0 {
+			configurationStatic.SwimSuspicionTimeout = uint(timeout)
+		}
+	}
 }
 
 // InitConfigurationDynamic prepare the configuration object, returns if config is read from file
@@ -153,6 +269,12 @@ func GetMachineId() string {
 func GetMachineGroupName() string {
 	return configurationDynamic.MachineGroupName
 }
+func GetMachineProtocolVersion() int {
+	return configurationDynamic.ProtocolVersion
+}
+func GetMachineCapabilities() []string {
+	return configurationDynamic.Capabilities
+}
 func GetDataPath() string {
 	return configurationStatic.DataPath
 }
@@ -180,9 +302,49 @@ func GetRunningEnvironment() string {
 func GetDefaultIface() string {
 	return configurationStatic.DefaultIface
 }
+func GetTlsEnabled() bool {
+	return configurationStatic.TlsEnabled
+}
+func GetTlsCaPath() string {
+	return configurationStatic.TlsCaPath
+}
+func GetTlsCertPath() string {
+	return configurationStatic.TlsCertPath
+}
+func GetTlsKeyPath() string {
+	return configurationStatic.TlsKeyPath
+}
+func GetTlsReloadInterval() uint {
+	return configurationStatic.TlsReloadInterval
+}
+func GetClusterKeys() []ClusterKey {
+	return configurationStatic.ClusterKeys
+}
+func GetSignatureWindow() uint {
+	return configurationStatic.SignatureWindow
+}
+func GetMembership() string {
+	return configurationStatic.Membership
+}
+func GetSwimPort() uint {
+	return configurationStatic.SwimPort
+}
+func GetSwimProbeInterval() uint {
+	return configurationStatic.SwimProbeInterval
+}
+func GetSwimProbeTimeout() uint {
+	return configurationStatic.SwimProbeTimeout
+}
+func GetSwimIndirectProbes() uint {
+	return configurationStatic.SwimIndirectProbes
+}
+func GetSwimSuspicionTimeout() uint {
+	return configurationStatic.SwimSuspicionTimeout
+}
 
 func GetConfigurationDynamicCopy() *ConfigurationDynamic {
 	copiedConf := *configurationDynamic
+	copiedConf.Capabilities = append([]string{}, configurationDynamic.Capabilities...)
 
 	return &copiedConf
 }
@@ -212,6 +374,12 @@ func SetMachineFogNetId(id string) {
 func SetInitServers(servers []string) {
 	configurationDynamic.InitServers = servers
 }
+func SetMachineProtocolVersion(version int) {
+	configurationDynamic.ProtocolVersion = version
+}
+func SetMachineCapabilities(capabilities []string) {
+	configurationDynamic.Capabilities = capabilities
+}
 
 /*
  * Utils
@@ -258,6 +426,19 @@ func GetDefaultConfigurationStatic() *ConfigurationStatic {
 		PollTimeout:                       DefaultPollTimeoutTime,
 		MachineDeadPollsRemovingThreshold: DefaultMachineDeadPollsRemovingThreshold,
 		DefaultIface:                      DefaultIfaceName,
+		TlsEnabled:                        false,
+		TlsCaPath:                         DefaultTlsCaPath,
+		TlsCertPath:                       DefaultTlsCertPath,
+		TlsKeyPath:                        DefaultTlsKeyPath,
+		TlsReloadInterval:                 DefaultTlsReloadInterval,
+		ClusterKeys:                       []ClusterKey{},
+		SignatureWindow:                   DefaultSignatureWindow,
+		Membership:                        DefaultMembership,
+		SwimPort:                          DefaultSwimPort,
+		SwimProbeInterval:                 DefaultSwimProbeInterval,
+		SwimProbeTimeout:                  DefaultSwimProbeTimeout,
+		SwimIndirectProbes:                DefaultSwimIndirectProbes,
+		SwimSuspicionTimeout:              DefaultSwimSuspicionTimeout,
 		RunningEnvironment:                os.Getenv(EnvRunningEnvironment),
 	}
 	return conf
@@ -269,6 +450,8 @@ func GetDefaultConfigurationDynamic() *ConfigurationDynamic {
 		MachineIp:        "",
 		MachineId:        "",
 		MachineGroupName: "",
+		ProtocolVersion:  0,
+		Capabilities:     []string{},
 	}
 	return conf
 }
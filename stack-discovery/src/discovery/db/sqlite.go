@@ -79,12 +79,23 @@ func initDb() error {
 								ping real, 
 								last_update integer, 
 								alive integer, 
-								dead_polls integer
+								dead_polls integer,
+								protocol_version integer default 0,
+								capabilities text default ''
                             )`)
 	if err != nil {
 		log.Log.Errorf("Cannot init machines: %s", types.MachinesCollectionName)
 		return err
 	}
+
+	// databases created by older versions miss the protocol columns
+	for _, column := range []string{"protocol_version integer default 0", "capabilities text default ''"} {
+		_, err = db.Exec("alter table machines add column " + column)
+		if err != nil && !strings.Contains(err.Error(), "duplicate column") {
+			log.Log.Errorf("Cannot add column to machines: %s", err.Error())
+			return err
+		}
+	}
 	return nil
 }
 
@@ -104,6 +115,11 @@ func MachineAdd(machine *types.Machine, declareAlive bool) error {
 	machineRetrieved, err := MachineGet(machine.IP)
 	if machineRetrieved != nil && declareAlive {
 		log.Log.Debugf("Machine %s already exists", machine.IP)
+		// lists of older machines do not carry the protocol, so the known one is kept
+		if machine.ProtocolVersion == 0 {
+			machine.ProtocolVersion = machineRetrieved.ProtocolVersion
+			machine.Capabilities = machineRetrieved.Capabilities
+		}
 		// if yes, set machine to alive and update
 		machine.Alive = true
 		machine.DeadPolls = 0
@@ -125,12 +141,12 @@ func MachineAdd(machine *types.Machine, declareAlive bool) error {
 		log.Log.Errorf("Cannot begin transaction: %s", err.Error())
 		return err
 	}
-	stmt, err := db.Prepare("insert into machines (ip, name, group_name, ping, last_update, alive, dead_polls) values (?,?,?,?,?,?,?)")
+	stmt, err := db.Prepare("insert into machines (ip, name, group_name, ping, last_update, alive, dead_polls, protocol_version, capabilities) values (?,?,?,?,?,?,?,?,?)")
 	if err != nil {
 		log.Log.Errorf("Cannot prepare query: %s", err.Error())
 		return err
 	}
-	_, err = stmt.Exec(machine.IP, machine.Name, machine.GroupName, machine.Ping, time.Now().Unix(), machine.Alive, machine.DeadPolls)
+	_, err = stmt.Exec(machine.IP, machine.Name, machine.GroupName, machine.Ping, time.Now().Unix(), machine.Alive, machine.DeadPolls, machine.ProtocolVersion, types.EncodeCapabilities(machine.Capabilities))
 	if err != nil {
 		log.Log.Errorf("Cannot execute query: %s", err.Error())
 		return err
@@ -197,7 +213,9 @@ func MachineUpdate(machine *types.Machine) (int64, error) {
                     ping = ?, 
                     last_update = ?, 
                     alive = ?, 
-                    dead_polls = ? 
+                    dead_polls = ?, 
+                    protocol_version = ?, 
+                    capabilities = ? 
 				where 
 				      ip = ?`,
 		machine.Name,
@@ -206,6 +224,8 @@ func MachineUpdate(machine *types.Machine) (int64, error) {
 		machine.LastUpdate,
 		machine.Alive,
 		machine.DeadPolls,
+		machine.ProtocolVersion,
+		types.EncodeCapabilities(machine.Capabilities),
 		machine.IP,
 	)
 	if err != nil {
@@ -244,11 +264,13 @@ func machinesParseRows(rows *sql.Rows) ([]types.Machine, error) {
 	for rows.Next() {
 		totalRows += 1
 		var tempMachine types.Machine
-		err = rows.Scan(&tempMachine.ID, &tempMachine.IP, &tempMachine.Name, &tempMachine.GroupName, &tempMachine.Ping, &tempMachine.LastUpdate, &tempMachine.Alive, &tempMachine.DeadPolls)
+		var capabilities string
+		err = rows.Scan(&tempMachine.ID, &tempMachine.IP, &tempMachine.Name, &tempMachine.GroupName, &tempMachine.Ping, &tempMachine.LastUpdate, &tempMachine.Alive, &tempMachine.DeadPolls, &tempMachine.ProtocolVersion, &capabilities)
 		if err != nil {
 			log.Log.Errorf("Cannot scan row: %s", err.Error())
 			continue
 		}
+		tempMachine.Capabilities = types.DecodeCapabilities(capabilities)
 		machines = append(machines, tempMachine)
 	}
 	log.Log.Debugf("Total rows: %d", totalRows)
@@ -64,6 +64,18 @@ func DeclarePollSucceeded(machine *types.Machine, ping float64) {
 	log.Log.Debugf("Poll for machine %s succeeded", machine.IP)
 }
 
+// DeclareMachineDead declares the machine as not alive, so that it is no more returned in the list
+func DeclareMachineDead(machine *types.Machine) {
+	machine.Alive = false
+	machine.LastUpdate = time.Now().Unix()
+
+	_, err := MachineUpdate(machine)
+	if err != nil {
+		log.Log.Warningf("Could not update the machine %s", machine.IP)
+	}
+	log.Log.Debugf("Machine %s declared dead", machine.IP)
+}
+
 /*
  * Core
  */
@@ -20,10 +20,13 @@
 package main
 
 import (
+	"crypto/tls"
 	"discovery/api"
 	"discovery/config"
 	"discovery/db"
 	"discovery/log"
+	"discovery/mtls"
+	"discovery/swim"
 	"discovery/watcher"
 	"fmt"
 	"github.com/gorilla/mux"
@@ -68,15 +71,29 @@ func server() {
 		Handler: router,
 	}
 
-	log.Log.Infof("Started listening on %s:%d", config.GetListeningHost(), config.GetListeningPort())
-	err := server.ListenAndServe()
+	var err error
+	if mtls.Enabled() {
+		// clients are not required to present a certificate, only machines with a valid one are added to the list
+		server.TLSConfig = mtls.ServerConfig(tls.VerifyClientCertIfGiven, []string{"h2", "http/1.1"})
+
+		log.Log.Infof("Started listening with mutual tls on %s:%d", config.GetListeningHost(), config.GetListeningPort())
+		err = server.ListenAndServeTLS("", "")
+	} else {
+		log.Log.Infof("Started listening on %s:%d", config.GetListeningHost(), config.GetListeningPort())
+		err = server.ListenAndServe()
+	}
 
 	log.Log.Fatalf("Error while starting server: %s", err)
 	wg.Done()
 }
 
 func watchd() {
-	log.Log.Infof("Watcher started")
-	watcher.PollingLooper()
+	if config.GetMembership() == config.MembershipSwim {
+		log.Log.Infof("Swim membership started")
+		swim.Start()
+	} else {
+		log.Log.Infof("Watcher started")
+		watcher.PollingLooper()
+	}
 	wg.Done()
 }
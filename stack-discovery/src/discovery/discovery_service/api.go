@@ -21,11 +21,12 @@ package discovery_service
 
 import (
 	"discovery/config"
+	"discovery/mtls"
 	"fmt"
 )
 
 func GetBaseUrlApi(ip string) string {
-	return fmt.Sprintf("http://%s:%d", ip, config.GetListeningPort())
+	return fmt.Sprintf("%s://%s:%d", mtls.HttpScheme(), ip, config.GetListeningPort())
 }
 
 func GetServerListApi(ip string) string {
@@ -0,0 +1,215 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package mtls implements the optional mutual tls between the services of the cluster. All the services present a
+// certificate signed by the cluster CA and verify the one of the other side against it. The files are watched and
+// reloaded when they change, so certificates can be rotated without restarting.
+package mtls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"discovery/config"
+	"discovery/log"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+)
+
+type certificates struct {
+	certificate *tls.Certificate
+	caPool      *x509.CertPool
+	modTimes    [3]time.Time
+}
+
+var current *certificates
+var currentMutex sync.RWMutex
+
+func init() {
+	if !config.GetTlsEnabled() {
+		return
+	}
+
+	if err := reload(); err != nil {
+		log.Log.Errorf("Cannot load tls certificates: %s", err)
+	}
+	if config.GetTlsReloadInterval() > 0 {
+		go reloader()
+	}
+}
+
+// Enabled tells if the services communicate with mutual tls
+func Enabled() bool {
+	return config.GetTlsEnabled()
+}
+
+// HttpScheme returns the scheme of the urls of the other services
+func HttpScheme() string {
+	if Enabled() {
+		return "https"
+	}
+	return "http"
+}
+
+// ServerConfig returns the tls configuration of a server, the current certificates are used at every handshake.
+// Servers which are called also by end clients request the client certificate without requiring it, and check
+// IsPeerVerified on the apis reserved to the other services.
+func ServerConfig(clientAuth tls.ClientAuthType, nextProtos []string) *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			certs, err := get()
+			if err != nil {
+				return nil, err
+			}
+			return &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*certs.certificate},
+				ClientCAs:    certs.caPool,
+				ClientAuth:   clientAuth,
+				NextProtos:   nextProtos,
+			}, nil
+		},
+	}
+}
+
+// ClientConfig returns the tls configuration of a client, which presents the current certificate and accepts only
+// servers with a certificate signed by the cluster CA. Services are addressed by ip, so the host name is not checked.
+func ClientConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			certs, err := get()
+			if err != nil {
+				return nil, err
+			}
+			return certs.certificate, nil
+		},
+		// the chain is verified in VerifyConnection against the current CA
+		InsecureSkipVerify: true,
+		VerifyConnection:   verifyServer,
+	}
+}
+
+// IsPeerVerified tells if the other side of the connection presented a certificate signed by the cluster CA
+func IsPeerVerified(state *tls.ConnectionState) bool {
+	return state != nil && len(state.VerifiedChains) > 0
+}
+
+func verifyServer(state tls.ConnectionState) error {
+	if len(state.PeerCertificates) == 0 {
+		return fmt.Errorf("no server certificate")
+	}
+	certs, err := get()
+	if err != nil {
+		return err
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range state.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err = state.PeerCertificates[0].Verify(x509.VerifyOptions{
+		Roots:         certs.caPool,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	})
+	return err
+}
+
+func get() (*certificates, error) {
+	currentMutex.RLock()
+	defer currentMutex.RUnlock()
+
+	if current == nil {
+		return nil, fmt.Errorf("tls certificates not loaded")
+	}
+	return current, nil
+}
+
+// reloader polls the certificate files and reloads them when one changes
+func reloader() {
+	for {
+		time.Sleep(time.Duration(config.GetTlsReloadInterval()) * time.Millisecond)
+
+		modTimes, err := getModTimes()
+		if err != nil {
+			log.Log.Warningf("Cannot stat tls certificates: %s", err)
+			continue
+		}
+
+		currentMutex.RLock()
+		changed := current == nil || current.modTimes != modTimes
+		currentMutex.RUnlock()
+
+		if !changed {
+			continue
+		}
+		// the previous certificates are kept if the new ones are not valid
+		if err = reload(); err != nil {
+			log.Log.Errorf("Cannot reload tls certificates: %s", err)
+			continue
+		}
+		log.Log.Infof("Reloaded tls certificates")
+	}
+}
+
+func reload() error {
+	modTimes, err := getModTimes()
+	if err != nil {
+		return err
+	}
+
+	certificate, err := tls.LoadX509KeyPair(config.GetTlsCertPath(), config.GetTlsKeyPath())
+	if err != nil {
+		return err
+	}
+
+	caPem, err := ioutil.ReadFile(config.GetTlsCaPath())
+	if err != nil {
+		return err
+	}
+	caPool := x509.NewCertPool()
+	if !caPool.AppendCertsFromPEM(caPem) {
+		return fmt.Errorf("no valid certificate in %s", config.GetTlsCaPath())
+	}
+
+	currentMutex.Lock()
+	current = &certificates{
+		certificate: &certificate,
+		caPool:      caPool,
+		modTimes:    modTimes,
+	}
+	currentMutex.Unlock()
+
+	return nil
+}
+
+func getModTimes() ([3]time.Time, error) {
+	var modTimes [3]time.Time
+	for i, path := range []string{config.GetTlsCaPath(), config.GetTlsCertPath(), config.GetTlsKeyPath()} {
+		info, err := os.Stat(path)
+		if err != nil {
+			return modTimes, err
+		}
+		modTimes[i] = info.ModTime()
+	}
+	return modTimes, nil
+}
@@ -0,0 +1,53 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package signature
+
+import "fmt"
+
+type ErrorMissing struct{}
+
+func (ErrorMissing) Error() string {
+	return "request is not signed"
+}
+
+type ErrorUnknownKey struct {
+	keyId string
+}
+
+func (e ErrorUnknownKey) Error() string {
+	return fmt.Sprintf("request is signed with unknown key %s", e.keyId)
+}
+
+type ErrorExpired struct{}
+
+func (ErrorExpired) Error() string {
+	return "request signature is expired"
+}
+
+type ErrorInvalid struct{}
+
+func (ErrorInvalid) Error() string {
+	return "request signature is not valid"
+}
+
+type ErrorReplayed struct{}
+
+func (ErrorReplayed) Error() string {
+	return "request has been already received"
+}
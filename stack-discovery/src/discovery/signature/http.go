@@ -0,0 +1,70 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package signature
+
+import (
+	"bytes"
+	"discovery/utils"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+)
+
+const HttpHeaderKeyId = "X-P2pfaas-Signature-Key"
+const HttpHeaderTimestamp = "X-P2pfaas-Signature-Timestamp"
+const HttpHeaderNonce = "X-P2pfaas-Signature-Nonce"
+const HttpHeaderSignature = "X-P2pfaas-Signature"
+
+// Headers returns the signature headers of a request to the url
+func Headers(method string, rawUrl string, body []byte) ([]utils.Header, error) {
+	parsedUrl, err := url.Parse(rawUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	signature := Sign(method, parsedUrl.RequestURI(), body)
+	return []utils.Header{
+		{Field: HttpHeaderKeyId, Payload: signature.KeyId},
+		{Field: HttpHeaderTimestamp, Payload: signature.Timestamp},
+		{Field: HttpHeaderNonce, Payload: signature.Nonce},
+		{Field: HttpHeaderSignature, Payload: signature.Value},
+	}, nil
+}
+
+// VerifyRequest checks the signature of the request, the body is read and replaced so that it can be read again
+func VerifyRequest(req *http.Request) error {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	signature := &Signature{
+		KeyId:     req.Header.Get(HttpHeaderKeyId),
+		Timestamp: req.Header.Get(HttpHeaderTimestamp),
+		Nonce:     req.Header.Get(HttpHeaderNonce),
+		Value:     req.Header.Get(HttpHeaderSignature),
+	}
+	return Verify(signature, req.Method, req.URL.RequestURI(), body)
+}
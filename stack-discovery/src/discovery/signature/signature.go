@@ -0,0 +1,148 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package signature implements the signing of the requests between the nodes with a key shared by the cluster, a
+// lighter alternative to mutual tls. The signature covers the method, the path, the timestamp, a nonce and the digest
+// of the body, requests older than the window or already seen are rejected as replays.
+package signature
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"discovery/config"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Signature are the signing fields of a request
+type Signature struct {
+	KeyId     string
+	Timestamp string // unix ms
+	Nonce     string
+	Value     string
+}
+
+var seenNonces = make(map[string]time.Time)
+var seenNoncesMutex sync.Mutex
+var seenNoncesLastPurge time.Time
+
+// Enabled tells if the requests between the nodes are signed
+func Enabled() bool {
+	return len(config.GetClusterKeys()) > 0
+}
+
+// Sign signs the request with the first cluster key
+func Sign(method string, uri string, body []byte) *Signature {
+	key := config.GetClusterKeys()[0]
+
+	nonce := make([]byte, 16)
+	_, _ = rand.Read(nonce)
+
+	signature := &Signature{
+		KeyId:     key.Id,
+		Timestamp: strconv.FormatInt(time.Now().UnixNano()/int64(time.Millisecond), 10),
+		Nonce:     hex.EncodeToString(nonce),
+	}
+	signature.Value = compute(key.Secret, method, uri, body, signature)
+	return signature
+}
+
+// Verify checks that the request is signed with one of the cluster keys, it is not older than the window and it has
+// not been seen yet
+func Verify(signature *Signature, method string, uri string, body []byte) error {
+	if signature == nil || signature.KeyId == "" || signature.Value == "" {
+		return ErrorMissing{}
+	}
+
+	secret, exists := getSecret(signature.KeyId)
+	if !exists {
+		return ErrorUnknownKey{keyId: signature.KeyId}
+	}
+
+	timestamp, err := strconv.ParseInt(signature.Timestamp, 10, 64)
+	if err != nil {
+		return ErrorInvalid{}
+	}
+	signedAt := time.Unix(0, timestamp*int64(time.Millisecond))
+	window := time.Duration(config.GetSignatureWindow()) * time.Millisecond
+	if age := time.Since(signedAt); age > window || age < -window {
+		return ErrorExpired{}
+	}
+
+	expected := compute(secret, method, uri, body, signature)
+	if !hmac.Equal([]byte(expected), []byte(signature.Value)) {
+		return ErrorInvalid{}
+	}
+
+	// the nonce is checked only for valid signatures, so that forged requests cannot fill the cache
+	if !markNonceSeen(signature.KeyId+":"+signature.Nonce, signedAt.Add(window)) {
+		return ErrorReplayed{}
+	}
+
+	return nil
+}
+
+func compute(secret string, method string, uri string, body []byte, signature *Signature) string {
+	digest := sha256.Sum256(body)
+	message := strings.Join([]string{
+		method,
+		uri,
+		signature.Timestamp,
+		signature.Nonce,
+		hex.EncodeToString(digest[:]),
+	}, "\n")
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(message))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func getSecret(keyId string) (string, bool) {
+	for _, key := range config.GetClusterKeys() {
+		if key.Id == keyId {
+			return key.Secret, true
+		}
+	}
+	return "", false
+}
+
+// markNonceSeen returns false if the nonce has been already seen, nonces are kept until the request expires
+func markNonceSeen(nonce string, expiresAt time.Time) bool {
+	seenNoncesMutex.Lock()
+	defer seenNoncesMutex.Unlock()
+
+	now := time.Now()
+	if now.Sub(seenNoncesLastPurge) > time.Duration(config.GetSignatureWindow())*time.Millisecond {
+		for seenNonce, seenExpiresAt := range seenNonces {
+			if now.After(seenExpiresAt) {
+				delete(seenNonces, seenNonce)
+			}
+		}
+		seenNoncesLastPurge = now
+	}
+
+	if _, seen := seenNonces[nonce]; seen {
+		return false
+	}
+	seenNonces[nonce] = expiresAt
+	return true
+}
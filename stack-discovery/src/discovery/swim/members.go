@@ -0,0 +1,265 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package swim
+
+import (
+	"discovery/config"
+	"discovery/db"
+	"discovery/log"
+	"discovery/types"
+	"math"
+	"sort"
+	"time"
+)
+
+// loadMembers adds as alive the machines of the table, comprising the init servers. Their incarnation is 0, so any
+// message from them overrides it.
+func loadMembers() {
+	machines, err := db.MachinesGetAlive()
+	if err != nil {
+		log.Log.Errorf("Cannot load machines: %s", err)
+		return
+	}
+
+	membersMutex.Lock()
+	defer membersMutex.Unlock()
+
+	for _, machine := range machines {
+		if machine.IP == config.GetMachineIp() {
+			continue
+		}
+		members[machine.IP] = &member{Update: Update{
+			Ip:              machine.IP,
+			Name:            machine.Name,
+			GroupName:       machine.GroupName,
+			ProtocolVersion: machine.ProtocolVersion,
+			Capabilities:    machine.Capabilities,
+			State:           stateAlive,
+		}}
+	}
+	log.Log.Infof("Loaded %d machines as swim members", len(members))
+}
+
+// self returns the state of this machine. membersMutex must be held.
+func self() Update {
+	return Update{
+		Ip:              config.GetMachineIp(),
+		Name:            config.GetMachineId(),
+		GroupName:       config.GetMachineGroupName(),
+		ProtocolVersion: config.GetMachineProtocolVersion(),
+		Capabilities:    config.GetMachineCapabilities(),
+		Incarnation:     incarnation,
+		State:           stateAlive,
+	}
+}
+
+// advertiseSelf disseminates the info of this machine when it changes, like the protocol set by the scheduler, with a
+// new incarnation. membersMutex must be held.
+func advertiseSelf() {
+	current := self()
+	if advertised.Ip != "" && sameInfo(current, advertised) {
+		return
+	}
+	if advertised.Ip != "" {
+		incarnation++
+		current.Incarnation = incarnation
+	}
+	advertised = current
+	enqueueBroadcast(current)
+}
+
+func sameInfo(a Update, b Update) bool {
+	return a.Ip == b.Ip && a.Name == b.Name && a.GroupName == b.GroupName && a.ProtocolVersion == b.ProtocolVersion &&
+		types.EncodeCapabilities(a.Capabilities) == types.EncodeCapabilities(b.Capabilities)
+}
+
+// applyUpdate merges an update into the members, following the precedence of the SWIM protocol. The accepted updates
+// are disseminated and stored in the table. membersMutex must be held.
+func applyUpdate(update Update) {
+	if update.Ip == "" {
+		return
+	}
+
+	// refute the suspicion or the death of this machine
+	if update.Ip == config.GetMachineIp() {
+		if update.State != stateAlive && update.Incarnation >= incarnation {
+			log.Log.Infof("Refuting %s state of this machine with incarnation %d", update.State, update.Incarnation)
+			incarnation = update.Incarnation + 1
+			advertised = self()
+			enqueueBroadcast(advertised)
+		}
+		return
+	}
+
+	m, exists := members[update.Ip]
+	switch update.State {
+	case stateAlive:
+		if exists && update.Incarnation <= m.Incarnation {
+			return
+		}
+		if !exists || m.State != stateAlive {
+			log.Log.Infof("Machine %s is alive with incarnation %d", update.Ip, update.Incarnation)
+		}
+		m = &member{Update: update}
+		members[update.Ip] = m
+		storeAlive(m)
+	case stateSuspect:
+		if !exists || m.State == stateDead || update.Incarnation < m.Incarnation ||
+			(m.State == stateSuspect && update.Incarnation == m.Incarnation) {
+			return
+		}
+		if m.State == stateAlive {
+			m.suspectedAt = time.Now()
+			log.Log.Infof("Machine %s is suspected with incarnation %d", update.Ip, update.Incarnation)
+		}
+		m.State = stateSuspect
+		m.Incarnation = update.Incarnation
+	case stateDead:
+		if !exists || update.Incarnation < m.Incarnation || (m.State == stateDead && update.Incarnation == m.Incarnation) {
+			return
+		}
+		m.State = stateDead
+		m.Incarnation = update.Incarnation
+		log.Log.Infof("Machine %s is dead with incarnation %d", update.Ip, update.Incarnation)
+		storeDead(m)
+	default:
+		return
+	}
+
+	enqueueBroadcast(m.Update)
+}
+
+// suspect suspects a member which did not ack the probes. membersMutex must be held.
+func suspect(ip string) {
+	m, exists := members[ip]
+	if !exists {
+		return
+	}
+	update := m.Update
+	update.State = stateSuspect
+	applyUpdate(update)
+}
+
+// expireSuspects declares dead the members suspected for longer than the suspicion timeout. membersMutex must be held.
+func expireSuspects() {
+	timeout := time.Duration(config.GetSwimSuspicionTimeout()) * time.Millisecond
+	for _, m := range members {
+		if m.State == stateSuspect && time.Since(m.suspectedAt) > timeout {
+			update := m.Update
+			update.State = stateDead
+			applyUpdate(update)
+		}
+	}
+}
+
+// enqueueBroadcast adds the update to the ones to be piggybacked, replacing any older one of the same machine.
+// membersMutex must be held.
+func enqueueBroadcast(update Update) {
+	for i, b := range broadcasts {
+		if b.update.Ip == update.Ip {
+			broadcasts = append(broadcasts[:i], broadcasts[i+1:]...)
+			break
+		}
+	}
+	broadcasts = append(broadcasts, &broadcast{update: update})
+}
+
+// piggyback returns the updates to be sent in a message, the least sent first, and drops the ones sent enough times.
+// membersMutex must be held.
+func piggyback() []Update {
+	limit := uint(retransmitMultiplier * math.Ceil(math.Log2(float64(len(members)+2))))
+
+	sort.SliceStable(broadcasts, func(i, j int) bool { return broadcasts[i].transmits < broadcasts[j].transmits })
+
+	var updates []Update
+	for _, b := range broadcasts {
+		if len(updates) == maxPiggybackUpdates {
+			break
+		}
+		updates = append(updates, b.update)
+		b.transmits++
+	}
+
+	kept := broadcasts[:0]
+	for _, b := range broadcasts {
+		if b.transmits < limit {
+			kept = append(kept, b)
+		}
+	}
+	broadcasts = kept
+
+	return updates
+}
+
+// recordPing stores the round trip time of a direct ping of the machine
+func recordPing(ip string, rtt time.Duration) {
+	machine, err := db.MachineGet(ip)
+	if err != nil || machine == nil {
+		return
+	}
+
+	membersMutex.Lock()
+	defer membersMutex.Unlock()
+
+	// a dead machine is alive again only when it refutes its death
+	if m, exists := members[ip]; !exists || m.State == stateDead {
+		return
+	}
+	db.DeclarePollSucceeded(machine, rtt.Seconds())
+}
+
+// storeAlive adds or updates the member in the table as alive
+func storeAlive(m *member) {
+	machine, err := db.MachineGet(m.Ip)
+	if err != nil {
+		return
+	}
+	if machine == nil {
+		err = db.MachineAdd(&types.Machine{
+			IP:              m.Ip,
+			Name:            m.Name,
+			GroupName:       m.GroupName,
+			Alive:           true,
+			ProtocolVersion: m.ProtocolVersion,
+			Capabilities:    m.Capabilities,
+		}, true)
+		if err != nil {
+			log.Log.Debugf("Cannot add machine %s: %s", m.Ip, err)
+		}
+		return
+	}
+
+	machine.Name = m.Name
+	machine.GroupName = m.GroupName
+	machine.ProtocolVersion = m.ProtocolVersion
+	machine.Capabilities = m.Capabilities
+	machine.Alive = true
+	machine.DeadPolls = 0
+	machine.LastUpdate = time.Now().Unix()
+	_, _ = db.MachineUpdate(machine)
+}
+
+// storeDead declares the member as not alive in the table
+func storeDead(m *member) {
+	machine, err := db.MachineGet(m.Ip)
+	if err != nil || machine == nil {
+		return
+	}
+	db.DeclareMachineDead(machine)
+}
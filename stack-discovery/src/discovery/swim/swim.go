@@ -0,0 +1,409 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package swim implements the membership of the machines with the SWIM protocol, an alternative to the polling of the
+// watcher. Every protocol period a random machine is pinged over udp, if it does not ack other machines are asked to
+// ping it, and if they do not succeed either it is suspected. A suspected machine which does not refute the suspicion
+// by increasing its incarnation is declared dead, and dead machines are probed at a lower rate so that they can join
+// again when a partition heals. The changes are disseminated piggybacked on the messages, and they are stored in the
+// machines table, so that the list api is the same of the polling.
+package swim
+
+import (
+	"discovery/config"
+	"discovery/log"
+	"discovery/signature"
+	"encoding/json"
+	"math/rand"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// maxDatagramSize is the maximum size of a received message
+const maxDatagramSize = 65536
+
+// signatureMethod and signatureUri are the method and the uri of the signatures of swim messages
+const signatureMethod = "SWIM"
+const signatureUri = "/swim"
+
+// maxPiggybackUpdates is the maximum number of updates sent in a message
+const maxPiggybackUpdates = 8
+
+// retransmitMultiplier tells how many times an update is sent, which is retransmitMultiplier * log2(machines)
+const retransmitMultiplier = 3
+
+// deadProbeRounds is the number of protocol periods between two probes of a random dead machine, so that a machine
+// declared dead during a partition refutes its death when the partition heals
+const deadProbeRounds = 10
+
+var conn *net.UDPConn
+
+var members = make(map[string]*member)
+var broadcasts []*broadcast
+var incarnation uint64
+var advertised Update
+
+// membersMutex guards members, broadcasts, incarnation and advertised
+var membersMutex sync.Mutex
+
+var probeOrder []string
+var probeIndex = 0
+var probeRounds = 0
+
+var seq uint64
+var pendingAcks = make(map[uint64]chan bool)
+var pendingAcksMutex sync.Mutex
+
+// Start runs the protocol, it does not return
+func Start() {
+	for config.GetMachineIp() == "" {
+		log.Log.Warningf("Machine has not configured its IP, service is idle. Retrying in 30 seconds...")
+		time.Sleep(30 * time.Second)
+	}
+
+	var err error
+	conn, err = net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP(config.GetListeningHost()), Port: int(config.GetSwimPort())})
+	if err != nil {
+		log.Log.Fatalf("Cannot listen for swim on port %d: %s", config.GetSwimPort(), err)
+		return
+	}
+
+	// the incarnation starts from the time so that a restarted machine overrides its previous death
+	incarnation = uint64(time.Now().Unix())
+	loadMembers()
+
+	log.Log.Infof("Started swim on port %d with probe interval %dms", config.GetSwimPort(), config.GetSwimProbeInterval())
+
+	go receiver()
+
+	for {
+		startedAt := time.Now()
+		probeRound()
+		time.Sleep(time.Duration(config.GetSwimProbeInterval())*time.Millisecond - time.Since(startedAt))
+	}
+}
+
+// Reset forgets all the members, which are added back when their messages are received
+func Reset() {
+	membersMutex.Lock()
+	defer membersMutex.Unlock()
+
+	members = make(map[string]*member)
+	broadcasts = nil
+}
+
+// probeRound is a protocol period, in which a single machine is probed
+func probeRound() {
+	membersMutex.Lock()
+	advertiseSelf()
+	expireSuspects()
+	target, ok := nextProbeTarget()
+	deadTarget, deadOk := nextDeadProbeTarget()
+	membersMutex.Unlock()
+
+	if deadOk {
+		go probeDead(deadTarget)
+	}
+	if !ok {
+		return
+	}
+
+	startedAt := time.Now()
+	if ping(target, time.Duration(config.GetSwimProbeTimeout())*time.Millisecond) {
+		recordPing(target, time.Since(startedAt))
+		return
+	}
+
+	log.Log.Debugf("Machine %s did not ack, probing it indirectly", target)
+	remaining := time.Duration(config.GetSwimProbeInterval())*time.Millisecond - time.Since(startedAt)
+	if pingIndirect(target, remaining) {
+		return
+	}
+
+	membersMutex.Lock()
+	defer membersMutex.Unlock()
+	suspect(target)
+}
+
+// probeDead pings a dead machine, which is told that it is dead and refutes it in the ack if it is reachable again
+func probeDead(ip string) {
+	if ping(ip, time.Duration(config.GetSwimProbeTimeout())*time.Millisecond) {
+		log.Log.Debugf("Dead machine %s acked", ip)
+	}
+}
+
+// ping sends a ping to the machine and waits for its ack
+func ping(ip string, timeout time.Duration) bool {
+	seq, acked := registerAck()
+	defer unregisterAck(seq)
+
+	send(ip, &message{Type: messagePing, Seq: seq})
+	return waitAck(acked, timeout)
+}
+
+// pingIndirect asks random members to ping the machine and waits for an ack forwarded by any of them
+func pingIndirect(ip string, timeout time.Duration) bool {
+	membersMutex.Lock()
+	helpers := randomMembers(int(config.GetSwimIndirectProbes()), ip)
+	membersMutex.Unlock()
+	if len(helpers) == 0 {
+		return false
+	}
+
+	seq, acked := registerAck()
+	defer unregisterAck(seq)
+
+	for _, helper := range helpers {
+		send(helper, &message{Type: messagePingReq, Seq: seq, Target: ip})
+	}
+	return waitAck(acked, timeout)
+}
+
+func registerAck() (uint64, chan bool) {
+	seq := atomic.AddUint64(&seq, 1)
+	acked := make(chan bool, 1)
+
+	pendingAcksMutex.Lock()
+	pendingAcks[seq] = acked
+	pendingAcksMutex.Unlock()
+
+	return seq, acked
+}
+
+func unregisterAck(seq uint64) {
+	pendingAcksMutex.Lock()
+	delete(pendingAcks, seq)
+	pendingAcksMutex.Unlock()
+}
+
+func deliverAck(seq uint64) {
+	pendingAcksMutex.Lock()
+	defer pendingAcksMutex.Unlock()
+
+	if acked, exists := pendingAcks[seq]; exists {
+		select {
+		case acked <- true:
+		default:
+		}
+	}
+}
+
+func waitAck(acked chan bool, timeout time.Duration) bool {
+	if timeout <= 0 {
+		return false
+	}
+	select {
+	case <-acked:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// nextProbeTarget returns the next machine to probe, the members are probed in a random order which is shuffled at
+// every round, so that every member is probed in a bounded time. If all the members are dead one of the init servers is
+// probed, for joining again the cluster. membersMutex must be held.
+func nextProbeTarget() (string, bool) {
+	for attempts := 0; attempts < 2; attempts++ {
+		for probeIndex < len(probeOrder) {
+			ip := probeOrder[probeIndex]
+			probeIndex++
+			if m, exists := members[ip]; exists && m.State != stateDead {
+				return ip, true
+			}
+		}
+
+		probeOrder = probeOrder[:0]
+		for ip, m := range members {
+			if m.State != stateDead {
+				probeOrder = append(probeOrder, ip)
+			}
+		}
+		rand.Shuffle(len(probeOrder), func(i, j int) { probeOrder[i], probeOrder[j] = probeOrder[j], probeOrder[i] })
+		probeIndex = 0
+	}
+
+	var initServers []string
+	for _, ip := range config.GetInitServers() {
+		if ip != config.GetMachineIp() {
+			initServers = append(initServers, ip)
+		}
+	}
+	if len(initServers) == 0 {
+		return "", false
+	}
+	return initServers[rand.Intn(len(initServers))], true
+}
+
+// nextDeadProbeTarget returns a random dead member once every deadProbeRounds protocol periods. membersMutex must be
+// held.
+func nextDeadProbeTarget() (string, bool) {
+	probeRounds++
+	if probeRounds < deadProbeRounds {
+		return "", false
+	}
+	probeRounds = 0
+
+	var dead []string
+	for ip, m := range members {
+		if m.State == stateDead {
+			dead = append(dead, ip)
+		}
+	}
+	if len(dead) == 0 {
+		return "", false
+	}
+	return dead[rand.Intn(len(dead))], true
+}
+
+// randomMembers returns at most n random members which are not dead, excluding the passed one. membersMutex must be
+// held.
+func randomMembers(n int, exclude string) []string {
+	var candidates []string
+	for ip, m := range members {
+		if ip != exclude && m.State != stateDead {
+			candidates = append(candidates, ip)
+		}
+	}
+	rand.Shuffle(len(candidates), func(i, j int) { candidates[i], candidates[j] = candidates[j], candidates[i] })
+	if len(candidates) > n {
+		candidates = candidates[:n]
+	}
+	return candidates
+}
+
+func receiver() {
+	buffer := make([]byte, maxDatagramSize)
+	for {
+		n, addr, err := conn.ReadFromUDP(buffer)
+		if err != nil {
+			log.Log.Errorf("Cannot read swim message: %s", err)
+			continue
+		}
+
+		msg, err := decodeMessage(buffer[:n])
+		if err != nil {
+			log.Log.Debugf("Discarding swim message from %s: %s", addr, err)
+			continue
+		}
+		handleMessage(msg, addr)
+	}
+}
+
+func handleMessage(msg *message, addr *net.UDPAddr) {
+	membersMutex.Lock()
+	msg.From.State = stateAlive
+	applyUpdate(msg.From)
+	for _, update := range msg.Updates {
+		applyUpdate(update)
+	}
+	// a sender which does not know that it is suspected or dead is told in the ack
+	var aboutSender *Update
+	if m, exists := members[msg.From.Ip]; exists && m.State != stateAlive && m.Incarnation >= msg.From.Incarnation {
+		update := m.Update
+		aboutSender = &update
+	}
+	membersMutex.Unlock()
+
+	switch msg.Type {
+	case messagePing:
+		ack := &message{Type: messageAck, Seq: msg.Seq}
+		if aboutSender != nil {
+			ack.Updates = []Update{*aboutSender}
+		}
+		sendTo(addr, ack)
+	case messagePingReq:
+		go func() {
+			if ping(msg.Target, time.Duration(config.GetSwimProbeTimeout())*time.Millisecond) {
+				sendTo(addr, &message{Type: messageAck, Seq: msg.Seq})
+			}
+		}()
+	case messageAck:
+		deliverAck(msg.Seq)
+	}
+}
+
+func send(ip string, msg *message) {
+	addr := &net.UDPAddr{IP: net.ParseIP(ip), Port: int(config.GetSwimPort())}
+	if addr.IP == nil {
+		log.Log.Debugf("Cannot send swim message to %s: ip is not valid", ip)
+		return
+	}
+	// a machine which is suspected or dead is told directly, so that it can refute
+	membersMutex.Lock()
+	if m, exists := members[ip]; exists && m.State != stateAlive {
+		msg.Updates = append(msg.Updates, m.Update)
+	}
+	membersMutex.Unlock()
+
+	sendTo(addr, msg)
+}
+
+// sendTo sends the message with the updates to be piggybacked
+func sendTo(addr *net.UDPAddr, msg *message) {
+	membersMutex.Lock()
+	msg.From = self()
+	msg.Updates = append(msg.Updates, piggyback()...)
+	membersMutex.Unlock()
+
+	body, err := encodeMessage(msg)
+	if err != nil {
+		log.Log.Errorf("Cannot encode swim message: %s", err)
+		return
+	}
+	if _, err = conn.WriteToUDP(body, addr); err != nil {
+		log.Log.Debugf("Cannot send swim message to %s: %s", addr, err)
+	}
+}
+
+func encodeMessage(msg *message) ([]byte, error) {
+	if signature.Enabled() {
+		msg.Signature = nil
+		body, err := json.Marshal(msg)
+		if err != nil {
+			return nil, err
+		}
+		msg.Signature = signature.Sign(signatureMethod, signatureUri, body)
+	}
+	return json.Marshal(msg)
+}
+
+func decodeMessage(body []byte) (*message, error) {
+	msg := &message{}
+	if err := json.Unmarshal(body, msg); err != nil {
+		return nil, err
+	}
+
+	if signature.Enabled() {
+		messageSignature := msg.Signature
+		if messageSignature == nil {
+			return nil, signature.ErrorMissing{}
+		}
+		msg.Signature = nil
+		unsigned, err := json.Marshal(msg)
+		if err != nil {
+			return nil, err
+		}
+		if err = signature.Verify(messageSignature, signatureMethod, signatureUri, unsigned); err != nil {
+			return nil, err
+		}
+	}
+	return msg, nil
+}
@@ -0,0 +1,272 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package swim
+
+import (
+	"discovery/config"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testMachineIp = "10.0.0.100"
+
+// reachableIp is served by the socket of the test, which acks its own pings, nothing listens on unreachableIp
+const reachableIp = "127.0.0.1"
+const unreachableIp = "127.0.0.2"
+
+var testConnOnce sync.Once
+
+func setEnv(t *testing.T, key string, value string) {
+	_ = os.Setenv(key, value)
+	t.Cleanup(func() {
+		_ = os.Unsetenv(key)
+		config.InitConfigurationStatic()
+	})
+}
+
+// startTestSwim forgets the members and configures short timeouts, the socket of the protocol is opened once on a
+// random port of the loopback, which is used also as the swim port of the other machines
+func startTestSwim(t *testing.T) {
+	testConnOnce.Do(func() {
+		var err error
+		conn, err = net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP(reachableIp)})
+		if err != nil {
+			t.Fatalf("cannot listen for swim: %s", err)
+		}
+		go receiver()
+	})
+
+	setEnv(t, config.EnvSwimPort, strconv.Itoa(conn.LocalAddr().(*net.UDPAddr).Port))
+	setEnv(t, config.EnvSwimProbeInterval, "100")
+	setEnv(t, config.EnvSwimProbeTimeout, "20")
+	setEnv(t, config.EnvSwimSuspicionTimeout, "50")
+	config.InitConfigurationStatic()
+	config.SetMachineIp(testMachineIp)
+
+	Reset()
+	membersMutex.Lock()
+	incarnation = 1
+	advertised = Update{}
+	probeOrder = nil
+	probeIndex = 0
+	probeRounds = 0
+	membersMutex.Unlock()
+}
+
+func applyTestUpdate(ip string, state string, incarnation uint64) {
+	membersMutex.Lock()
+	defer membersMutex.Unlock()
+
+	applyUpdate(Update{Ip: ip, Name: ip, State: state, Incarnation: incarnation})
+}
+
+func expectMember(t *testing.T, ip string, state string, incarnation uint64) {
+	t.Helper()
+	membersMutex.Lock()
+	defer membersMutex.Unlock()
+
+	m, exists := members[ip]
+	if !exists {
+		t.Fatalf("expected %s to be a member", ip)
+	}
+	if m.State != state || m.Incarnation != incarnation {
+		t.Fatalf("expected %s to be %s with incarnation %d, it is %s with incarnation %d", ip, state, incarnation,
+			m.State, m.Incarnation)
+	}
+}
+
+func isBroadcast(ip string, state string) bool {
+	membersMutex.Lock()
+	defer membersMutex.Unlock()
+
+	for _, b := range broadcasts {
+		if b.update.Ip == ip && b.update.State == state {
+			return true
+		}
+	}
+	return false
+}
+
+func TestSuspectedMemberIsDeclaredDead(t *testing.T) {
+	startTestSwim(t)
+	applyTestUpdate("10.0.0.1", stateAlive, 1)
+
+	membersMutex.Lock()
+	suspect("10.0.0.1")
+	expireSuspects()
+	membersMutex.Unlock()
+	expectMember(t, "10.0.0.1", stateSuspect, 1)
+	if !isBroadcast("10.0.0.1", stateSuspect) {
+		t.Fatalf("expected the suspicion to be disseminated")
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	membersMutex.Lock()
+	expireSuspects()
+	membersMutex.Unlock()
+	expectMember(t, "10.0.0.1", stateDead, 1)
+	if !isBroadcast("10.0.0.1", stateDead) {
+		t.Fatalf("expected the death to be disseminated")
+	}
+}
+
+func TestSuspicionIsRefutedByNewIncarnation(t *testing.T) {
+	startTestSwim(t)
+	applyTestUpdate("10.0.0.1", stateAlive, 1)
+	applyTestUpdate("10.0.0.1", stateSuspect, 1)
+
+	// an alive update of the same incarnation does not override the suspicion
+	applyTestUpdate("10.0.0.1", stateAlive, 1)
+	expectMember(t, "10.0.0.1", stateSuspect, 1)
+
+	applyTestUpdate("10.0.0.1", stateAlive, 2)
+	expectMember(t, "10.0.0.1", stateAlive, 2)
+
+	// the suspicion was made before the refutation
+	applyTestUpdate("10.0.0.1", stateSuspect, 1)
+	expectMember(t, "10.0.0.1", stateAlive, 2)
+}
+
+func TestDeadMemberJoinsWithNewIncarnation(t *testing.T) {
+	startTestSwim(t)
+	applyTestUpdate("10.0.0.1", stateAlive, 3)
+
+	// stale updates are ignored
+	applyTestUpdate("10.0.0.1", stateDead, 2)
+	expectMember(t, "10.0.0.1", stateAlive, 3)
+
+	applyTestUpdate("10.0.0.1", stateDead, 3)
+	expectMember(t, "10.0.0.1", stateDead, 3)
+
+	// a dead member is not suspected and it is alive again only with a new incarnation
+	applyTestUpdate("10.0.0.1", stateSuspect, 4)
+	applyTestUpdate("10.0.0.1", stateAlive, 3)
+	expectMember(t, "10.0.0.1", stateDead, 3)
+
+	applyTestUpdate("10.0.0.1", stateAlive, 4)
+	expectMember(t, "10.0.0.1", stateAlive, 4)
+}
+
+func TestSuspicionOfThisMachineIsRefuted(t *testing.T) {
+	startTestSwim(t)
+
+	applyTestUpdate(testMachineIp, stateSuspect, 1)
+
+	membersMutex.Lock()
+	refutedIncarnation := incarnation
+	membersMutex.Unlock()
+	if refutedIncarnation != 2 {
+		t.Fatalf("expected the incarnation to be increased to 2, it is %d", refutedIncarnation)
+	}
+	if !isBroadcast(testMachineIp, stateAlive) {
+		t.Fatalf("expected the refutation to be disseminated")
+	}
+
+	// an older suspicion does not need to be refuted
+	applyTestUpdate(testMachineIp, stateDead, 1)
+	membersMutex.Lock()
+	defer membersMutex.Unlock()
+	if incarnation != 2 {
+		t.Fatalf("expected the incarnation to stay 2, it is %d", incarnation)
+	}
+}
+
+func TestDeadMembersAreNotProbed(t *testing.T) {
+	startTestSwim(t)
+	applyTestUpdate("10.0.0.1", stateAlive, 1)
+	applyTestUpdate("10.0.0.2", stateAlive, 1)
+	applyTestUpdate("10.0.0.3", stateAlive, 1)
+	applyTestUpdate("10.0.0.3", stateDead, 1)
+
+	membersMutex.Lock()
+	defer membersMutex.Unlock()
+
+	for round := 0; round < 3; round++ {
+		probed := map[string]bool{}
+		for i := 0; i < 2; i++ {
+			target, ok := nextProbeTarget()
+			if !ok {
+				t.Fatalf("expected a probe target")
+			}
+			probed[target] = true
+		}
+		if !probed["10.0.0.1"] || !probed["10.0.0.2"] {
+			t.Fatalf("expected every alive member to be probed in a round, probed %v", probed)
+		}
+	}
+}
+
+func TestDeadMembersAreProbedAgain(t *testing.T) {
+	startTestSwim(t)
+	applyTestUpdate("10.0.0.1", stateAlive, 1)
+
+	membersMutex.Lock()
+	defer membersMutex.Unlock()
+
+	for i := 0; i < deadProbeRounds; i++ {
+		if _, ok := nextDeadProbeTarget(); ok {
+			t.Fatalf("expected no dead member to probe")
+		}
+	}
+
+	applyUpdate(Update{Ip: "10.0.0.1", State: stateDead, Incarnation: 1})
+	for i := 0; i < deadProbeRounds-1; i++ {
+		if _, ok := nextDeadProbeTarget(); ok {
+			t.Fatalf("expected the dead member to be probed once every %d rounds", deadProbeRounds)
+		}
+	}
+	if target, ok := nextDeadProbeTarget(); !ok || target != "10.0.0.1" {
+		t.Fatalf("expected the dead member to be probed, got %s", target)
+	}
+}
+
+func TestProbeRoundSuspectsUnreachableMember(t *testing.T) {
+	startTestSwim(t)
+	applyTestUpdate(reachableIp, stateAlive, 1)
+	applyTestUpdate(unreachableIp, stateAlive, 1)
+
+	// the probe order is random, both members are probed in two rounds
+	probeRound()
+	probeRound()
+
+	expectMember(t, reachableIp, stateAlive, 1)
+	expectMember(t, unreachableIp, stateSuspect, 1)
+}
+
+func TestDeadMemberRefutesWhenReachable(t *testing.T) {
+	startTestSwim(t)
+	applyTestUpdate(reachableIp, stateAlive, 1)
+	applyTestUpdate(reachableIp, stateDead, 1)
+
+	if !ping(reachableIp, 100*time.Millisecond) {
+		t.Fatalf("expected the dead member to ack the probe")
+	}
+
+	// a message of the dead member with the same incarnation does not make it alive
+	handleMessage(&message{Type: messageAck, From: Update{Ip: reachableIp, Incarnation: 1}}, conn.LocalAddr().(*net.UDPAddr))
+	expectMember(t, reachableIp, stateDead, 1)
+
+	// the member told of its death refutes it with a new incarnation
+	handleMessage(&message{Type: messageAck, From: Update{Ip: reachableIp, Incarnation: 2}}, conn.LocalAddr().(*net.UDPAddr))
+	expectMember(t, reachableIp, stateAlive, 2)
+}
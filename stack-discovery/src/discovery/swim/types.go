@@ -0,0 +1,74 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package swim
+
+import (
+	"discovery/signature"
+	"time"
+)
+
+// the messages of the protocol, a ping-req asks the receiver to ping the target on behalf of the sender and to forward
+// the ack to it
+const (
+	messagePing    = "ping"
+	messageAck     = "ack"
+	messagePingReq = "ping-req"
+)
+
+// the states of a member, a suspected member is still in the list until it refutes the suspicion or it is confirmed
+// as dead after the suspicion timeout
+const (
+	stateAlive   = "alive"
+	stateSuspect = "suspect"
+	stateDead    = "dead"
+)
+
+// Update is the state of a member, which is disseminated piggybacked on the messages. The incarnation is increased
+// only by the member itself, for refuting a suspicion or for advertising new info.
+type Update struct {
+	Ip              string   `json:"ip"`
+	Name            string   `json:"name"`
+	GroupName       string   `json:"group_name"`
+	ProtocolVersion int      `json:"protocol_version"`
+	Capabilities    []string `json:"capabilities"`
+	Incarnation     uint64   `json:"incarnation"`
+	State           string   `json:"state"`
+}
+
+type message struct {
+	Type string `json:"type"`
+	Seq  uint64 `json:"seq"`
+	// Target is the machine to ping, only in ping-req
+	Target  string   `json:"target,omitempty"`
+	From    Update   `json:"from"`
+	Updates []Update `json:"updates,omitempty"`
+	// Signature covers the message without it, when the cluster keys are set
+	Signature *signature.Signature `json:"signature,omitempty"`
+}
+
+type member struct {
+	Update
+	suspectedAt time.Time
+}
+
+// broadcast is an update to be piggybacked until it has been sent enough times
+type broadcast struct {
+	update    Update
+	transmits uint
+}
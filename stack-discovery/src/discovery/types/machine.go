@@ -19,6 +19,8 @@
 // Package types implements object models
 package types
 
+import "strings"
+
 const MachinesCollectionName = "machines"
 
 type Machine struct {
@@ -36,4 +38,24 @@ type Machine struct {
 	// DeadPolls tells the number of consecutive times the machine timed out. This is set to 0 when the machine replies
 	// correctly
 	DeadPolls uint `json:"dead_polls" bson:"dead_polls"`
+	// ProtocolVersion and Capabilities are the ones of the scheduler of the machine, the version is 0 if it does not
+	// advertise them
+	ProtocolVersion int      `json:"protocol_version" bson:"protocol_version"`
+	Capabilities    []string `json:"capabilities" bson:"capabilities"`
+}
+
+// EncodeCapabilities encodes the capabilities for a header or a column
+func EncodeCapabilities(capabilities []string) string {
+	return strings.Join(capabilities, ",")
+}
+
+// DecodeCapabilities decodes the capabilities encoded with EncodeCapabilities
+func DecodeCapabilities(value string) []string {
+	capabilities := []string{}
+	for _, capability := range strings.Split(value, ",") {
+		if capability != "" {
+			capabilities = append(capabilities, capability)
+		}
+	}
+	return capabilities
 }
@@ -21,8 +21,12 @@ package watcher
 import (
 	"discovery/config"
 	"discovery/discovery_service"
+	"discovery/mtls"
+	"discovery/signature"
+	"discovery/types"
 	"discovery/utils"
 	"net/http"
+	"strconv"
 	"time"
 )
 
@@ -31,7 +35,21 @@ func GetForPoll(ip string) (*http.Response, error) {
 		{Field: config.GetParamIp, Payload: config.GetMachineIp()},
 		{Field: config.GetParamName, Payload: config.GetMachineId()},
 		{Field: config.GetParamGroupName, Payload: config.GetMachineGroupName()},
+		{Field: config.GetParamProtocolVersion, Payload: strconv.Itoa(config.GetMachineProtocolVersion())},
+		{Field: config.GetParamCapabilities, Payload: types.EncodeCapabilities(config.GetMachineCapabilities())},
 	}
+	url := discovery_service.GetServerListApi(ip)
+	if signature.Enabled() {
+		signatureHeaders, err := signature.Headers("GET", url, nil)
+		if err != nil {
+			return nil, err
+		}
+		headers = append(headers, signatureHeaders...)
+	}
+
 	client := http.Client{Timeout: time.Duration(config.GetPollTimeout()) * time.Second}
-	return utils.HttpMachineGet(&client, discovery_service.GetServerListApi(ip), headers)
+	if mtls.Enabled() {
+		client.Transport = &http.Transport{TLSClientConfig: mtls.ClientConfig()}
+	}
+	return utils.HttpMachineGet(&client, url, headers)
 }
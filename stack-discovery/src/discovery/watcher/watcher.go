@@ -25,6 +25,7 @@ import (
 	"discovery/log"
 	"discovery/types"
 	"encoding/json"
+	"strconv"
 	"time"
 )
 
@@ -109,6 +110,8 @@ func pollMachine(machine *types.Machine) (*time.Duration, error) {
 			answeringMachine.IP = res.Header.Get(config.GetParamIp)
 			answeringMachine.Name = res.Header.Get(config.GetParamName)
 			answeringMachine.GroupName = res.Header.Get(config.GetParamGroupName)
+			answeringMachine.ProtocolVersion, _ = strconv.Atoi(res.Header.Get(config.GetParamProtocolVersion))
+			answeringMachine.Capabilities = types.DecodeCapabilities(res.Header.Get(config.GetParamCapabilities))
 			_, _ = db.MachineUpdate(answeringMachine)
 		}
 	}
@@ -116,6 +119,8 @@ func pollMachine(machine *types.Machine) (*time.Duration, error) {
 	// update parameters, they may change over time
 	machine.Name = res.Header.Get(config.GetParamName)
 	machine.GroupName = res.Header.Get(config.GetParamGroupName)
+	machine.ProtocolVersion, _ = strconv.Atoi(res.Header.Get(config.GetParamProtocolVersion))
+	machine.Capabilities = types.DecodeCapabilities(res.Header.Get(config.GetParamCapabilities))
 
 	// decode machine list
 	var machines []types.Machine
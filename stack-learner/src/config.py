@@ -28,6 +28,11 @@ _ENV_LISTENING_HOST = "P2PFAAS_LISTENING_HOST"
 _ENV_LISTENING_PORT = "P2PFAAS_LISTENING_PORT"
 _ENV_DIR_DATA = "P2PFAAS_DIR_DATA"
 _ENV_RUNNING_ENVIRONMENT = "P2PFAAS_RUNNING_ENVIRONMENT"
+_ENV_TLS_ENABLED = "P2PFAAS_TLS_ENABLED"
+_ENV_TLS_CA_PATH = "P2PFAAS_TLS_CA_PATH"
+_ENV_TLS_CERT_PATH = "P2PFAAS_TLS_CERT_PATH"
+_ENV_TLS_KEY_PATH = "P2PFAAS_TLS_KEY_PATH"
+_ENV_TLS_RELOAD_INTERVAL = "P2PFAAS_TLS_RELOAD_INTERVAL_MS"
 
 
 class ConfigurationStatic:
@@ -37,6 +42,10 @@ class ConfigurationStatic:
     _DEFAULT_DIR_DATA = "/data"
     _DEFAULT_LISTENING_HOST = "0.0.0.0"
     _DEFAULT_LISTENING_PORT = 19020
+    _DEFAULT_TLS_CA_PATH = "/tls/ca.crt"
+    _DEFAULT_TLS_CERT_PATH = "/tls/tls.crt"
+    _DEFAULT_TLS_KEY_PATH = "/tls/tls.key"
+    _DEFAULT_TLS_RELOAD_INTERVAL = 30000  # ms
 
     @classmethod
     def _init(cls):
@@ -45,8 +54,16 @@ class ConfigurationStatic:
         cls._listening_host = os.getenv(_ENV_LISTENING_HOST, default=ConfigurationStatic._DEFAULT_LISTENING_HOST)
         cls._listening_port = os.getenv(_ENV_LISTENING_PORT, default=ConfigurationStatic._DEFAULT_LISTENING_PORT)
 
+        cls._tls_enabled = os.getenv(_ENV_TLS_ENABLED, default="false")
+        cls._tls_ca_path = os.getenv(_ENV_TLS_CA_PATH, default=ConfigurationStatic._DEFAULT_TLS_CA_PATH)
+        cls._tls_cert_path = os.getenv(_ENV_TLS_CERT_PATH, default=ConfigurationStatic._DEFAULT_TLS_CERT_PATH)
+        cls._tls_key_path = os.getenv(_ENV_TLS_KEY_PATH, default=ConfigurationStatic._DEFAULT_TLS_KEY_PATH)
+        cls._tls_reload_interval = os.getenv(_ENV_TLS_RELOAD_INTERVAL, default=ConfigurationStatic._DEFAULT_TLS_RELOAD_INTERVAL)
+
         cls._listening_port = int(cls._listening_port)
         cls._is_development = cls._running_environment == "development"
+        cls._tls_enabled = cls._tls_enabled.lower() in ("1", "t", "true")
+        cls._tls_reload_interval = int(cls._tls_reload_interval)
 
     def __init__(self):
         raise RuntimeError('Call instance() instead')
@@ -74,6 +91,21 @@ class ConfigurationStatic:
     def get_listening_port(self) -> int:
         return self._listening_port
 
+    def is_tls_enabled(self) -> bool:
+        return self._tls_enabled
+
+    def get_tls_ca_path(self) -> str:
+        return self._tls_ca_path
+
+    def get_tls_cert_path(self) -> str:
+        return self._tls_cert_path
+
+    def get_tls_key_path(self) -> str:
+        return self._tls_key_path
+
+    def get_tls_reload_interval(self) -> int:
+        return self._tls_reload_interval
+
     @staticmethod
     def get_app_name():
         return "p2pfaas-learner"
@@ -16,6 +16,7 @@
 
 import asyncio
 import json
+import ssl
 from threading import Thread
 
 from flask import Flask, request
@@ -30,6 +31,7 @@ from config import ConfigurationStatic, ConfigurationDynamic
 from learners.learner import Learner
 from log import Log
 from models import ActEntry
+from mtls import MutualTls
 
 _MODULE_NAME = "Main"
 
@@ -151,13 +153,23 @@ async def ws_act(websocket):
         log.Log.mwarn(_MODULE_NAME, f"ws_act: socket error: {e}")
 
 
-async def ws_main(port):
-    async with ws_serve(ws_act, "0.0.0.0", port):
+async def ws_main(port, ssl_context=None):
+    async with ws_serve(ws_act, "0.0.0.0", port, ssl=ssl_context):
         await asyncio.Future()  # run forever
 
 
-def ws_init():
-    asyncio.run(ws_main(8765))
+def ws_init(ssl_context=None):
+    asyncio.run(ws_main(8765, ssl_context))
+
+
+def new_mutual_tls(context_class=ssl.SSLContext) -> MutualTls:
+    mutual_tls = MutualTls(configurationStatic.get_tls_ca_path(),
+                           configurationStatic.get_tls_cert_path(),
+                           configurationStatic.get_tls_key_path(),
+                           configurationStatic.get_tls_reload_interval(),
+                           context_class)
+    mutual_tls.start_reloader()
+    return mutual_tls
 
 
 #
@@ -183,11 +195,24 @@ if __name__ == '__main__':
     # start socket server
     Log.minfo(_MODULE_NAME, "Starting socket listening")
     # asyncio.run(ws_main(8765))
-    Thread(target=ws_init).start()
+    ws_ssl_context = new_mutual_tls().server_context() if configurationStatic.is_tls_enabled() else None
+    Thread(target=ws_init, args=(ws_ssl_context,)).start()
 
     # start webserver
-    Log.minfo(_MODULE_NAME, "Starting webserver")
-    serve(app, host=configurationStatic.get_listening_host(), port=configurationStatic.get_listening_port())
+    if configurationStatic.is_tls_enabled():
+        # waitress does not support tls, so the webserver is served by gevent with the gevent ssl context
+        from gevent import ssl as gevent_ssl
+        from gevent.pywsgi import WSGIServer
+
+        Log.minfo(_MODULE_NAME, "Starting webserver with mutual tls")
+        http_server = WSGIServer((configurationStatic.get_listening_host(), configurationStatic.get_listening_port()),
+                                 app,
+                                 ssl_context=new_mutual_tls(gevent_ssl.SSLContext).server_context(),
+                                 log=None)
+        http_server.serve_forever()
+    else:
+        Log.minfo(_MODULE_NAME, "Starting webserver")
+        serve(app, host=configurationStatic.get_listening_host(), port=configurationStatic.get_listening_port())
 
     # http_server = WSGIServer((
     #    configurationStatic.get_listening_host(),
@@ -0,0 +1,91 @@
+#  P2PFaaS - A framework for FaaS Load Balancing
+#  Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+#
+#  This program is free software: you can redistribute it and/or modify
+#  it under the terms of the GNU General Public License as published by
+#  the Free Software Foundation, either version 3 of the License, or
+#  (at your option) any later version.
+#
+#  This program is distributed in the hope that it will be useful,
+#  but WITHOUT ANY WARRANTY; without even the implied warranty of
+#  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+#  GNU General Public License for more details.
+#
+#  You should have received a copy of the GNU General Public License
+#   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+import os
+import ssl
+import time
+from threading import Thread, Lock
+
+from log import Log
+
+"""
+Implement the mutual tls with the other services of the cluster. The scheduler presents a certificate signed by the
+cluster CA, and the files are reloaded when they change, so certificates can be rotated without restarting.
+"""
+
+
+class MutualTls:
+    _MODULE_NAME = "MutualTls"
+
+    def __init__(self, ca_path, cert_path, key_path, reload_interval_ms, context_class=ssl.SSLContext):
+        self._ca_path = ca_path
+        self._cert_path = cert_path
+        self._key_path = key_path
+        self._reload_interval_ms = reload_interval_ms
+        self._context_class = context_class
+
+        self._lock = Lock()
+        self._current = self._load()
+        self._mod_times = self._get_mod_times()
+
+    def server_context(self) -> ssl.SSLContext:
+        """Returns the context to pass to the server, at every handshake it switches to the current certificates"""
+        context = self._load()
+        context.sni_callback = self._sni_callback
+        return context
+
+    def start_reloader(self):
+        if self._reload_interval_ms > 0:
+            Thread(target=self._reloader, daemon=True).start()
+
+    #
+    # Internals
+    #
+
+    def _sni_callback(self, ssl_socket, server_name, context):
+        with self._lock:
+            ssl_socket.context = self._current
+
+    def _reloader(self):
+        while True:
+            time.sleep(self._reload_interval_ms / 1000)
+
+            try:
+                mod_times = self._get_mod_times()
+                if mod_times == self._mod_times:
+                    continue
+                # the previous certificates are kept if the new ones are not valid
+                context = self._load()
+            except (OSError, ssl.SSLError) as e:
+                Log.mwarn(MutualTls._MODULE_NAME, f"_reloader: cannot reload tls certificates: {e}")
+                continue
+
+            with self._lock:
+                self._current = context
+                self._mod_times = mod_times
+            Log.minfo(MutualTls._MODULE_NAME, "_reloader: reloaded tls certificates")
+
+    def _load(self) -> ssl.SSLContext:
+        context = self._context_class(ssl.PROTOCOL_TLS_SERVER)
+        context.minimum_version = ssl.TLSVersion.TLSv1_2
+        # the learner is called only by the scheduler, so a client certificate is always required
+        context.verify_mode = ssl.CERT_REQUIRED
+        context.load_cert_chain(self._cert_path, self._key_path)
+        context.load_verify_locations(cafile=self._ca_path)
+        return context
+
+    def _get_mod_times(self):
+        return tuple(os.stat(path).st_mtime for path in (self._ca_path, self._cert_path, self._key_path))
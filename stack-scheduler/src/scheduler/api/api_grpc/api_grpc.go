@@ -0,0 +1,119 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package api_grpc implements the grpc peer service, which offers to the other nodes the load and the peer apis when
+// the cluster uses the grpc peer transport.
+package api_grpc
+
+import (
+	"context"
+	"crypto/tls"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"scheduler/config"
+	"scheduler/log"
+	"scheduler/mtls"
+	"scheduler/peer_proto"
+	"scheduler/signature"
+	"scheduler/utils"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type peerServer struct {
+	peer_proto.UnimplementedPeerServer
+}
+
+// NewServer returns a grpc server with the peer service registered, only other machines call it so a client
+// certificate is required when mtls is enabled
+func NewServer() *grpc.Server {
+	options := []grpc.ServerOption{
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			MaxConnectionIdle: time.Duration(config.GetPeerIdleConnTimeout()) * time.Millisecond,
+		}),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             5 * time.Second,
+			PermitWithoutStream: true,
+		}),
+	}
+	if mtls.Enabled() {
+		tlsConfig := mtls.ServerConfig(tls.RequireAndVerifyClientCert, []string{"h2"})
+		options = append(options, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+
+	server := grpc.NewServer(options...)
+	peer_proto.RegisterPeerServer(server, &peerServer{})
+	return server
+}
+
+// checkMachine returns an error if the call does not come from another machine, which is authenticated with its
+// certificate and its signature when mtls and signatures are enabled, and with the user agent otherwise, or if its
+// protocol version is not accepted
+func checkMachine(ctx context.Context) error {
+	if mtls.Enabled() && !isPeerVerified(ctx) {
+		return status.Error(codes.Unauthenticated, "called without a valid machine certificate")
+	}
+	if signature.Enabled() {
+		method, _ := grpc.Method(ctx)
+		if err := signature.VerifyIncomingContext(ctx, method); err != nil {
+			log.Log.Warningf("Rejected grpc call %s: %s", method, err)
+			return status.Error(codes.Unauthenticated, err.Error())
+		}
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	if !mtls.Enabled() && !signature.Enabled() && !isUserAgentMachine(md) {
+		return status.Error(codes.PermissionDenied, "called from not a machine")
+	}
+
+	// the machines which do not send the protocol version are of version 0
+	version := 0
+	if values := md.Get(utils.GrpcMetadataP2PFaaSProtocolVersion); len(values) > 0 {
+		version, _ = strconv.Atoi(values[0])
+	}
+	if version < int(config.GetPeerMinProtocolVersion()) {
+		method, _ := grpc.Method(ctx)
+		log.Log.Warningf("Rejected grpc call %s: protocol version %d is older than %d", method, version, config.GetPeerMinProtocolVersion())
+		return status.Errorf(codes.FailedPrecondition, "protocol version %d is not supported", version)
+	}
+	return nil
+}
+
+func isUserAgentMachine(md metadata.MD) bool {
+	for _, userAgent := range md.Get("user-agent") {
+		// grpc appends its own user agent to the one of the client
+		if strings.HasPrefix(userAgent, config.UserAgentMachine) {
+			return true
+		}
+	}
+	return false
+}
+
+func isPeerVerified(ctx context.Context) bool {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return false
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	return ok && mtls.IsPeerVerified(&tlsInfo.State)
+}
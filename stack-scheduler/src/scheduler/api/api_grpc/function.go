@@ -0,0 +1,163 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package api_grpc
+
+import (
+	"bytes"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"io"
+	"scheduler/api/api_peer"
+	"scheduler/config"
+	"scheduler/errors"
+	"scheduler/log"
+	"scheduler/memdb"
+	"scheduler/peer_proto"
+	"scheduler/scheduler"
+	"scheduler/types"
+	"scheduler/utils"
+)
+
+// ExecuteFunction executes a job sent by another node, as the peer api. Errors of the job are replied as job responses
+// with the error json as body, like the peer api does.
+func (s *peerServer) ExecuteFunction(stream peer_proto.Peer_ExecuteFunctionServer) error {
+	var requestId uint64 = 0
+
+	if err := checkMachine(stream.Context()); err != nil {
+		return err
+	}
+
+	// assign id to requests if development
+	if log.GetEnv() != config.RunningEnvironmentProduction {
+		requestId = memdb.GetNextRequestNumberFromPeers()
+	}
+
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	if first.GetJob() == nil {
+		return status.Error(codes.InvalidArgument, "the first message must be the job")
+	}
+	peerRequest := first.GetJob().ToPeerJobRequest()
+	tracingId := peerRequest.ServiceIdTracing
+
+	log.Log.Debugf("[R#%d,T%s] Request to execute function from peer with grpc", requestId, tracingId)
+
+	payload, err := receivePayload(stream)
+	if err != nil {
+		log.Log.Errorf("[R#%d,T%s] Cannot receive payload: %s", requestId, tracingId, err)
+		if _, ok := err.(utils.ErrorBodyTooLarge); ok {
+			return sendError(stream, errors.PayloadTooLarge)
+		}
+		return err
+	}
+
+	if peerRequest.FunctionName == "" {
+		log.Log.Debugf("[R#%d,T%s] service is not specified", requestId, tracingId)
+		return sendError(stream, errors.InputNotValid)
+	}
+
+	if config.GetDraining() {
+		log.Log.Debugf("[R#%d,T%s] Rejected job from peer: draining", requestId, tracingId)
+		return sendError(stream, errors.MachineDraining)
+	}
+
+	peerRequest.ServiceIdRequest = requestId
+
+	headers := make(map[string]string, len(peerRequest.Headers)+1)
+	for key, value := range peerRequest.Headers {
+		headers[key] = value
+	}
+	if tracingId != "" {
+		headers[utils.HttpHeaderP2PFaaSSchedulerTracingId] = tracingId
+	}
+
+	serviceRequest := types.ServiceRequest{
+		Id:                 requestId,
+		IdTracing:          tracingId,
+		External:           true,
+		ExternalJobRequest: peerRequest,
+		ServiceName:        peerRequest.FunctionName,
+		Payload:            payload,
+		PayloadContentType: peerRequest.ContentType,
+		Headers:            &headers,
+	}
+
+	log.Log.Debugf("[R#%d,T%s] type=%s, len(payload)=%d, len(peers)=%d, service=%s", requestId, tracingId, serviceRequest.PayloadContentType, len(serviceRequest.Payload), len(peerRequest.PeersList), serviceRequest.ServiceName)
+
+	// schedule the job
+	jobResult, err := scheduler.Schedule(&serviceRequest)
+
+	return sendPeerResponse(stream, api_peer.PreparePeerResponse(peerRequest, &serviceRequest, jobResult, err))
+}
+
+// receivePayload reads the payload chunks until the sender closes its side of the stream
+func receivePayload(stream peer_proto.Peer_ExecuteFunctionServer) ([]byte, error) {
+	maxSize := int64(config.GetPayloadMaxSize())
+	var payload bytes.Buffer
+
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return payload.Bytes(), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		payload.Write(req.GetPayloadChunk())
+		if maxSize > 0 && int64(payload.Len()) > maxSize {
+			return nil, utils.ErrorBodyTooLarge{MaxSize: maxSize}
+		}
+	}
+}
+
+// sendPeerResponse sends the job response followed by the body in chunks
+func sendPeerResponse(stream peer_proto.Peer_ExecuteFunctionServer, peerResponse *types.PeerJobResponse) error {
+	err := stream.Send(&peer_proto.ExecuteFunctionResponse{
+		Message: &peer_proto.ExecuteFunctionResponse_Job{Job: peer_proto.NewJobResponse(peerResponse)},
+	})
+	if err != nil {
+		return err
+	}
+
+	body := []byte(peerResponse.Body)
+	for len(body) > 0 {
+		chunkSize := len(body)
+		if chunkSize > peer_proto.ChunkSize {
+			chunkSize = peer_proto.ChunkSize
+		}
+
+		err = stream.Send(&peer_proto.ExecuteFunctionResponse{
+			Message: &peer_proto.ExecuteFunctionResponse_BodyChunk{BodyChunk: body[:chunkSize]},
+		})
+		if err != nil {
+			return err
+		}
+		body = body[chunkSize:]
+	}
+
+	return nil
+}
+
+func sendError(stream peer_proto.Peer_ExecuteFunctionServer, errorCode int) error {
+	statusCode, errorJson, _ := errors.GetErrorJson(errorCode)
+	return sendPeerResponse(stream, &types.PeerJobResponse{StatusCode: statusCode, Body: errorJson})
+}
@@ -0,0 +1,122 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package api_grpc
+
+import (
+	"context"
+	"scheduler/circuit_breaker"
+	"scheduler/config"
+	"scheduler/faas"
+	"scheduler/log"
+	"scheduler/memdb"
+	"scheduler/peer_proto"
+	"scheduler/queue"
+	"scheduler/types"
+	"time"
+)
+
+// MinSubscriptionInterval is the minimum interval at which the load is sent to a subscriber
+const MinSubscriptionInterval = 100 * time.Millisecond
+
+// GetLoad returns the load of the machine, as the load api
+func (s *peerServer) GetLoad(ctx context.Context, _ *peer_proto.LoadRequest) (*peer_proto.Load, error) {
+	if err := checkMachine(ctx); err != nil {
+		return nil, err
+	}
+	return prepareLoad(), nil
+}
+
+// SubscribeLoad sends the load of the machine at the interval of the last subscription received
+func (s *peerServer) SubscribeLoad(stream peer_proto.Peer_SubscribeLoadServer) error {
+	if err := checkMachine(stream.Context()); err != nil {
+		return err
+	}
+
+	subscription, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+
+	// the subscriber can change the interval at any time
+	intervals := make(chan time.Duration)
+	go func() {
+		defer close(intervals)
+		for {
+			subscription, err := stream.Recv()
+			if err != nil {
+				return
+			}
+			select {
+			case intervals <- subscriptionInterval(subscription):
+			case <-stream.Context().Done():
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(subscriptionInterval(subscription))
+	defer ticker.Stop()
+
+	for {
+		if err := stream.Send(prepareLoad()); err != nil {
+			log.Log.Debugf("Cannot send load to subscriber: %s", err)
+			return err
+		}
+
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case interval, ok := <-intervals:
+			if !ok {
+				return nil
+			}
+			ticker.Reset(interval)
+		case <-ticker.C:
+		}
+	}
+}
+
+func subscriptionInterval(subscription *peer_proto.LoadSubscription) time.Duration {
+	interval := time.Duration(subscription.GetIntervalMs()) * time.Millisecond
+	if interval < MinSubscriptionInterval {
+		return MinSubscriptionInterval
+	}
+	return interval
+}
+
+// prepareLoad returns the same load and functions advertised by the load api
+func prepareLoad() *peer_proto.Load {
+	load := &peer_proto.Load{
+		RunningFunctions:     uint32(memdb.GetTotalRunningFunctions()),
+		RunningFunctionsMax:  uint32(config.GetRunningFunctionMax()),
+		QueueLength:          uint32(queue.GetLength()),
+		UnavailableFunctions: circuit_breaker.GetOpenFunctions(),
+		PeerProtocol:         types.PeerProtocolVersion,
+		Draining:             config.GetDraining(),
+		WarmFunctions:        faas.GetWarmFunctions(),
+	}
+
+	// if the functions cannot be retrieved peers will keep the last known ones
+	if functions, err := faas.GetDeployedFunctions(); err == nil {
+		load.Functions = functions
+		load.FunctionsKnown = true
+	}
+
+	return load
+}
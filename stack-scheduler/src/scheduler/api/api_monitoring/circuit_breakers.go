@@ -0,0 +1,38 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package api_monitoring
+
+import (
+	"encoding/json"
+	"net/http"
+	"scheduler/circuit_breaker"
+	"scheduler/errors"
+	"scheduler/utils"
+)
+
+// Retrieve the state of the circuit breakers of the functions.
+func CircuitBreakersGet(w http.ResponseWriter, r *http.Request) {
+	resJson, err := json.Marshal(circuit_breaker.GetFunctionsStatus())
+	if err != nil {
+		errors.ReplyWithError(&w, errors.MarshalError, nil)
+		return
+	}
+
+	utils.HttpSendJSONResponseByte(&w, http.StatusOK, resJson, nil)
+}
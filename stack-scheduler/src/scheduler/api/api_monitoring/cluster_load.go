@@ -0,0 +1,51 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package api_monitoring
+
+import (
+	"encoding/json"
+	"net/http"
+	"scheduler/errors"
+	"scheduler/gossip"
+	"scheduler/utils"
+)
+
+type clusterLoadResponse struct {
+	Enabled bool            `json:"enabled"`
+	Nodes   []gossip.Digest `json:"nodes"`
+}
+
+// Retrieve the load of the nodes of the cluster received through the gossip, with the one of this node.
+func ClusterLoadGet(w http.ResponseWriter, r *http.Request) {
+	res := clusterLoadResponse{
+		Enabled: gossip.IsStarted(),
+		Nodes:   []gossip.Digest{},
+	}
+	if res.Enabled {
+		res.Nodes = gossip.GetClusterLoad()
+	}
+
+	resJson, err := json.Marshal(res)
+	if err != nil {
+		errors.ReplyWithError(&w, errors.MarshalError, nil)
+		return
+	}
+
+	utils.HttpSendJSONResponseByte(&w, http.StatusOK, resJson, nil)
+}
@@ -0,0 +1,38 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package api_monitoring
+
+import (
+	"encoding/json"
+	"net/http"
+	"scheduler/errors"
+	"scheduler/scheduler_service"
+	"scheduler/utils"
+)
+
+// Retrieve the round trip time and the throughput of the links to the peers.
+func LinksGet(w http.ResponseWriter, r *http.Request) {
+	resJson, err := json.Marshal(scheduler_service.GetLinks())
+	if err != nil {
+		errors.ReplyWithError(&w, errors.MarshalError, nil)
+		return
+	}
+
+	utils.HttpSendJSONResponseByte(&w, http.StatusOK, resJson, nil)
+}
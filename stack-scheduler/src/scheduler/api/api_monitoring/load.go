@@ -19,44 +19,98 @@
 package api_monitoring
 
 import (
+	"encoding/json"
 	"net/http"
+	"scheduler/circuit_breaker"
 	"scheduler/config"
+	"scheduler/errors"
+	"scheduler/faas"
+	"scheduler/log"
 	"scheduler/memdb"
 	"scheduler/queue"
+	"scheduler/scheduler"
+	"scheduler/service_discovery"
+	"scheduler/signature"
+	"scheduler/types"
+	"scheduler/utils"
 	"strconv"
+	"strings"
 )
 
-const ApiMonitoringLoadHeaderKey = "X-P2PFaaS-Load"
-const ApiMonitoringMaxLoadHeaderKey = "X-P2PFaaS-MaxLoad"
-const ApiMonitoringQueueLengthHeaderKey = "X-P2PFog-Queue-Length"
-
-// Retrieve the load of the machine.
+// LoadGetLoad replies with the load document of the machine. The load and the peer info are also sent in headers, for
+// the peers which do not read the document. When signatures are enabled only signed requests of peers are served.
 func LoadGetLoad(w http.ResponseWriter, r *http.Request) {
-	w.Header().Add(ApiMonitoringLoadHeaderKey, strconv.Itoa(int(memdb.GetTotalRunningFunctions())))
-	w.Header().Add(ApiMonitoringMaxLoadHeaderKey, strconv.Itoa(int(config.GetRunningFunctionMax())))
-	w.Header().Add(ApiMonitoringQueueLengthHeaderKey, strconv.Itoa(queue.GetLength()))
-
-	w.WriteHeader(200)
-	/*
-		load, err := faas_containers-openfaas.GetCurrentLoad()
-		if err != nil {
-			errors.ReplyWithError(w, errors.GenericError)
-			log.Log.Debugf("%s cannot get current load from openfaas")
+	if signature.Enabled() {
+		if err := signature.VerifyRequest(r); err != nil {
+			log.Log.Warningf("Rejected load request from %s: %s", r.RemoteAddr, err)
+			errors.ReplyWithError(&w, errors.GenericError, nil)
 			return
 		}
+	}
+
+	load := PrepareLoad()
+
+	w.Header().Add(utils.HttpHeaderP2PFaaSLoad, strconv.Itoa(int(load.FunctionsRunning)))
+	w.Header().Add(utils.HttpHeaderP2PFaaSMaxLoad, strconv.Itoa(int(load.FunctionsRunningMax)))
+	w.Header().Add(utils.HttpHeaderP2PFaaSQueueLength, strconv.Itoa(int(load.QueueLength)))
+	w.Header().Add(utils.HttpHeaderP2PFaaSPeerProtocol, strconv.Itoa(types.PeerProtocolVersion))
+	if config.GetPeerTransport() == config.PeerTransportGrpc {
+		w.Header().Add(utils.HttpHeaderP2PFaaSPeerGrpcPort, strconv.Itoa(int(config.GetGrpcListeningPort())))
+	}
+	if utils.IsMachineHttp2Enabled() {
+		w.Header().Add(utils.HttpHeaderP2PFaaSPeerHttp2, "true")
+	}
+	// if the deployed functions cannot be retrieved peers will keep the last known ones
+	if load.FunctionsDeployed != nil {
+		w.Header().Add(utils.HttpHeaderP2PFaaSFunctions, service_discovery.EncodeMachineFunctions(load.FunctionsDeployed))
+	}
+	w.Header().Add(utils.HttpHeaderP2PFaaSFunctionsUnavailable, strings.Join(load.FunctionsUnavailable, ","))
+	w.Header().Add(utils.HttpHeaderP2PFaaSFunctionsWarm, strings.Join(load.FunctionsWarm, ","))
+
+	res, err := json.Marshal(load)
+	if err != nil {
+		log.Log.Errorf("Cannot marshal load: %s", err)
+		errors.ReplyWithError(&w, errors.MarshalError, nil)
+		return
+	}
+
+	utils.HttpSendJSONResponseByte(&w, 200, res, nil)
+}
+
+// PrepareLoad returns the current load document of the machine
+func PrepareLoad() *types.Load {
+	load := &types.Load{
+		Version:              types.LoadVersion,
+		SchedulerName:        scheduler.GetName(),
+		FunctionsRunning:     memdb.GetTotalRunningFunctions(),
+		FunctionsRunningMax:  config.GetRunningFunctionMax(),
+		QueueLength:          uint(queue.GetLength()),
+		QueueLengthMax:       config.GetQueueLengthMax(),
+		RunningOfTypes:       memdb.GetTotalRunningFunctionsOfType(),
+		QueuedOfTypes:        queue.GetLengthOfTypes(),
+		Functions:            make(map[string]types.FunctionLoad),
+		FunctionsUnavailable: circuit_breaker.GetOpenFunctions(),
+		Draining:             config.GetDraining(),
+		FunctionsWarm:        faas.GetWarmFunctions(),
+	}
 
-		res := &types.Load{
-			SchedulerName:          scheduler.GetName(),
-			FunctionsDeployed:      load.NumberOfServices,
-			FunctionsRunning:       memdb.GetTotalRunningFunctions(),
-			FunctionsRunningMax:    config.Configuration.GetRunningFunctionMax(),
-			FunctionsTotalReplicas: load.TotalReplicas,
-			QueueLengthMax:         config.Configuration.GetQueueLengthMax(),
-			QueueFill:              queue.GetQueueFill(),
+	for _, fn := range memdb.GetFunctions() {
+		load.Functions[fn.Name] = types.FunctionLoad{
+			Running:           fn.RunningInstances,
+			LatencyEwma:       fn.LatencyAverage,
+			ExecutionTimeEwma: fn.ExecutionTimeAverage,
+			Warm:              faas.IsFunctionWarm(fn.Name),
 		}
+	}
+	for name, queued := range queue.GetLengthOfFunctions() {
+		functionLoad := load.Functions[name]
+		functionLoad.Queued = uint(queued)
+		load.Functions[name] = functionLoad
+	}
 
-		rep, err := json.Marshal(res)
+	if functions, err := faas.GetDeployedFunctions(); err == nil {
+		load.FunctionsDeployed = functions
+	}
 
-		utils.SendJSONResponse(&w, 200, string(rep))
-	*/
+	return load
 }
@@ -23,7 +23,7 @@ import (
 	"github.com/gorilla/mux"
 	"net/http"
 	"scheduler/errors"
-	"scheduler/faas_openfaas"
+	"scheduler/faas"
 	"scheduler/log"
 	"scheduler/utils"
 	"time"
@@ -41,7 +41,12 @@ func ScaleDelay(w http.ResponseWriter, r *http.Request) {
 	function := vars["function"]
 
 	// before scaling check if the function is stabilized
-	fun, _, err := faas_openfaas.FunctionGet(function)
+	fun, _, err := faas.FunctionGet(function)
+	if err != nil {
+		log.Log.Debugf("Cannot get function: %s", err.Error())
+		errors.ReplyWithError(&w, errors.GenericOpenFaasError, nil)
+		return
+	}
 	if fun.Replicas != fun.AvailableReplicas {
 		log.Log.Debugf("Cannot start monitoring, service is not stabilized")
 		errors.ReplyWithError(&w, errors.InputNotValid, nil)
@@ -49,7 +54,7 @@ func ScaleDelay(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// scale the function
-	_, err = faas_openfaas.FunctionScaleByOne(function)
+	_, err = faas.FunctionScaleByOne(function)
 	if err != nil {
 		log.Log.Debugf("Cannot scale function: %s", err.Error())
 		errors.ReplyWithError(&w, errors.GenericOpenFaasError, nil)
@@ -61,7 +66,7 @@ func ScaleDelay(w http.ResponseWriter, r *http.Request) {
 	var loopTime time.Duration
 	// loop until the available replicas is set
 	for {
-		fun, _, err := faas_openfaas.FunctionGet(function)
+		fun, _, err := faas.FunctionGet(function)
 		attempts += 1
 		if err != nil {
 			log.Log.Debugf("Cannot get function: %s", err.Error())
@@ -76,7 +81,7 @@ func ScaleDelay(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// un-scale the function
-	_, err = faas_openfaas.FunctionScaleDownByOne(function)
+	_, err = faas.FunctionScaleDownByOne(function)
 	if err != nil {
 		log.Log.Debugf("Cannot scale function: %s", err.Error())
 		errors.ReplyWithError(&w, errors.GenericOpenFaasError, nil)
@@ -19,10 +19,7 @@
 package api_peer
 
 import (
-	"encoding/base64"
-	"encoding/json"
 	"github.com/gorilla/mux"
-	"io/ioutil"
 	"net/http"
 	"reflect"
 	"scheduler/api"
@@ -34,6 +31,7 @@ import (
 	"scheduler/service_discovery"
 	"scheduler/types"
 	"scheduler/utils"
+	"strconv"
 )
 
 // FunctionExecute Execute a function. This function must called only by another node, and not a client.
@@ -41,12 +39,21 @@ func FunctionExecute(w http.ResponseWriter, r *http.Request) {
 	var requestId uint64 = 0
 	tracingId := api.HeadersGetRequestTracingId(r)
 
-	if !headersCheckUserAgentMachine(r) {
+	// every reply, errors included, tells the peer protocol, otherwise the peer would downgrade the protocol and resend
+	w.Header().Set(utils.HttpHeaderP2PFaaSPeerProtocol, strconv.Itoa(types.PeerProtocolVersion))
+
+	if !checkMachine(r) {
 		errors.ReplyWithError(&w, errors.GenericError, nil)
 		log.Log.Errorf("[R#%d,T%s] called from not a machine", requestId, tracingId)
 		return
 	}
 
+	if config.GetDraining() {
+		errors.ReplyWithError(&w, errors.MachineDraining, nil)
+		log.Log.Debugf("[R#%d,T%s] Rejected job from peer %s: draining", requestId, tracingId, r.RemoteAddr)
+		return
+	}
+
 	// assign id to requests if development
 	if log.GetEnv() != config.RunningEnvironmentProduction {
 		requestId = memdb.GetNextRequestNumberFromPeers()
@@ -62,18 +69,17 @@ func FunctionExecute(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	bytes, err := ioutil.ReadAll(r.Body)
-	if err != nil {
-		log.Log.Errorf("[R#%d,T%s] Cannot parse input: %s", requestId, tracingId, err)
-		errors.ReplyWithError(&w, errors.InputNotValid, nil)
-		return
-	}
+	// the job is read and replied with the peer protocol of the caller
+	protocolVersion := headersGetPeerProtocol(r)
 
-	var peerRequest types.PeerJobRequest
-	err = json.Unmarshal(bytes, &peerRequest)
+	peerRequest, payload, err := decodePeerRequest(r, protocolVersion)
 	if err != nil {
-		log.Log.Errorf("[R#%d,T%s] Cannot parse json input: %s", requestId, tracingId, err)
-		errors.ReplyWithError(&w, errors.InputNotValid, nil)
+		if _, ok := err.(utils.ErrorBodyTooLarge); ok {
+			errors.ReplyWithError(&w, errors.PayloadTooLarge, nil)
+		} else {
+			errors.ReplyWithError(&w, errors.InputNotValid, nil)
+		}
+		log.Log.Errorf("[R#%d,T%s] Cannot parse input: %s", requestId, tracingId, err)
 		return
 	}
 
@@ -84,35 +90,32 @@ func FunctionExecute(w http.ResponseWriter, r *http.Request) {
 		Id:                 requestId,
 		IdTracing:          tracingId,
 		External:           true,
-		ExternalJobRequest: &peerRequest,
+		ExternalJobRequest: peerRequest,
 		ServiceName:        function,
-		Payload:            []byte(peerRequest.Payload), // the payload is a string because request it's a peer request
+		Payload:            payload,
 		PayloadContentType: peerRequest.ContentType,
 		Headers:            utils.HttpParseXHeaders(r.Header),
 	}
 
-	log.Log.Debugf("[R#%d,T%s] type=%s, len(payload)=%d", requestId, tracingId, serviceRequest.PayloadContentType, len(serviceRequest.Payload))
+	log.Log.Debugf("[R#%d,T%s] type=%s, len(payload)=%d, protocol=%d", requestId, tracingId, serviceRequest.PayloadContentType, len(serviceRequest.Payload), protocolVersion)
 	log.Log.Debugf("[R#%d,T%s] len(peers)=%d, service=%s", requestId, tracingId, len(peerRequest.PeersList), serviceRequest.ServiceName)
 
 	// schedule the job
 	jobResult, err := scheduler.Schedule(&serviceRequest)
 
 	// prepare response
-	peerResponse := preparePeerResponse(&peerRequest, &serviceRequest, jobResult, err)
+	peerResponse := PreparePeerResponse(peerRequest, &serviceRequest, jobResult, err)
 
-	responseBodyBytes, err := json.Marshal(peerResponse)
-	if err != nil {
-		log.Log.Errorf("[R#%d,T%s] Cannot marshal peerResponse: %s", requestId, tracingId, err)
-		errors.ReplyWithError(&w, errors.MarshalError, nil)
+	if protocolVersion >= types.PeerProtocolVersionBinary {
+		replyWithPeerBinaryResponse(&w, peerResponse)
 		return
 	}
-
-	utils.HttpSendJSONResponse(&w, peerResponse.StatusCode, string(responseBodyBytes), nil)
+	replyWithPeerJsonResponse(&w, peerResponse)
 }
 
-// preparePeerResponse Prepares the response to another peer that invoked the function. Remember: jobResult MUST NOT be
-// nil even if there is a scheduleErr!
-func preparePeerResponse(peerRequest *types.PeerJobRequest, serviceRequest *types.ServiceRequest, jobResult *scheduler.JobResult, scheduleErr error) *types.PeerJobResponse {
+// PreparePeerResponse Prepares the response to another peer that invoked the function, the body is encoded later by the
+// peer protocol. Remember: jobResult MUST NOT be nil even if there is a scheduleErr!
+func PreparePeerResponse(peerRequest *types.PeerJobRequest, serviceRequest *types.ServiceRequest, jobResult *scheduler.JobResult, scheduleErr error) *types.PeerJobResponse {
 	log.Log.Debugf("[R#%d,T%s] Preparing peer response of job", serviceRequest.Id, peerRequest.ServiceIdTracing)
 
 	var res = types.PeerJobResponse{}
@@ -132,12 +135,21 @@ func preparePeerResponse(peerRequest *types.PeerJobRequest, serviceRequest *type
 	}
 
 	res.PeersList = jobResult.ExternalExecutionInfo.PeersList
+	res.Attempts = jobResult.Attempts
 	res.Body = ""
+	res.StatusCode = http.StatusOK
 
 	// add response body
-	if jobResult.Response != nil && jobResult.Response.Body != nil {
+	if jobResult.Response != nil {
 		res.Body = string(jobResult.Response.Body)
-		res.StatusCode = 200
+		if jobResult.Response.StatusCode != 0 {
+			res.StatusCode = jobResult.Response.StatusCode
+		}
+	} else if scheduleErr == nil {
+		// the execution failed without a scheduler error
+		statusCode, errorJsonString, _ := errors.GetErrorJsonMessage(errors.GenericError, "Job execution failed")
+		res.StatusCode = statusCode
+		res.Body = errorJsonString
 	}
 
 	// parse scheduler error
@@ -155,6 +167,12 @@ func preparePeerResponse(peerRequest *types.PeerJobRequest, serviceRequest *type
 		} else if _, ok = scheduleErr.(scheduler.JobDeliberatelyRejected); ok {
 			errorStatusCode, errorJsonString, err = errors.GetErrorJsonMessage(errors.JobDeliberatelyRejected, scheduleErr.Error())
 			log.Log.Debugf("[R#%d,T%s] %s", peerRequest.ServiceIdRequest, peerRequest.ServiceIdTracing, scheduleErr.Error())
+		} else if _, ok = scheduleErr.(scheduler.JobExecutionTimeout); ok {
+			errorStatusCode, errorJsonString, err = errors.GetErrorJsonMessage(errors.JobExecutionTimeout, scheduleErr.Error())
+			log.Log.Debugf("[R#%d,T%s] %s", peerRequest.ServiceIdRequest, peerRequest.ServiceIdTracing, scheduleErr.Error())
+		} else if _, ok = scheduleErr.(scheduler.JobCircuitOpen); ok {
+			errorStatusCode, errorJsonString, err = errors.GetErrorJsonMessage(errors.FunctionCircuitOpen, scheduleErr.Error())
+			log.Log.Debugf("[R#%d,T%s] %s", peerRequest.ServiceIdRequest, peerRequest.ServiceIdTracing, scheduleErr.Error())
 		} else if _, ok = scheduleErr.(scheduler.CannotRetrieveAction); ok {
 			errorStatusCode, errorJsonString, err = errors.GetErrorJsonMessage(errors.CannotRetrieveAction, scheduleErr.Error())
 			log.Log.Errorf("[R#%d,T%s] %s", peerRequest.ServiceIdRequest, peerRequest.ServiceIdTracing, scheduleErr.Error())
@@ -179,17 +197,5 @@ func preparePeerResponse(peerRequest *types.PeerJobRequest, serviceRequest *type
 		res.Body = errorJsonString
 	}
 
-	// check if result has a response body
-	if res.Body != "" {
-		// If we have a peer request and we finally executed it here we need to encode the payload in base64
-		// We are the last node of the chain PC --> O --> O --> O <-this
-		if !jobResult.ExternalExecution {
-			// We need to base64 encode the output
-			res.Body = base64.StdEncoding.EncodeToString([]byte(res.Body))
-		} // else {
-		//	res.Body = string(jobResult.Response.Body) // job result from other nodes is always a base64 string
-		//}
-	}
-
 	return &res
 }
@@ -0,0 +1,123 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package api_peer
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"scheduler/config"
+	"scheduler/errors"
+	"scheduler/log"
+	"scheduler/types"
+	"scheduler/utils"
+	"strconv"
+)
+
+// headersGetPeerProtocol returns the peer protocol of the request, requests without the header are of the json protocol
+func headersGetPeerProtocol(req *http.Request) int {
+	version, err := strconv.Atoi(req.Header.Get(utils.HttpHeaderP2PFaaSPeerProtocol))
+	if err != nil || version < types.PeerProtocolVersionJson {
+		return types.PeerProtocolVersionJson
+	}
+	return version
+}
+
+// getPeerBodyMaxSize returns the maximum size of the body of a job request with the passed peer protocol
+func getPeerBodyMaxSize(protocolVersion int) int64 {
+	// the json protocol encodes the payload in base64, so the limit is on the decoded one
+	maxSize := int64(config.GetPayloadMaxSize())
+	if protocolVersion < types.PeerProtocolVersionBinary && maxSize > 0 {
+		maxSize = int64(base64.StdEncoding.EncodedLen(int(maxSize))) + 4096
+	}
+	return maxSize
+}
+
+// decodePeerRequest reads the job request and its payload, not encoded, with the passed peer protocol
+func decodePeerRequest(r *http.Request, protocolVersion int) (*types.PeerJobRequest, []byte, error) {
+	body, err := ioutil.ReadAll(utils.NewLimitedReader(r.Body, getPeerBodyMaxSize(protocolVersion)))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var peerRequest types.PeerJobRequest
+
+	if protocolVersion >= types.PeerProtocolVersionBinary {
+		if peerJob := r.Header.Get(utils.HttpHeaderP2PFaaSPeerJob); peerJob != "" {
+			err = json.Unmarshal([]byte(peerJob), &peerRequest)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		peerRequest.ContentType = r.Header.Get("Content-Type")
+		return &peerRequest, body, nil
+	}
+
+	err = json.Unmarshal(body, &peerRequest)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	payload, err := base64.StdEncoding.DecodeString(peerRequest.Payload)
+	if err != nil {
+		return nil, nil, err
+	}
+	peerRequest.Payload = ""
+
+	return &peerRequest, payload, nil
+}
+
+// replyWithPeerBinaryResponse sends the body as raw bytes and the rest of the job response in a header
+func replyWithPeerBinaryResponse(w *http.ResponseWriter, peerResponse *types.PeerJobResponse) {
+	body := peerResponse.Body
+	peerResponse.Body = ""
+
+	(*w).Header().Set(utils.HttpHeaderP2PFaaSPeerProtocol, strconv.Itoa(types.PeerProtocolVersionBinary))
+
+	peerResponseJson, err := json.Marshal(peerResponse)
+	if err != nil {
+		log.Log.Errorf("Cannot marshal peerResponse: %s", err)
+		errors.ReplyWithError(w, errors.MarshalError, nil)
+		return
+	}
+
+	(*w).Header().Set(utils.HttpHeaderP2PFaaSPeerJob, string(peerResponseJson))
+	(*w).Header().Set("Content-Type", "application/octet-stream")
+	(*w).WriteHeader(peerResponse.StatusCode)
+
+	_, err = (*w).Write([]byte(body))
+	if err != nil {
+		log.Log.Errorf("Cannot write peer response: %s", err)
+	}
+}
+
+// replyWithPeerJsonResponse sends the job response as JSON with the body base64 encoded
+func replyWithPeerJsonResponse(w *http.ResponseWriter, peerResponse *types.PeerJobResponse) {
+	peerResponse.Body = base64.StdEncoding.EncodeToString([]byte(peerResponse.Body))
+
+	peerResponseJson, err := json.Marshal(peerResponse)
+	if err != nil {
+		log.Log.Errorf("Cannot marshal peerResponse: %s", err)
+		errors.ReplyWithError(w, errors.MarshalError, nil)
+		return
+	}
+
+	utils.HttpSendJSONResponse(w, peerResponse.StatusCode, string(peerResponseJson), nil)
+}
@@ -19,10 +19,49 @@
 package api_peer
 
 import (
+	"io/ioutil"
 	"net/http"
 	"scheduler/config"
+	"scheduler/log"
+	"scheduler/mtls"
+	"scheduler/signature"
+	"scheduler/utils"
+	"strconv"
 )
 
+// checkMachine tells if the request comes from another machine, which is authenticated with its certificate and its
+// signature when mtls and signatures are enabled, and with the user agent otherwise, with an accepted protocol version
+func checkMachine(req *http.Request) bool {
+	if mtls.Enabled() && !mtls.IsPeerVerified(req.TLS) {
+		return false
+	}
+	if signature.Enabled() {
+		// the body is read for verifying its digest, so it is limited as the job request
+		maxSize := getPeerBodyMaxSize(headersGetPeerProtocol(req))
+		req.Body = ioutil.NopCloser(utils.NewLimitedReader(req.Body, maxSize))
+
+		if err := signature.VerifyRequest(req); err != nil {
+			log.Log.Warningf("Rejected peer request from %s: %s", req.RemoteAddr, err)
+			return false
+		}
+	}
+	if !mtls.Enabled() && !signature.Enabled() && !headersCheckUserAgentMachine(req) {
+		return false
+	}
+	return checkProtocolVersion(req)
+}
+
+// checkProtocolVersion tells if the machine which sent the request uses a protocol version accepted by this node, the
+// machines which do not send it are of version 0
+func checkProtocolVersion(req *http.Request) bool {
+	version, _ := strconv.Atoi(req.Header.Get(utils.HttpHeaderP2PFaaSProtocolVersion))
+	if version < int(config.GetPeerMinProtocolVersion()) {
+		log.Log.Warningf("Rejected peer request from %s: protocol version %d is older than %d", req.RemoteAddr, version, config.GetPeerMinProtocolVersion())
+		return false
+	}
+	return true
+}
+
 func headersCheckUserAgentMachine(req *http.Request) bool {
 	return req.Header.Get("User-Agent") == config.UserAgentMachine
 }
@@ -63,6 +63,17 @@ func SetConfiguration(w http.ResponseWriter, r *http.Request) {
 	config.SetRunningFunctionMax(newConfiguration.ParallelRunningFunctionsMax)
 	config.SetQueueLengthMax(newConfiguration.QueueLengthMax)
 	config.SetQueueEnabled(newConfiguration.QueueEnabled)
+	config.SetFunctionTimeout(newConfiguration.FunctionTimeout)
+	config.SetFunctionTimeouts(newConfiguration.FunctionTimeouts)
+	config.SetAutoscalerPolicy(newConfiguration.AutoscalerPolicy)
+	config.SetAutoscalerPolicies(newConfiguration.AutoscalerPolicies)
+	config.SetFunctionCaches(newConfiguration.FunctionCaches)
+	config.SetRetryPolicy(newConfiguration.RetryPolicy)
+	config.SetRetryPolicies(newConfiguration.RetryPolicies)
+	config.SetCircuitBreakerPolicy(newConfiguration.CircuitBreakerPolicy)
+	config.SetCircuitBreakerPolicies(newConfiguration.CircuitBreakerPolicies)
+	config.SetDraining(newConfiguration.Draining)
+	config.SetOutlierEjectionPolicy(newConfiguration.OutlierEjectionPolicy)
 
 	// save configuration to file
 	err = config.SaveConfigurationDynamicToConfigFile()
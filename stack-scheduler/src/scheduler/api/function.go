@@ -23,14 +23,17 @@ import (
 	"github.com/gorilla/mux"
 	"io/ioutil"
 	"net/http"
+	"scheduler/config"
 	"scheduler/errors"
 	"scheduler/log"
 	"scheduler/memdb"
 	"scheduler/metrics"
+	"scheduler/result_cache"
 	"scheduler/scheduler"
 	"scheduler/service_discovery"
 	"scheduler/types"
 	"scheduler/utils"
+	"time"
 )
 
 func FunctionPost(w http.ResponseWriter, r *http.Request) {
@@ -66,15 +69,48 @@ func executeFunction(w http.ResponseWriter, r *http.Request) {
 
 	log.Log.Debugf("[R#%d,T%s] Execute function called for %s", requestId, tracingId, function)
 
-	payload, _ := ioutil.ReadAll(r.Body)
+	// the results of cached functions are never streamed, since both the payload and the body must be kept
+	cachePolicy, cacheEnabled := config.GetCachePolicy(function)
+
 	req := types.ServiceRequest{
 		Id:                 requestId,
 		IdTracing:          tracingId,
 		ServiceName:        function,
-		Payload:            payload,
 		PayloadContentType: r.Header.Get("Content-Type"),
 		External:           false,
 		Headers:            utils.HttpParseXHeaders(r.Header),
+		Stream:             config.GetStreamingEnabled() && !cacheEnabled,
+	}
+
+	payload := utils.NewLimitedReader(r.Body, int64(config.GetPayloadMaxSize()))
+	// the payload is streamed to the function only if the job log is disabled, since the job log must record it
+	if req.Stream && !config.GetJobLogEnabled() {
+		payloadStream := utils.NewClosableReader(payload)
+		defer payloadStream.Close()
+		req.PayloadStream = payloadStream
+		req.PayloadStreamLength = r.ContentLength
+	} else {
+		req.Payload, err = ioutil.ReadAll(payload)
+		if payload.Exceeded() {
+			errors.ReplyWithError(&w, errors.PayloadTooLarge, nil)
+			log.Log.Debugf("[R#%d,T%s] %s", requestId, tracingId, err.Error())
+			return
+		}
+		if err != nil {
+			errors.ReplyWithError(&w, errors.InputNotValid, nil)
+			log.Log.Errorf("[R#%d,T%s] Cannot read payload: %s", requestId, tracingId, err.Error())
+			return
+		}
+	}
+
+	// memoized results are replied before scheduling, so they never use an execution slot
+	if cacheEnabled {
+		if entry, hit := result_cache.Get(function, req.Payload); hit {
+			replyWithCachedResult(&req, &w, entry)
+			metrics.PostJobInvocations(function, entry.StatusCode)
+			log.Log.Debugf("[R#%d,T%s] %s replied from cache", requestId, tracingId, function)
+			return
+		}
 	}
 
 	// schedule the function execution forced if development
@@ -91,8 +127,18 @@ func executeFunction(w http.ResponseWriter, r *http.Request) {
 
 	/* This is blocking */
 
+	// a streamed body must be always closed for freeing the execution slot
+	if jobResult != nil && jobResult.Response != nil && jobResult.Response.BodyStream != nil {
+		defer jobResult.Response.BodyStream.Close()
+	}
+
 	// check if any error
-	if err != nil {
+	if err != nil || (jobResult != nil && jobResult.ErrorExecution && payload.Exceeded()) {
+		if payload.Exceeded() {
+			ReplyWithErrorFromJobResult(&w, errors.PayloadTooLarge, jobResult, "")
+			log.Log.Debugf("[R#%d,T%s] payload exceeds the maximum size", requestId, tracingId)
+			return
+		}
 		if _, ok := err.(scheduler.JobCannotBeScheduled); ok {
 			ReplyWithErrorFromJobResult(&w, errors.JobCannotBeScheduledError, jobResult, err.Error())
 			log.Log.Debugf("[R#%d,T%s] %s", requestId, tracingId, err.Error())
@@ -103,6 +149,16 @@ func executeFunction(w http.ResponseWriter, r *http.Request) {
 			log.Log.Debugf("[R#%d,T%s] %s", requestId, tracingId, err.Error())
 			return
 		}
+		if _, ok := err.(scheduler.JobExecutionTimeout); ok {
+			ReplyWithErrorFromJobResult(&w, errors.JobExecutionTimeout, jobResult, err.Error())
+			log.Log.Debugf("[R#%d,T%s] %s", requestId, tracingId, err.Error())
+			return
+		}
+		if _, ok := err.(scheduler.JobCircuitOpen); ok {
+			ReplyWithErrorFromJobResult(&w, errors.FunctionCircuitOpen, jobResult, err.Error())
+			log.Log.Debugf("[R#%d,T%s] %s", requestId, tracingId, err.Error())
+			return
+		}
 		if _, ok := err.(scheduler.CannotRetrieveAction); ok {
 			ReplyWithErrorFromJobResult(&w, errors.CannotRetrieveAction, jobResult, err.Error())
 			log.Log.Errorf("[R#%d,T%s] %s", requestId, tracingId, err.Error())
@@ -154,6 +210,11 @@ func executeFunction(w http.ResponseWriter, r *http.Request) {
 		log.Log.Fatalf("[R#%d,T%s] Job has been executed externally but its peers list is empty", requestId, tracingId)
 	}
 
+	if cacheEnabled && jobResult.Response.BodyStream == nil {
+		ttl := time.Duration(cachePolicy.TTL) * time.Millisecond
+		result_cache.Put(function, req.Payload, jobResult.Response.StatusCode, jobResult.Response.Headers, jobResult.Response.Body, ttl)
+	}
+
 	// reply to client
 	ReplyWithBodyFromJobResult(&req, &w, jobResult)
 
@@ -0,0 +1,75 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package api
+
+import (
+	"fmt"
+	"github.com/gorilla/mux"
+	"io/ioutil"
+	"net/http"
+	"scheduler/async"
+	"scheduler/errors"
+	"scheduler/log"
+	"scheduler/memdb"
+	"scheduler/types"
+	"scheduler/utils"
+)
+
+// FunctionAsyncPost executes the function asynchronously. The client is replied immediately with the call id and the
+// result is posted to the url in the X-Callback-Url header, if set.
+func FunctionAsyncPost(w http.ResponseWriter, r *http.Request) {
+	tracingId := HeadersGetRequestTracingId(r)
+
+	vars := mux.Vars(r)
+	function := vars["function"]
+	if function == "" {
+		errors.ReplyWithErrorMessage(&w, errors.GenericError, fmt.Sprintf("[T%s] service is not specified", tracingId), nil)
+		log.Log.Debugf("[T%s] service is not specified", tracingId)
+		return
+	}
+
+	requestId := memdb.GetNextRequestNumber()
+
+	payload, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		log.Log.Errorf("[R#%d,T%s] Cannot read payload: %s", requestId, tracingId, err)
+		errors.ReplyWithError(&w, errors.InputNotValid, nil)
+		return
+	}
+
+	req := types.ServiceRequest{
+		Id:                 requestId,
+		IdTracing:          tracingId,
+		ServiceName:        function,
+		Payload:            payload,
+		PayloadContentType: r.Header.Get("Content-Type"),
+		External:           false,
+		Headers:            utils.HttpParseXHeaders(r.Header),
+	}
+
+	callId := async.Submit(&req, r.Header.Get(utils.HttpHeaderCallbackUrl))
+
+	log.Log.Debugf("[R#%d,T%s] Async execution of %s accepted with call id %s", requestId, tracingId, function, callId)
+
+	headers := HttpGetHeadersFromFramework()
+	headers[utils.HttpHeaderCallId] = callId
+	utils.HttpAddHeadersToResponse(&w, &headers)
+
+	w.WriteHeader(http.StatusAccepted)
+}
@@ -24,8 +24,9 @@ import (
 	"io/ioutil"
 	"net/http"
 	"scheduler/errors"
-	"scheduler/faas_openfaas"
+	"scheduler/faas"
 	"scheduler/log"
+	"scheduler/types"
 	"scheduler/utils"
 )
 
@@ -45,17 +46,17 @@ func SystemScaleFunctionPost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var params faas_openfaas.FunctionScalePayload
+	var params types.FaasFunctionScalePayload
 	err = json.Unmarshal(bytes, &params)
 	if err != nil {
 		log.Log.Debugf("Cannot parse json input: %s", err)
 		errors.ReplyWithError(&w, errors.InputNotValid, nil)
 		return
 	}
-	res, err := faas_openfaas.FunctionScale(function, params.Replicas)
+	res, err := faas.FunctionScale(function, params.Replicas)
 	if err != nil {
 		log.Log.Debugf("Cannot scale function: %s", err)
-		errors.ReplyWithError(&w, errors.GenericOpenFaasError, nil)
+		replyWithFaasError(&w, errors.GenericOpenFaasError, err)
 		return
 	}
 
@@ -0,0 +1,121 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"scheduler/cluster"
+	"scheduler/errors"
+	"scheduler/log"
+	"scheduler/types"
+	"scheduler/utils"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// isClusterRequest tells if the request must involve all the nodes of the cluster, i.e. it has ?cluster=true
+func isClusterRequest(r *http.Request) bool {
+	return strings.ToLower(r.URL.Query().Get("cluster")) == "true"
+}
+
+// systemFunctionsClusterGet replies with the functions deployed in the cluster and the nodes which host them
+func systemFunctionsClusterGet(w http.ResponseWriter) {
+	functions, err := cluster.GetFunctions()
+	if err != nil {
+		errors.ReplyWithError(&w, errors.DiscoveryConnectError, nil)
+		return
+	}
+
+	functionsJson, err := json.Marshal(functions)
+	if err != nil {
+		errors.ReplyWithError(&w, errors.MarshalError, nil)
+		return
+	}
+
+	utils.HttpSendJSONResponseByte(&w, http.StatusOK, functionsJson, nil)
+}
+
+// systemFunctionsClusterPost deploys the function on the nodes selected by the "groups" and "nodes" query parameters,
+// which are comma separated lists. The reply is 200 if all the nodes succeeded and 207 otherwise, failed nodes are
+// retried in background.
+func systemFunctionsClusterPost(w http.ResponseWriter, r *http.Request, function types.FaasFunction) {
+	if function.Service == "" {
+		errors.ReplyWithError(&w, errors.ServiceNotValid, nil)
+		log.Log.Debugf("service is not specified")
+		return
+	}
+
+	selector := cluster.ParseSelector(r.URL.Query().Get("groups"), r.URL.Query().Get("nodes"))
+
+	deployment, err := cluster.Deploy(function, selector)
+	if err != nil {
+		switch err.(type) {
+		case cluster.ErrorNoNodeSelected:
+			errors.ReplyWithError(&w, errors.ClusterNoNodeSelected, nil)
+		default:
+			errors.ReplyWithError(&w, errors.DiscoveryConnectError, nil)
+		}
+		return
+	}
+
+	deploymentJson, err := json.Marshal(deployment)
+	if err != nil {
+		errors.ReplyWithError(&w, errors.MarshalError, nil)
+		return
+	}
+
+	statusCode := http.StatusOK
+	if deployment.SucceededNodes() < len(deployment.Nodes) {
+		statusCode = http.StatusMultiStatus
+	}
+
+	utils.HttpSendJSONResponseByte(&w, statusCode, deploymentJson, nil)
+
+	log.Log.Debugf("%s deployed on %d/%d nodes", function.Service, deployment.SucceededNodes(), len(deployment.Nodes))
+}
+
+// SystemClusterDeploymentsGet replies with the last cluster deployments and the state of their nodes
+func SystemClusterDeploymentsGet(w http.ResponseWriter, r *http.Request) {
+	deploymentsJson, err := json.Marshal(cluster.GetDeployments())
+	if err != nil {
+		errors.ReplyWithError(&w, errors.MarshalError, nil)
+		return
+	}
+
+	utils.HttpSendJSONResponseByte(&w, http.StatusOK, deploymentsJson, nil)
+}
+
+// SystemClusterDeploymentGet replies with a single cluster deployment
+func SystemClusterDeploymentGet(w http.ResponseWriter, r *http.Request) {
+	deployment, err := cluster.GetDeployment(mux.Vars(r)["id"])
+	if err != nil {
+		errors.ReplyWithError(&w, errors.GenericNotFoundError, nil)
+		return
+	}
+
+	deploymentJson, err := json.Marshal(deployment)
+	if err != nil {
+		errors.ReplyWithError(&w, errors.MarshalError, nil)
+		return
+	}
+
+	utils.HttpSendJSONResponseByte(&w, http.StatusOK, deploymentJson, nil)
+}
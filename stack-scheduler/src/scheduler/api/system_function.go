@@ -19,10 +19,11 @@
 package api
 
 import (
+	"encoding/json"
 	"github.com/gorilla/mux"
 	"net/http"
 	"scheduler/errors"
-	"scheduler/faas_openfaas"
+	"scheduler/faas"
 	"scheduler/log"
 	"scheduler/utils"
 )
@@ -36,14 +37,20 @@ func SystemFunctionGet(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	_, res, err := faas_openfaas.FunctionGet(function)
+	fn, _, err := faas.FunctionGet(function)
 	if err != nil {
-		errors.ReplyWithError(&w, errors.GenericOpenFaasError, nil)
+		replyWithFaasError(&w, errors.GenericOpenFaasError, err)
 		log.Log.Debugf("cannot get the service: %s", err.Error())
 		return
 	}
 
-	utils.HttpSendJSONResponseByte(&w, res.StatusCode, res.Body, nil)
+	fnJson, err := json.Marshal(fn)
+	if err != nil {
+		errors.ReplyWithError(&w, errors.MarshalError, nil)
+		return
+	}
+
+	utils.HttpSendJSONResponseByte(&w, http.StatusOK, fnJson, nil)
 
 	log.Log.Debugf("%s success", function)
 }
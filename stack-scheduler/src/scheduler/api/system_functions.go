@@ -20,33 +20,55 @@ package api
 
 import (
 	"encoding/json"
+	"io/ioutil"
 	"net/http"
 	"scheduler/errors"
-	"scheduler/faas_openfaas"
+	"scheduler/faas"
 	"scheduler/log"
+	"scheduler/types"
 	"scheduler/utils"
 )
 
 func SystemFunctionsGet(w http.ResponseWriter, r *http.Request) {
-	_, res, err := faas_openfaas.FunctionsGet()
+	if isClusterRequest(r) {
+		systemFunctionsClusterGet(w)
+		return
+	}
+
+	functions, _, err := faas.FunctionsGet()
 	if err != nil {
-		log.Log.Errorf("Cannot get functions from openfaas")
-		errors.ReplyWithError(&w, errors.GenericOpenFaasError, nil)
+		log.Log.Errorf("Cannot get functions from faas backend: %s", err)
+		replyWithFaasError(&w, errors.GenericOpenFaasError, err)
 		return
 	}
 
-	utils.HttpSendJSONResponseByte(&w, res.StatusCode, res.Body, nil)
+	if functions == nil {
+		functions = []types.FaasFunction{}
+	}
+
+	functionsJson, err := json.Marshal(functions)
+	if err != nil {
+		errors.ReplyWithError(&w, errors.MarshalError, nil)
+		return
+	}
+
+	utils.HttpSendJSONResponseByte(&w, http.StatusOK, functionsJson, nil)
 
 	log.Log.Debugf("success")
 }
 
 func SystemFunctionsPost(w http.ResponseWriter, r *http.Request) {
-	var service faas_openfaas.Service
+	var service types.FaasService
 	_ = json.NewDecoder(r.Body).Decode(&service)
 
-	res, err := faas_openfaas.FunctionDeploy(service.OpenFaaSFunction)
+	if isClusterRequest(r) {
+		systemFunctionsClusterPost(w, r, service.OpenFaaSFunction)
+		return
+	}
+
+	res, err := faas.FunctionDeploy(service.OpenFaaSFunction)
 	if err != nil {
-		errors.ReplyWithError(&w, errors.GenericDeployError, nil)
+		replyWithFaasError(&w, errors.GenericDeployError, err)
 		return
 	}
 
@@ -55,10 +77,72 @@ func SystemFunctionsPost(w http.ResponseWriter, r *http.Request) {
 	log.Log.Debugf("success")
 }
 
+// SystemFunctionsPut updates a deployed function. The function can be passed as in the deploy or as the OpenFaaS
+// gateway accepts it, so that faas-cli can be used.
 func SystemFunctionsPut(w http.ResponseWriter, r *http.Request) {
+	reqBody, _ := ioutil.ReadAll(r.Body)
+
+	var service types.FaasService
+	err := json.Unmarshal(reqBody, &service)
+	if err != nil {
+		errors.ReplyWithError(&w, errors.InputNotValid, nil)
+		return
+	}
+
+	function := service.OpenFaaSFunction
+	if function.Service == "" && function.Name == "" {
+		err = json.Unmarshal(reqBody, &function)
+		if err != nil {
+			errors.ReplyWithError(&w, errors.InputNotValid, nil)
+			return
+		}
+	}
 
+	// OpenFaaS identifies the function by the service field
+	if function.Service == "" {
+		function.Service = function.Name
+	}
+	if function.Service == "" {
+		errors.ReplyWithError(&w, errors.ServiceNotValid, nil)
+		log.Log.Debugf("service is not specified")
+		return
+	}
+
+	res, err := faas.FunctionUpdate(function)
+	if err != nil {
+		log.Log.Errorf("Cannot update function %s: %s", function.Service, err)
+		replyWithFaasError(&w, errors.GenericUpdateError, err)
+		return
+	}
+
+	utils.HttpSendJSONResponseByte(&w, res.StatusCode, res.Body, nil)
+
+	log.Log.Debugf("%s updated", function.Service)
 }
 
+// SystemFunctionsDelete removes a deployed function, the payload is the same accepted by the OpenFaaS gateway
 func SystemFunctionsDelete(w http.ResponseWriter, r *http.Request) {
+	var payload types.FaasFunctionRemovePayload
+	err := json.NewDecoder(r.Body).Decode(&payload)
+	if err != nil {
+		errors.ReplyWithError(&w, errors.InputNotValid, nil)
+		return
+	}
+
+	if payload.FunctionName == "" {
+		errors.ReplyWithError(&w, errors.ServiceNotValid, nil)
+		log.Log.Debugf("service is not specified")
+		return
+	}
+
+	res, err := faas.FunctionRemove(payload.FunctionName)
+	if err != nil {
+		log.Log.Errorf("Cannot remove function %s: %s", payload.FunctionName, err)
+		replyWithFaasError(&w, errors.GenericRemoveError, err)
+		return
+	}
+
+	utils.HttpSendJSONResponseByte(&w, res.StatusCode, res.Body, nil)
 
+	log.Log.Debugf("%s removed", payload.FunctionName)
 }
@@ -19,22 +19,27 @@
 package api
 
 import (
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"scheduler/config"
 	"scheduler/errors"
+	"scheduler/faas"
 	"scheduler/log"
+	"scheduler/result_cache"
 	"scheduler/scheduler"
 	"scheduler/types"
 	"scheduler/utils"
+	"strings"
 )
 
 /*
  * Utils
  */
 
+const httpHeaderP2PFaaSPrefix = "X-P2pfaas-"
+
 func headersCheckSchedulerBypass(req *http.Request) bool {
 	return req.Header.Get(utils.HttpHeaderP2PFaaSSchedulerBypass) != ""
 }
@@ -120,6 +125,9 @@ func HttpGetHeadersFunctionExecution(jobResult *scheduler.JobResult) map[string]
 		if jobResult.Timings.ExecutionTime != nil {
 			output[utils.HttpHeaderP2PFaaSExecutionTime] = fmt.Sprintf("%f", *jobResult.Timings.ExecutionTime)
 		}
+		if jobResult.Timings.ColdStart != nil {
+			output[utils.HttpHeaderP2PFaaSColdStart] = httpHeaderBoolValue(*jobResult.Timings.ColdStart)
+		}
 	}
 
 	// jobResult has been executed externally, so we have a list of times
@@ -135,6 +143,9 @@ func HttpGetHeadersFunctionExecution(jobResult *scheduler.JobResult) map[string]
 		if jobResult.ExternalExecutionInfo.PeersList[0].Timings.ExecutionTime != nil {
 			output[utils.HttpHeaderP2PFaaSExecutionTime] = fmt.Sprintf("%f", *jobResult.ExternalExecutionInfo.PeersList[0].Timings.ExecutionTime)
 		}
+		if jobResult.ExternalExecutionInfo.PeersList[0].Timings.ColdStart != nil {
+			output[utils.HttpHeaderP2PFaaSColdStart] = httpHeaderBoolValue(*jobResult.ExternalExecutionInfo.PeersList[0].Timings.ColdStart)
+		}
 
 		var ipList []string
 		var idList []string
@@ -180,9 +191,53 @@ func HttpGetHeadersFunctionExecution(jobResult *scheduler.JobResult) map[string]
 		output[utils.HttpHeaderP2PFaaSSchedulingTimingsList] = fmt.Sprintf("%s", string(schedulingTimesJ))
 	}
 
+	output = utils.MapsMerge(output, HttpGetHeadersAttempts(jobResult))
+
+	return output
+}
+
+// HttpGetHeadersAttempts returns the headers which report all the attempts of a job which has been retried
+func HttpGetHeadersAttempts(jobResult *scheduler.JobResult) map[string]string {
+	output := map[string]string{}
+
+	if jobResult == nil || len(jobResult.Attempts) <= 1 {
+		return output
+	}
+
+	var attemptsTimes []float64
+	var attemptsIps []string
+	var attemptsStatuses []string
+
+	for _, attempt := range jobResult.Attempts {
+		attemptsTimes = append(attemptsTimes, attempt.Time)
+		attemptsIps = append(attemptsIps, attempt.MachineIp)
+		if attempt.StatusCode != 0 {
+			attemptsStatuses = append(attemptsStatuses, fmt.Sprintf("%d", attempt.StatusCode))
+		} else {
+			attemptsStatuses = append(attemptsStatuses, attempt.Error)
+		}
+	}
+
+	attemptsTimesJ, _ := json.Marshal(attemptsTimes)
+	attemptsIpsJ, _ := json.Marshal(attemptsIps)
+	attemptsStatusesJ, _ := json.Marshal(attemptsStatuses)
+
+	output[utils.HttpHeaderP2PFaaSAttempts] = fmt.Sprintf("%d", len(jobResult.Attempts))
+	output[utils.HttpHeaderP2PFaaSAttemptsTimingsList] = string(attemptsTimesJ)
+	output[utils.HttpHeaderP2PFaaSAttemptsIpList] = string(attemptsIpsJ)
+	output[utils.HttpHeaderP2PFaaSAttemptsStatusList] = string(attemptsStatusesJ)
+
 	return output
 }
 
+// httpHeaderBoolValue returns the value of a boolean header, formatted as the other P2PFaaS headers
+func httpHeaderBoolValue(value bool) string {
+	if value {
+		return "True"
+	}
+	return "False"
+}
+
 func ReplyWithErrorFromJobResult(w *http.ResponseWriter, errorCode int, jobResult *scheduler.JobResult, message string) {
 	finalHeaders := HttpGetHeadersFromFramework()
 
@@ -199,6 +254,8 @@ func ReplyWithErrorFromJobResult(w *http.ResponseWriter, errorCode int, jobResul
 				HttpGetHeadersXFromResponse(jobResult.Response),
 			)
 		}
+
+		finalHeaders = utils.MapsMerge(finalHeaders, HttpGetHeadersAttempts(jobResult))
 	}
 
 	utils.HttpAddHeadersToResponse(w, &finalHeaders)
@@ -213,6 +270,11 @@ func ReplyWithErrorFromJobResult(w *http.ResponseWriter, errorCode int, jobResul
 func ReplyWithBodyFromJobResult(serviceRequest *types.ServiceRequest, w *http.ResponseWriter, jobResult *scheduler.JobResult) {
 	var err error
 
+	if jobResult.Response.BodyStream != nil {
+		replyWithBodyStreamFromJobResult(serviceRequest, w, jobResult)
+		return
+	}
+
 	// add headers
 	finalHeaders := utils.MapsMerge(
 		HttpGetHeadersFromFramework(),
@@ -228,25 +290,95 @@ func ReplyWithBodyFromJobResult(serviceRequest *types.ServiceRequest, w *http.Re
 	if jobResult.Response.Body != nil && len(jobResult.Response.Body) > 0 {
 		log.Log.Debugf("[R#%d,T%s] Job body has length %d, external=%t", serviceRequest.Id, serviceRequest.IdTracing, len(jobResult.Response.Body), jobResult.ExternalExecution)
 
-		var outputBody []byte
-
-		// decode the job output if it has been executed externally, since when a node offload a jobs, the remote note
-		// will reply with base64 encoded body
-		if jobResult.ExternalExecution {
-			outputBody, err = base64.StdEncoding.DecodeString(string(jobResult.Response.Body))
-			if err != nil {
-				log.Log.Errorf("[R#%d,T%s] Cannot decode job output: %s", serviceRequest.IdTracing, serviceRequest.Id, err)
-				return
-			}
-		} else {
-			outputBody = jobResult.Response.Body
-		}
-
 		// Write response
-		_, err = (*w).Write(outputBody)
+		_, err = (*w).Write(jobResult.Response.Body)
 		if err != nil {
 			log.Log.Errorf("[R#%d,T%s] Cannot write job output: %s", serviceRequest.Id, serviceRequest.IdTracing, err.Error())
 			return
 		}
 	}
 }
+
+// replyWithCachedResult replies with a memoized result, the framework headers of the execution which produced it are
+// not sent again
+func replyWithCachedResult(serviceRequest *types.ServiceRequest, w *http.ResponseWriter, entry *result_cache.Entry) {
+	responseHeaders := HttpGetHeadersXFromResponse(&types.APIResponse{Headers: entry.Headers})
+	for key := range responseHeaders {
+		if strings.HasPrefix(key, httpHeaderP2PFaaSPrefix) {
+			delete(responseHeaders, key)
+		}
+	}
+	finalHeaders := utils.MapsMerge(responseHeaders, HttpGetHeadersFromFramework())
+	finalHeaders[utils.HttpHeaderP2PFaaSCacheHit] = httpHeaderBoolValue(true)
+	utils.HttpAddHeadersToResponse(w, &finalHeaders)
+
+	(*w).WriteHeader(entry.StatusCode)
+
+	_, err := (*w).Write(entry.Body)
+	if err != nil {
+		log.Log.Errorf("[R#%d,T%s] Cannot write cached job output: %s", serviceRequest.Id, serviceRequest.IdTracing, err.Error())
+	}
+}
+
+// httpTrailersStreamedFunctionExecution are the headers which are known only after a streamed body has been sent
+var httpTrailersStreamedFunctionExecution = []string{
+	utils.HttpHeaderP2PFaaSExecutionTime,
+	utils.HttpHeaderP2PFaaSTotalTimingsList,
+}
+
+// replyWithBodyStreamFromJobResult copies the streamed body of the function to the client, the timings which include
+// the streaming of the body are sent as trailers
+func replyWithBodyStreamFromJobResult(serviceRequest *types.ServiceRequest, w *http.ResponseWriter, jobResult *scheduler.JobResult) {
+	finalHeaders := utils.MapsMerge(
+		HttpGetHeadersFromFramework(),
+		HttpGetHeadersFromJobResult(jobResult),
+		HttpGetHeadersXFromResponse(jobResult.Response),
+		HttpGetHeadersFunctionExecution(jobResult),
+	)
+	for _, trailer := range httpTrailersStreamedFunctionExecution {
+		delete(finalHeaders, trailer)
+		(*w).Header().Add("Trailer", trailer)
+	}
+	utils.HttpAddHeadersToResponse(w, &finalHeaders)
+
+	(*w).WriteHeader(jobResult.Response.StatusCode)
+
+	body := utils.NewLimitedReader(jobResult.Response.BodyStream, int64(config.GetResponseMaxSize()))
+	written, err := io.Copy(*w, body)
+	_ = jobResult.Response.BodyStream.Close()
+	if err != nil {
+		// the status has been already sent, so the client sees a truncated body
+		log.Log.Errorf("[R#%d,T%s] Cannot stream job output after %d bytes: %s", serviceRequest.Id, serviceRequest.IdTracing, written, err.Error())
+		return
+	}
+
+	log.Log.Debugf("[R#%d,T%s] Job body streamed with length %d", serviceRequest.Id, serviceRequest.IdTracing, written)
+
+	// closing the body updated the execution time
+	utils.ComputeTimings(jobResult.TimingsStart, jobResult.Timings)
+	trailers := HttpGetHeadersFunctionExecution(jobResult)
+	for _, trailer := range httpTrailersStreamedFunctionExecution {
+		if value, exists := trailers[trailer]; exists {
+			(*w).Header().Set(trailer, value)
+		}
+	}
+}
+
+// replyWithFaasError replies with the error returned by a faas backend, defaultErrorCode is used when the error is not
+// specific to backends
+func replyWithFaasError(w *http.ResponseWriter, defaultErrorCode int, err error) {
+	if _, ok := err.(errors.ErrorFaasOperationNotSupported); ok {
+		errors.ReplyWithErrorMessage(w, errors.FaasOperationNotSupported, err.Error(), nil)
+		return
+	}
+	if _, ok := err.(faas.ErrorBackendNotRegistered); ok {
+		errors.ReplyWithErrorMessage(w, errors.FaasBackendNotAvailable, err.Error(), nil)
+		return
+	}
+	if faas.IsErrorFunctionNotFound(err) {
+		errors.ReplyWithError(w, errors.GenericNotFoundError, nil)
+		return
+	}
+
+	errors.ReplyWithError(w, defaultErrorCode, nil)
+}
@@ -0,0 +1,57 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package async implements the asynchronous execution of functions. The client is replied immediately and the result
+// of the execution is posted to a callback url, if given. Accepted jobs are recorded in the job log so that they are
+// executed even if the node restarts.
+package async
+
+import (
+	"scheduler/joblog"
+	"scheduler/log"
+	"scheduler/memdb"
+	"scheduler/types"
+	"scheduler/utils"
+)
+
+// Start replays the async jobs which were accepted but not completed before the last shutdown
+func Start() {
+	for _, entry := range joblog.GetPending(joblog.KindAsync) {
+		req := entry.ServiceRequest()
+		req.Id = memdb.GetNextRequestNumber()
+
+		log.Log.Infof("[R#%d,T%s] Replaying async job %s from job log", req.Id, req.IdTracing, req.ServiceName)
+
+		go execute(req, entry.CallbackUrl)
+	}
+}
+
+// Submit accepts the request for asynchronous execution and returns the call id assigned to it
+func Submit(req *types.ServiceRequest, callbackUrl string) string {
+	req.Async = true
+	req.JobLogId = utils.GenerateUniqueId()
+
+	_, err := joblog.Accepted(joblog.KindAsync, req, callbackUrl)
+	if err != nil {
+		log.Log.Warningf("[R#%d,T%s] Async job is not recorded, it will be lost on restart: %s", req.Id, req.IdTracing, err)
+	}
+
+	go execute(req, callbackUrl)
+
+	return req.JobLogId
+}
@@ -0,0 +1,103 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package async
+
+import (
+	"fmt"
+	"scheduler/errors"
+	"scheduler/joblog"
+	"scheduler/log"
+	"scheduler/scheduler"
+	"scheduler/types"
+	"scheduler/utils"
+	"time"
+)
+
+// CallbackMaxAttempts is the number of times the delivery of the result to the callback url is tried
+const CallbackMaxAttempts = 3
+
+// CallbackRetryDelay is the delay between two attempts of delivering the result
+const CallbackRetryDelay = 2 * time.Second
+
+// execute schedules the request and delivers the result to the callback url, then marks the job as completed
+func execute(req *types.ServiceRequest, callbackUrl string) {
+	jobResult, err := scheduler.Schedule(req)
+
+	/* This is blocking */
+
+	statusCode, body, contentType := prepareCallbackBody(req, jobResult, err)
+	log.Log.Debugf("[R#%d,T%s] Async job %s executed with status=%d", req.Id, req.IdTracing, req.ServiceName, statusCode)
+
+	if callbackUrl != "" {
+		deliverCallback(req, callbackUrl, statusCode, body, contentType)
+	}
+
+	_ = joblog.Completed(req.JobLogId)
+}
+
+// prepareCallbackBody returns the status code, the body and its content type of the result of the execution
+func prepareCallbackBody(req *types.ServiceRequest, jobResult *scheduler.JobResult, scheduleErr error) (int, []byte, string) {
+	if scheduleErr != nil || jobResult == nil || jobResult.Response == nil {
+		message := "job result is empty"
+		if scheduleErr != nil {
+			message = scheduleErr.Error()
+		}
+		errorCode := errors.GenericError
+		if _, ok := scheduleErr.(scheduler.JobExecutionTimeout); ok {
+			errorCode = errors.JobExecutionTimeout
+		} else if _, ok := scheduleErr.(scheduler.JobCircuitOpen); ok {
+			errorCode = errors.FunctionCircuitOpen
+		}
+		statusCode, errorJson, _ := errors.GetErrorJsonMessage(errorCode, message)
+		return statusCode, []byte(errorJson), "application/json"
+	}
+
+	body := jobResult.Response.Body
+
+	contentType := ""
+	if jobResult.Response.Headers != nil {
+		contentType = jobResult.Response.Headers.Get("Content-Type")
+	}
+
+	return jobResult.Response.StatusCode, body, contentType
+}
+
+func deliverCallback(req *types.ServiceRequest, callbackUrl string, statusCode int, body []byte, contentType string) {
+	headers := []utils.HttpHeader{
+		{Key: utils.HttpHeaderCallId, Value: req.JobLogId},
+		{Key: utils.HttpHeaderFunctionName, Value: req.ServiceName},
+		{Key: utils.HttpHeaderFunctionStatus, Value: fmt.Sprintf("%d", statusCode)},
+	}
+
+	for attempt := 1; attempt <= CallbackMaxAttempts; attempt++ {
+		res, err := utils.HttpPostWithHeaders(callbackUrl, body, contentType, headers)
+		if err == nil {
+			_ = res.Body.Close()
+			if res.StatusCode < 500 {
+				return
+			}
+			err = fmt.Errorf("callback replied with status %d", res.StatusCode)
+		}
+
+		log.Log.Warningf("[R#%d,T%s] Cannot deliver result to %s, attempt %d of %d: %s", req.Id, req.IdTracing, callbackUrl, attempt, CallbackMaxAttempts, err)
+		time.Sleep(CallbackRetryDelay)
+	}
+
+	log.Log.Errorf("[R#%d,T%s] Result of async job is not delivered to %s", req.Id, req.IdTracing, callbackUrl)
+}
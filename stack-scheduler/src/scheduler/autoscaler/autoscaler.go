@@ -0,0 +1,243 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package autoscaler implements a control loop which scales the replicas of the functions according to their queued
+// jobs, running executions and latency. Every function is scaled within the bounds of its policy, with cooldowns
+// between scaling actions and the scale to zero after a period of idleness. When the replicas are shared by all the
+// schedulers, the load of all of them is considered.
+package autoscaler
+
+import (
+	"fmt"
+	"scheduler/config"
+	"scheduler/faas"
+	"scheduler/log"
+	"scheduler/memdb"
+	"scheduler/queue"
+	"scheduler/types"
+	"sync"
+	"time"
+)
+
+// DecisionsHistoryMax is the number of scaling decisions which are kept for monitoring
+const DecisionsHistoryMax = 100
+
+var mutex sync.Mutex
+var started = false
+
+var functionsStatus = make(map[string]*FunctionStatus)
+var decisions []Decision
+
+// Start starts the autoscaling control loop, it does nothing if the autoscaler is not enabled
+func Start() {
+	if !config.GetAutoscalerEnabled() {
+		log.Log.Infof("Autoscaler is disabled")
+		return
+	}
+
+	mutex.Lock()
+	started = true
+	mutex.Unlock()
+
+	log.Log.Infof("Starting autoscaler with interval %dms", config.GetAutoscalerInterval())
+
+	go looper()
+}
+
+func looper() {
+	for {
+		time.Sleep(time.Duration(config.GetAutoscalerInterval()) * time.Millisecond)
+		evaluateFunctions()
+	}
+}
+
+// evaluateFunctions scales every function deployed in the faas backend, if needed. Backends shared by many schedulers
+// are scaled only by their leader, according to the load of all the schedulers.
+func evaluateFunctions() {
+	if !faas.IsScalingLeader() {
+		return
+	}
+
+	functions, _, err := faas.FunctionsGet()
+	if err != nil {
+		log.Log.Errorf("Autoscaler cannot get functions from faas backend: %s", err)
+		return
+	}
+
+	queued := queue.GetLengthOfFunctions()
+	now := time.Now()
+
+	cluster := clusterLoad{complete: true}
+	if faas.HasSharedFunctions() {
+		cluster = getClusterLoad()
+	}
+
+	for _, function := range functions {
+		evaluateFunction(function, queued[function.Name], cluster, now)
+	}
+}
+
+func evaluateFunction(function types.FaasFunction, queued int, cluster clusterLoad, now time.Time) {
+	policy := config.GetAutoscalerPolicy(function.Name)
+	// the function is not in memdb if it has never been executed
+	executionState, _ := memdb.GetFunction(function.Name)
+
+	mutex.Lock()
+	status := getFunctionStatus(function.Name, now)
+	status.Replicas = function.Replicas
+	status.AvailableReplicas = function.AvailableReplicas
+	status.Queued = queued + cluster.queued[function.Name]
+	status.Running = executionState.RunningInstances + cluster.running[function.Name]
+	status.ClusterLoadPartial = !cluster.complete
+	status.Latency = 0
+	// the average is only updated when a job completes, so after a burst it would stay high forever
+	if now.Sub(executionState.LastExecutionAt) <= latencyWindow(policy) {
+		status.Latency = executionState.LatencyAverage
+	}
+	status.Policy = policy
+	if executionState.LastExecutionAt.After(status.LastActivityAt) {
+		status.LastActivityAt = executionState.LastExecutionAt
+	}
+	if status.Queued > 0 || status.Running > 0 {
+		status.LastActivityAt = now
+	}
+
+	target, reason := computeTargetReplicas(status, now)
+	if target == function.Replicas {
+		mutex.Unlock()
+		return
+	}
+
+	// the cooldown starts even if the scale fails, in this way a failing backend is not called at every loop
+	status.LastScaleAt = now
+	mutex.Unlock()
+
+	log.Log.Infof("Autoscaler scaling %s from %d to %d replicas: %s", function.Name, function.Replicas, target, reason)
+
+	decision := Decision{
+		Function:     function.Name,
+		Time:         now,
+		FromReplicas: function.Replicas,
+		ToReplicas:   target,
+		Reason:       reason,
+	}
+
+	_, err := faas.FunctionScale(function.Name, target)
+	if err != nil {
+		log.Log.Errorf("Autoscaler cannot scale %s: %s", function.Name, err)
+		decision.Error = err.Error()
+	}
+
+	addDecision(decision)
+}
+
+// computeTargetReplicas returns the replicas the function should have and the reason of the change
+func computeTargetReplicas(status *FunctionStatus, now time.Time) (uint, string) {
+	policy := status.Policy
+	replicas := status.Replicas
+
+	maxReplicas := policy.MaxReplicas
+	if maxReplicas < policy.MinReplicas {
+		maxReplicas = policy.MinReplicas
+	}
+
+	// bounds are enforced regardless of the cooldowns
+	if replicas < policy.MinReplicas {
+		return policy.MinReplicas, fmt.Sprintf("replicas below the minimum of %d", policy.MinReplicas)
+	}
+	if replicas > maxReplicas {
+		return maxReplicas, fmt.Sprintf("replicas above the maximum of %d", maxReplicas)
+	}
+
+	// jobs are waiting for a function scaled to zero
+	if replicas == 0 {
+		if (status.Queued > 0 || status.Running > 0) && maxReplicas > 0 {
+			return 1, "jobs waiting for the function scaled to zero"
+		}
+		return replicas, ""
+	}
+
+	sinceLastScale := now.Sub(status.LastScaleAt)
+
+	if replicas < maxReplicas && sinceLastScale >= msToDuration(policy.ScaleUpCooldown) {
+		if policy.ScaleUpQueueLength > 0 && uint(status.Queued) >= policy.ScaleUpQueueLength*replicas {
+			return replicas + 1, fmt.Sprintf("%d queued jobs with %d replicas", status.Queued, replicas)
+		}
+		if policy.ScaleUpLatency > 0 && status.Latency*1000 >= float64(policy.ScaleUpLatency) {
+			return replicas + 1, fmt.Sprintf("average latency of %.3fs", status.Latency)
+		}
+	}
+
+	if sinceLastScale < msToDuration(policy.ScaleDownCooldown) || status.Queued > 0 || status.ClusterLoadPartial {
+		return replicas, ""
+	}
+
+	idleTime := now.Sub(status.LastActivityAt)
+	if policy.MinReplicas == 0 && policy.IdleTimeToZero > 0 && status.Running == 0 && idleTime >= msToDuration(policy.IdleTimeToZero) {
+		return 0, fmt.Sprintf("idle for %s", idleTime.Round(time.Second))
+	}
+
+	minReplicas := policy.MinReplicas
+	if minReplicas < 1 {
+		minReplicas = 1
+	}
+	latencyHigh := policy.ScaleUpLatency > 0 && status.Latency*1000 >= float64(policy.ScaleUpLatency)
+	if replicas > minReplicas && status.Running < replicas && !latencyHigh {
+		return replicas - 1, fmt.Sprintf("%d running jobs with %d replicas", status.Running, replicas)
+	}
+
+	return replicas, ""
+}
+
+/*
+ * Utils
+ */
+
+func getFunctionStatus(functionName string, now time.Time) *FunctionStatus {
+	status, exists := functionsStatus[functionName]
+	if !exists {
+		// the scheduler start counts as activity, otherwise functions would be scaled to zero at the startup
+		status = &FunctionStatus{Name: functionName, LastActivityAt: now}
+		functionsStatus[functionName] = status
+	}
+	return status
+}
+
+func addDecision(decision Decision) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	decisions = append(decisions, decision)
+	if len(decisions) > DecisionsHistoryMax {
+		decisions = decisions[len(decisions)-DecisionsHistoryMax:]
+	}
+}
+
+// latencyWindow returns the time within which a job must have completed for the latency average to be considered, it
+// is the scale down cooldown but at least the interval of the autoscaler
+func latencyWindow(policy config.AutoscalerPolicy) time.Duration {
+	window := msToDuration(policy.ScaleDownCooldown)
+	if interval := msToDuration(config.GetAutoscalerInterval()); window < interval {
+		return interval
+	}
+	return window
+}
+
+func msToDuration(ms uint) time.Duration {
+	return time.Duration(ms) * time.Millisecond
+}
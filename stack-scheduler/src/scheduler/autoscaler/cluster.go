@@ -0,0 +1,77 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package autoscaler
+
+import (
+	"scheduler/log"
+	"scheduler/scheduler_service"
+	"scheduler/service_discovery"
+	"sync"
+)
+
+// clusterLoad is the load of the functions on the other schedulers, which use the same replicas when the functions of
+// the backend are shared
+type clusterLoad struct {
+	running map[string]uint
+	queued  map[string]int
+	// complete is false when the load of some scheduler cannot be read, then the functions are not scaled down since
+	// their replicas may be in use
+	complete bool
+}
+
+// getClusterLoad reads the load documents of all the other schedulers
+func getClusterLoad() clusterLoad {
+	load := clusterLoad{running: make(map[string]uint), queued: make(map[string]int), complete: true}
+
+	machines, err := service_discovery.GetCachedMachinesIpsList()
+	if err != nil {
+		log.Log.Errorf("Autoscaler cannot get machines for the cluster load: %s", err)
+		load.complete = false
+		return load
+	}
+
+	var mutex sync.Mutex
+	wg := sync.WaitGroup{}
+	for _, ip := range machines {
+		wg.Add(1)
+
+		ip := ip
+		go func() {
+			defer wg.Done()
+
+			machineLoad, _, err := scheduler_service.GetLoadDocument(ip)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			if err != nil {
+				log.Log.Debugf("Autoscaler cannot get load of machine %s: %s", ip, err)
+				load.complete = false
+				return
+			}
+			for name, functionLoad := range machineLoad.Functions {
+				load.running[name] += functionLoad.Running
+				load.queued[name] += int(functionLoad.Queued)
+			}
+		}()
+	}
+	wg.Wait()
+
+	return load
+}
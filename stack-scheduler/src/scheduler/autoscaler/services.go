@@ -0,0 +1,53 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package autoscaler
+
+import "sort"
+
+// IsStarted returns true if the autoscaling control loop is running
+func IsStarted() bool {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	return started
+}
+
+// GetDecisions returns the last scaling decisions, from the oldest
+func GetDecisions() []Decision {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	out := make([]Decision, len(decisions))
+	copy(out, decisions)
+	return out
+}
+
+// GetFunctionsStatus returns the state of all the functions evaluated by the autoscaler
+func GetFunctionsStatus() []FunctionStatus {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	out := make([]FunctionStatus, 0, len(functionsStatus))
+	for _, status := range functionsStatus {
+		out = append(out, *status)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+
+	return out
+}
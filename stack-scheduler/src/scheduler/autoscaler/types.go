@@ -0,0 +1,50 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package autoscaler
+
+import (
+	"scheduler/config"
+	"time"
+)
+
+// Decision is a scaling action taken by the autoscaler
+type Decision struct {
+	Function     string    `json:"function"`
+	Time         time.Time `json:"time"`
+	FromReplicas uint      `json:"from_replicas"`
+	ToReplicas   uint      `json:"to_replicas"`
+	Reason       string    `json:"reason"`
+	Error        string    `json:"error,omitempty"`
+}
+
+// FunctionStatus is the state of a function as it is seen by the autoscaler at its last evaluation. Latency is 0 when no
+// job completed within the latency window. When the functions are shared by all the schedulers, Queued and Running are
+// of all of them, and ClusterLoadPartial is set when the load of some scheduler cannot be read.
+type FunctionStatus struct {
+	Name               string                  `json:"name"`
+	Replicas           uint                    `json:"replicas"`
+	AvailableReplicas  uint                    `json:"available_replicas"`
+	Queued             int                     `json:"queued"`
+	Running            uint                    `json:"running"`
+	ClusterLoadPartial bool                    `json:"cluster_load_partial"`
+	Latency            float64                 `json:"latency"`
+	LastActivityAt     time.Time               `json:"last_activity_at"`
+	LastScaleAt        time.Time               `json:"last_scale_at"`
+	Policy             config.AutoscalerPolicy `json:"policy"`
+}
@@ -0,0 +1,184 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package circuit_breaker implements a circuit breaker for every function. When the executions of a function keep
+// failing or exceeding the latency threshold the breaker opens and the function is not executed anymore by this node,
+// which advertises it as unavailable to peers. After the open duration some trial executions are let through and the
+// breaker closes again if they succeed.
+package circuit_breaker
+
+import (
+	"scheduler/config"
+	"scheduler/log"
+	"sort"
+	"sync"
+	"time"
+)
+
+var breakers = map[string]*breaker{}
+var breakersMutex sync.Mutex
+
+// Allow tells if an execution of the function can be started, when the breaker is half-open it reserves one of the
+// trial executions
+func Allow(functionName string) bool {
+	policy, enabled := config.GetCircuitBreakerPolicy(functionName)
+	if !enabled {
+		return true
+	}
+
+	breakersMutex.Lock()
+	defer breakersMutex.Unlock()
+
+	b, exists := breakers[functionName]
+	if !exists {
+		return true
+	}
+
+	b.refreshState(policy, functionName)
+	switch b.state {
+	case StateOpen:
+		return false
+	case StateHalfOpen:
+		if b.halfOpenStarted >= halfOpenExecutions(policy) {
+			return false
+		}
+		b.halfOpenStarted++
+	}
+	return true
+}
+
+// IsOpen tells if the breaker of the function is open, in such a case its executions are rejected
+func IsOpen(functionName string) bool {
+	policy, enabled := config.GetCircuitBreakerPolicy(functionName)
+	if !enabled {
+		return false
+	}
+
+	breakersMutex.Lock()
+	defer breakersMutex.Unlock()
+
+	b, exists := breakers[functionName]
+	if !exists {
+		return false
+	}
+
+	b.refreshState(policy, functionName)
+	return b.state == StateOpen
+}
+
+// Record records the outcome of an execution of the function, an execution longer than the latency threshold is
+// counted as failed
+func Record(functionName string, failed bool, duration time.Duration) {
+	policy, enabled := config.GetCircuitBreakerPolicy(functionName)
+	if !enabled {
+		return
+	}
+
+	if policy.LatencyThreshold > 0 && duration > time.Duration(policy.LatencyThreshold)*time.Millisecond {
+		failed = true
+	}
+
+	breakersMutex.Lock()
+	defer breakersMutex.Unlock()
+
+	b, exists := breakers[functionName]
+	if !exists {
+		b = &breaker{state: StateClosed}
+		breakers[functionName] = b
+	}
+
+	b.refreshState(policy, functionName)
+	switch b.state {
+	case StateClosed:
+		b.record(policy, failed)
+		if b.count >= policy.MinExecutions && b.count > 0 && b.errorRate() >= policy.ErrorRateThreshold {
+			log.Log.Infof("Circuit breaker of %s opened: error rate %.2f in %d executions", functionName, b.errorRate(), b.count)
+			b.open()
+		}
+	case StateHalfOpen:
+		if failed {
+			log.Log.Infof("Circuit breaker of %s opened again: a trial execution failed", functionName)
+			b.open()
+			return
+		}
+		b.halfOpenSucceeded++
+		if b.halfOpenSucceeded >= halfOpenExecutions(policy) {
+			log.Log.Infof("Circuit breaker of %s closed", functionName)
+			b.close()
+		}
+	case StateOpen:
+		// the execution started before the breaker opened, so it is ignored
+	}
+}
+
+// Remove forgets the state of the breaker of the function, for example when the function is redeployed
+func Remove(functionName string) {
+	breakersMutex.Lock()
+	defer breakersMutex.Unlock()
+
+	delete(breakers, functionName)
+}
+
+// GetOpenFunctions returns the sorted names of the functions whose breaker is open
+func GetOpenFunctions() []string {
+	breakersMutex.Lock()
+	defer breakersMutex.Unlock()
+
+	out := []string{}
+	for functionName, b := range breakers {
+		policy, enabled := config.GetCircuitBreakerPolicy(functionName)
+		if !enabled {
+			continue
+		}
+		b.refreshState(policy, functionName)
+		if b.state == StateOpen {
+			out = append(out, functionName)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// GetFunctionsStatus returns the state of the breaker of every function which has been executed
+func GetFunctionsStatus() []FunctionStatus {
+	breakersMutex.Lock()
+	defer breakersMutex.Unlock()
+
+	out := []FunctionStatus{}
+	for functionName, b := range breakers {
+		policy, enabled := config.GetCircuitBreakerPolicy(functionName)
+		if !enabled {
+			continue
+		}
+		b.refreshState(policy, functionName)
+
+		status := FunctionStatus{
+			FunctionName: functionName,
+			State:        b.state,
+			Executions:   b.count,
+			ErrorRate:    b.errorRate(),
+		}
+		if b.state != StateClosed {
+			openedAt := b.openedAt
+			status.OpenedAt = &openedAt
+		}
+		out = append(out, status)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].FunctionName < out[j].FunctionName })
+	return out
+}
@@ -0,0 +1,151 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package circuit_breaker
+
+import (
+	"reflect"
+	"scheduler/config"
+	"testing"
+	"time"
+)
+
+// startTestBreaker sets the policy of fn and forgets its breaker, it opens after 2 failures out of 4 executions and it
+// needs 2 trial executions to close
+func startTestBreaker(t *testing.T, openDuration uint, latencyThreshold uint) {
+	config.SetCircuitBreakerPolicies(map[string]config.CircuitBreakerPolicy{"fn": {
+		ErrorRateThreshold: 0.5,
+		LatencyThreshold:   latencyThreshold,
+		WindowSize:         4,
+		MinExecutions:      4,
+		OpenDuration:       openDuration,
+		HalfOpenExecutions: 2,
+	}})
+	t.Cleanup(func() { config.SetCircuitBreakerPolicies(map[string]config.CircuitBreakerPolicy{}) })
+	Remove("fn")
+}
+
+func recordOutcomes(outcomes ...bool) {
+	for _, failed := range outcomes {
+		Record("fn", failed, time.Millisecond)
+	}
+}
+
+func expectState(t *testing.T, expected State) {
+	t.Helper()
+	for _, status := range GetFunctionsStatus() {
+		if status.FunctionName == "fn" {
+			if status.State != expected {
+				t.Fatalf("expected the breaker to be %s, it is %s", expected, status.State)
+			}
+			return
+		}
+	}
+	t.Fatalf("expected the breaker to be %s, it does not exist", expected)
+}
+
+func TestBreakerOpensAboveErrorRate(t *testing.T) {
+	startTestBreaker(t, 60000, 0)
+
+	// not enough executions in the window
+	recordOutcomes(true, true, false)
+	expectState(t, StateClosed)
+	if !Allow("fn") {
+		t.Fatalf("expected a closed breaker to allow executions")
+	}
+
+	recordOutcomes(false)
+	expectState(t, StateOpen)
+	if Allow("fn") || !IsOpen("fn") {
+		t.Fatalf("expected an open breaker to reject executions")
+	}
+	if !reflect.DeepEqual(GetOpenFunctions(), []string{"fn"}) {
+		t.Fatalf("expected fn to be advertised as open, got %v", GetOpenFunctions())
+	}
+}
+
+func TestBreakerStaysClosedBelowErrorRate(t *testing.T) {
+	startTestBreaker(t, 60000, 0)
+
+	// the window slides, so the old failures do not count anymore
+	recordOutcomes(true, false, false, false, true, false, false, false)
+	expectState(t, StateClosed)
+}
+
+func TestBreakerCountsSlowExecutionsAsFailed(t *testing.T) {
+	startTestBreaker(t, 60000, 10)
+
+	for i := 0; i < 4; i++ {
+		Record("fn", false, 20*time.Millisecond)
+	}
+	expectState(t, StateOpen)
+}
+
+func TestBreakerClosesAfterSuccessfulTrials(t *testing.T) {
+	startTestBreaker(t, 20, 0)
+
+	recordOutcomes(true, true, true, true)
+	expectState(t, StateOpen)
+
+	time.Sleep(40 * time.Millisecond)
+	expectState(t, StateHalfOpen)
+	if !Allow("fn") || !Allow("fn") {
+		t.Fatalf("expected a half-open breaker to allow the trial executions")
+	}
+	if Allow("fn") {
+		t.Fatalf("expected a half-open breaker to allow only the trial executions")
+	}
+
+	recordOutcomes(false)
+	expectState(t, StateHalfOpen)
+	recordOutcomes(false)
+	expectState(t, StateClosed)
+	if !Allow("fn") {
+		t.Fatalf("expected a closed breaker to allow executions")
+	}
+
+	// the window is reset when the breaker closes
+	recordOutcomes(true, true, true)
+	expectState(t, StateClosed)
+}
+
+func TestBreakerOpensAgainAfterFailedTrial(t *testing.T) {
+	startTestBreaker(t, 20, 0)
+
+	recordOutcomes(true, true, true, true)
+	time.Sleep(40 * time.Millisecond)
+	if !Allow("fn") {
+		t.Fatalf("expected a half-open breaker to allow a trial execution")
+	}
+
+	recordOutcomes(true)
+	expectState(t, StateOpen)
+	if Allow("fn") {
+		t.Fatalf("expected an open breaker to reject executions")
+	}
+}
+
+func TestBreakerDisabledWithoutPolicy(t *testing.T) {
+	startTestBreaker(t, 60000, 0)
+	config.SetCircuitBreakerPolicies(map[string]config.CircuitBreakerPolicy{})
+
+	recordOutcomes(true, true, true, true)
+	if !Allow("fn") || IsOpen("fn") {
+		t.Fatalf("expected a function without policy to be always allowed")
+	}
+}
@@ -0,0 +1,125 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package circuit_breaker
+
+import (
+	"scheduler/config"
+	"scheduler/log"
+	"time"
+)
+
+// State is the state of a circuit breaker
+type State string
+
+// StateClosed lets all the executions through
+const StateClosed State = "closed"
+
+// StateOpen rejects all the executions
+const StateOpen State = "open"
+
+// StateHalfOpen lets a limited number of trial executions through
+const StateHalfOpen State = "half-open"
+
+// FunctionStatus is the state of the breaker of a function
+type FunctionStatus struct {
+	FunctionName string     `json:"function_name"`
+	State        State      `json:"state"`
+	Executions   uint       `json:"executions"` // the executions in the window
+	ErrorRate    float64    `json:"error_rate"` // the rate of failed executions in the window
+	OpenedAt     *time.Time `json:"opened_at,omitempty"`
+}
+
+type breaker struct {
+	state State
+	// outcomes is the window of the last executions as a ring, true if the execution failed
+	outcomes []bool
+	next     uint
+	count    uint
+	failures uint
+
+	openedAt          time.Time
+	halfOpenStarted   uint
+	halfOpenSucceeded uint
+}
+
+// record adds the outcome of an execution to the window, resizing it if the policy changed
+func (b *breaker) record(policy config.CircuitBreakerPolicy, failed bool) {
+	if uint(len(b.outcomes)) != policy.WindowSize {
+		b.resetWindow(policy.WindowSize)
+	}
+
+	if b.count == uint(len(b.outcomes)) {
+		if b.outcomes[b.next] {
+			b.failures--
+		}
+	} else {
+		b.count++
+	}
+
+	b.outcomes[b.next] = failed
+	if failed {
+		b.failures++
+	}
+	b.next = (b.next + 1) % uint(len(b.outcomes))
+}
+
+func (b *breaker) errorRate() float64 {
+	if b.count == 0 {
+		return 0
+	}
+	return float64(b.failures) / float64(b.count)
+}
+
+// refreshState moves an open breaker to half-open when the open duration elapsed
+func (b *breaker) refreshState(policy config.CircuitBreakerPolicy, functionName string) {
+	if b.state != StateOpen || time.Since(b.openedAt) < time.Duration(policy.OpenDuration)*time.Millisecond {
+		return
+	}
+
+	log.Log.Infof("Circuit breaker of %s half-open", functionName)
+	b.state = StateHalfOpen
+	b.halfOpenStarted = 0
+	b.halfOpenSucceeded = 0
+}
+
+func (b *breaker) open() {
+	b.state = StateOpen
+	b.openedAt = time.Now()
+	b.resetWindow(uint(len(b.outcomes)))
+}
+
+func (b *breaker) close() {
+	b.state = StateClosed
+	b.resetWindow(uint(len(b.outcomes)))
+}
+
+// halfOpenExecutions returns the number of trial executions of the policy, at least one is always needed
+func halfOpenExecutions(policy config.CircuitBreakerPolicy) uint {
+	if policy.HalfOpenExecutions == 0 {
+		return 1
+	}
+	return policy.HalfOpenExecutions
+}
+
+func (b *breaker) resetWindow(size uint) {
+	b.outcomes = make([]bool, size)
+	b.next = 0
+	b.count = 0
+	b.failures = 0
+}
@@ -0,0 +1,126 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package cluster implements the operations on functions which involve all the nodes of the cluster, as the deploy of
+// a function on every alive node known by the service_discovery service. Nodes on which the deploy failed are retried
+// periodically.
+package cluster
+
+import (
+	"scheduler/config"
+	"scheduler/log"
+	"scheduler/service_discovery"
+	"strings"
+	"time"
+)
+
+// node is a machine of the cluster, the local one included
+type node struct {
+	ip    string
+	name  string
+	group string
+	local bool
+}
+
+// Start starts the retry of the failed deployments
+func Start() {
+	go retryLooper()
+}
+
+func retryLooper() {
+	for {
+		time.Sleep(time.Duration(config.GetClusterDeployRetryInterval()) * time.Millisecond)
+		retryFailedDeployments()
+	}
+}
+
+// listNodes returns the local node and all the alive machines that match the selector
+func listNodes(selector Selector) ([]node, error) {
+	machines, err := service_discovery.GetMachinesList()
+	if err != nil {
+		log.Log.Errorf("Cannot get machines from service_discovery service: %s", err)
+		return nil, err
+	}
+
+	localNode := node{
+		ip:    service_discovery.Configuration.MachineIp,
+		name:  service_discovery.Configuration.MachineId,
+		group: service_discovery.Configuration.MachineGroupName,
+		local: true,
+	}
+
+	var nodes []node
+	if selector.matches(localNode) {
+		nodes = append(nodes, localNode)
+	}
+
+	for _, machine := range machines {
+		if machine.IP == localNode.ip {
+			continue
+		}
+
+		n := node{ip: machine.IP, name: machine.Name, group: machine.GroupName}
+		if selector.matches(n) {
+			nodes = append(nodes, n)
+		}
+	}
+
+	return nodes, nil
+}
+
+// ParseSelector prepares the selector from the comma separated lists of groups and nodes
+func ParseSelector(groups string, nodes string) Selector {
+	return Selector{
+		Groups: splitList(groups),
+		Nodes:  splitList(nodes),
+	}
+}
+
+func (s Selector) matches(n node) bool {
+	if len(s.Groups) > 0 && !contains(s.Groups, n.group) {
+		return false
+	}
+	if len(s.Nodes) > 0 && !contains(s.Nodes, n.ip) && !contains(s.Nodes, n.name) {
+		return false
+	}
+	return true
+}
+
+/*
+ * Utils
+ */
+
+func splitList(list string) []string {
+	var out []string
+	for _, value := range strings.Split(list, ",") {
+		value = strings.TrimSpace(value)
+		if value != "" {
+			out = append(out, value)
+		}
+	}
+	return out
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
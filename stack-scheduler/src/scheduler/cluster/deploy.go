@@ -0,0 +1,210 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package cluster
+
+import (
+	"fmt"
+	"net/http"
+	"scheduler/config"
+	"scheduler/faas"
+	"scheduler/log"
+	"scheduler/scheduler_service"
+	"scheduler/types"
+	"scheduler/utils"
+	"sync"
+	"time"
+)
+
+// DeploymentsHistoryMax is the number of deployments which are kept in memory
+const DeploymentsHistoryMax = 50
+
+var deployments []*Deployment
+var deploymentsMutex sync.Mutex
+
+// Deploy deploys the function on all the nodes that match the selector and returns the outcome for every node
+func Deploy(function types.FaasFunction, selector Selector) (*Deployment, error) {
+	nodes, err := listNodes(selector)
+	if err != nil {
+		return nil, err
+	}
+	if len(nodes) == 0 {
+		return nil, ErrorNoNodeSelected{}
+	}
+
+	deployment := Deployment{
+		Id:        utils.GenerateUniqueId(),
+		Function:  function,
+		Selector:  selector,
+		CreatedAt: time.Now(),
+	}
+	for _, n := range nodes {
+		deployment.Nodes = append(deployment.Nodes, NodeResult{NodeIp: n.ip, NodeName: n.name, Local: n.local})
+	}
+
+	var wg sync.WaitGroup
+	for i := range deployment.Nodes {
+		wg.Add(1)
+		go func(result *NodeResult) {
+			defer wg.Done()
+			deployOnNode(function, result)
+		}(&deployment.Nodes[i])
+	}
+	wg.Wait()
+
+	log.Log.Infof("Deployed function %s on cluster: %d/%d nodes succeeded", function.Name,
+		deployment.SucceededNodes(), len(deployment.Nodes))
+
+	deploymentsMutex.Lock()
+	deployments = append(deployments, &deployment)
+	if len(deployments) > DeploymentsHistoryMax {
+		deployments = deployments[len(deployments)-DeploymentsHistoryMax:]
+	}
+	deploymentCopy := deployment.copy()
+	deploymentsMutex.Unlock()
+
+	return &deploymentCopy, nil
+}
+
+// GetDeployments returns the last deployments
+func GetDeployments() []Deployment {
+	deploymentsMutex.Lock()
+	defer deploymentsMutex.Unlock()
+
+	out := make([]Deployment, 0, len(deployments))
+	for _, deployment := range deployments {
+		out = append(out, deployment.copy())
+	}
+	return out
+}
+
+// GetDeployment returns the deployment with the given id
+func GetDeployment(id string) (*Deployment, error) {
+	deploymentsMutex.Lock()
+	defer deploymentsMutex.Unlock()
+
+	for _, deployment := range deployments {
+		if deployment.Id == id {
+			deploymentCopy := deployment.copy()
+			return &deploymentCopy, nil
+		}
+	}
+	return nil, ErrorDeploymentNotFound{id: id}
+}
+
+// SucceededNodes returns the number of nodes on which the function has been deployed
+func (d Deployment) SucceededNodes() int {
+	succeeded := 0
+	for _, result := range d.Nodes {
+		if result.Success {
+			succeeded++
+		}
+	}
+	return succeeded
+}
+
+func (d Deployment) copy() Deployment {
+	out := d
+	out.Nodes = append([]NodeResult{}, d.Nodes...)
+	return out
+}
+
+// retryFailedDeployments deploys again the functions on the nodes where the deploy failed, until the maximum number of
+// attempts is reached
+func retryFailedDeployments() {
+	type retry struct {
+		deployment *Deployment
+		index      int
+		result     NodeResult
+	}
+
+	maxAttempts := config.GetClusterDeployRetryMax() + 1
+
+	deploymentsMutex.Lock()
+	var retries []retry
+	for _, deployment := range deployments {
+		for i, result := range deployment.Nodes {
+			if !result.Success && result.Attempts < maxAttempts {
+				retries = append(retries, retry{deployment: deployment, index: i, result: result})
+			}
+		}
+	}
+	deploymentsMutex.Unlock()
+
+	if len(retries) == 0 {
+		return
+	}
+
+	var wg sync.WaitGroup
+	for i := range retries {
+		wg.Add(1)
+		go func(r *retry) {
+			defer wg.Done()
+			deployOnNode(r.deployment.Function, &r.result)
+			if r.result.Success {
+				log.Log.Infof("Deployed function %s on node %s after %d attempts", r.deployment.Function.Name,
+					r.result.NodeIp, r.result.Attempts)
+			} else if r.result.Attempts >= maxAttempts {
+				log.Log.Errorf("Giving up deploying function %s on node %s after %d attempts: %s",
+					r.deployment.Function.Name, r.result.NodeIp, r.result.Attempts, r.result.Error)
+			}
+		}(&retries[i])
+	}
+	wg.Wait()
+
+	deploymentsMutex.Lock()
+	for _, r := range retries {
+		r.deployment.Nodes[r.index] = r.result
+	}
+	deploymentsMutex.Unlock()
+}
+
+// deployOnNode makes an attempt of deploying the function on the node and records the outcome in result
+func deployOnNode(function types.FaasFunction, result *NodeResult) {
+	result.Attempts++
+	result.LastAttemptAt = time.Now()
+	result.Success = false
+	result.StatusCode = 0
+	result.Error = ""
+
+	if result.Local {
+		res, err := faas.FunctionDeploy(function)
+		if res != nil {
+			result.StatusCode = res.StatusCode
+		}
+		if err != nil {
+			result.Error = err.Error()
+			return
+		}
+	} else {
+		res, err := scheduler_service.DeployFunction(result.NodeIp, function)
+		if res != nil {
+			result.StatusCode = res.StatusCode
+		}
+		if err != nil {
+			result.Error = err.Error()
+			return
+		}
+	}
+
+	if result.StatusCode != 0 && (result.StatusCode < http.StatusOK || result.StatusCode >= http.StatusMultipleChoices) {
+		result.Error = fmt.Sprintf("Unexpected status code %d", result.StatusCode)
+		return
+	}
+	result.Success = true
+}
@@ -0,0 +1,35 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package cluster
+
+import "fmt"
+
+type ErrorNoNodeSelected struct{}
+
+func (ErrorNoNodeSelected) Error() string {
+	return "No node matches the selector"
+}
+
+type ErrorDeploymentNotFound struct {
+	id string
+}
+
+func (e ErrorDeploymentNotFound) Error() string {
+	return fmt.Sprintf("Deployment %s not found", e.id)
+}
@@ -0,0 +1,90 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package cluster
+
+import (
+	"scheduler/faas"
+	"scheduler/log"
+	"scheduler/scheduler_service"
+	"scheduler/types"
+	"sort"
+	"sync"
+)
+
+// GetFunctions returns the functions deployed in the cluster together with the nodes which host them. Nodes that
+// cannot be reached are skipped.
+func GetFunctions() ([]Function, error) {
+	nodes, err := listNodes(Selector{})
+	if err != nil {
+		return nil, err
+	}
+
+	nodesFunctions := make([][]types.FaasFunction, len(nodes))
+
+	var wg sync.WaitGroup
+	for i := range nodes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			nodesFunctions[i] = getNodeFunctions(nodes[i])
+		}(i)
+	}
+	wg.Wait()
+
+	functionsMap := make(map[string]*Function)
+	for i, n := range nodes {
+		for _, f := range nodesFunctions[i] {
+			function, ok := functionsMap[f.Name]
+			if !ok {
+				function = &Function{Name: f.Name, Image: f.Image}
+				functionsMap[f.Name] = function
+			}
+			function.Nodes = append(function.Nodes, FunctionNode{
+				NodeIp:            n.ip,
+				NodeName:          n.name,
+				Replicas:          f.Replicas,
+				AvailableReplicas: f.AvailableReplicas,
+			})
+		}
+	}
+
+	functions := make([]Function, 0, len(functionsMap))
+	for _, function := range functionsMap {
+		functions = append(functions, *function)
+	}
+	sort.Slice(functions, func(i, j int) bool { return functions[i].Name < functions[j].Name })
+
+	return functions, nil
+}
+
+func getNodeFunctions(n node) []types.FaasFunction {
+	if n.local {
+		functions, _, err := faas.FunctionsGet()
+		if err != nil {
+			log.Log.Errorf("Cannot get functions from faas backend: %s", err)
+		}
+		return functions
+	}
+
+	functions, err := scheduler_service.GetFunctions(n.ip)
+	if err != nil {
+		log.Log.Warningf("Cannot get functions from node %s: %s", n.ip, err)
+	}
+	return functions
+}
@@ -0,0 +1,67 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package cluster
+
+import (
+	"scheduler/types"
+	"time"
+)
+
+// Selector restricts the nodes involved in an operation, an empty selector selects all the nodes
+type Selector struct {
+	// Groups are the group names of the nodes
+	Groups []string `json:"groups,omitempty"`
+	// Nodes are the ips or the names of the nodes
+	Nodes []string `json:"nodes,omitempty"`
+}
+
+// NodeResult is the outcome of a deployment on a node
+type NodeResult struct {
+	NodeIp        string    `json:"node_ip"`
+	NodeName      string    `json:"node_name"`
+	Local         bool      `json:"local"`
+	Success       bool      `json:"success"`
+	StatusCode    int       `json:"status_code,omitempty"`
+	Error         string    `json:"error,omitempty"`
+	Attempts      uint      `json:"attempts"`
+	LastAttemptAt time.Time `json:"last_attempt_at"`
+}
+
+// Deployment is the deploy of a function on the nodes of the cluster
+type Deployment struct {
+	Id        string             `json:"id"`
+	Function  types.FaasFunction `json:"function"`
+	Selector  Selector           `json:"selector"`
+	CreatedAt time.Time          `json:"created_at"`
+	Nodes     []NodeResult       `json:"nodes"`
+}
+
+// Function is a function deployed in the cluster, with the nodes which host it
+type Function struct {
+	Name  string         `json:"name"`
+	Image string         `json:"image,omitempty"`
+	Nodes []FunctionNode `json:"nodes"`
+}
+
+type FunctionNode struct {
+	NodeIp            string `json:"node_ip"`
+	NodeName          string `json:"node_name"`
+	Replicas          uint   `json:"replicas"`
+	AvailableReplicas uint   `json:"available_replicas"`
+}
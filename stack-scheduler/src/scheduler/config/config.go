@@ -34,6 +34,7 @@ const DataPath = "/data"
 // const ConfigurationFilePath = "/config"
 const ConfigurationFileName = "p2p_faas-scheduler.json"
 const ConfigurationSchedulerFileName = "p2p_faas-scheduler-config.json"
+const ProcessManifestFileName = "p2p_faas-process-manifest.json"
 
 // const ConfigurationFileFullPath = ConfigurationFilePath + "/" + ConfigurationFileName
 // const SchedulerConfigurationFullPath = ConfigurationFilePath + "/" + SchedulerConfigurationFileName
@@ -41,6 +42,7 @@ const ConfigurationSchedulerFileName = "p2p_faas-scheduler-config.json"
 const DefaultListeningPort = 18080
 const DefaultQueueLengthMax = 100
 const DefaultFunctionsRunningMax = 10
+const DefaultFunctionTimeout = 30000 // ms
 const DefaultRunningEnvironment = RunningEnvironmentProduction
 
 // env
@@ -49,11 +51,51 @@ const EnvServiceDiscoveryListeningHost = "P2PFAAS_SERVICE_DISCOVERY_HOST"
 const EnvServiceDiscoveryListeningPort = "P2PFAAS_SERVICE_DISCOVERY_PORT"
 const EnvServiceLearningListeningHost = "P2PFAAS_SERVICE_LEARNING_HOST"
 const EnvServiceLearningListeningPort = "P2PFAAS_SERVICE_LEARNING_PORT"
+const EnvFaasBackend = "P2PFAAS_FAAS_BACKEND"
+const EnvProcessManifestPath = "P2PFAAS_PROCESS_MANIFEST_PATH"
+const EnvKubernetesApiUrl = "P2PFAAS_KUBERNETES_API_URL"
+const EnvKubernetesNamespace = "P2PFAAS_KUBERNETES_NAMESPACE"
+const EnvKubernetesNodeName = "P2PFAAS_KUBERNETES_NODE_NAME"
+const EnvKubernetesNodeLocal = "P2PFAAS_KUBERNETES_NODE_LOCAL"
 const EnvOpenFaasEnabled = "P2PFAAS_OPENFAAS_ENABLED"
 const EnvOpenFaasListeningHost = "P2PFAAS_OPENFAAS_HOST"
 const EnvOpenFaasListeningPort = "P2PFAAS_OPENFAAS_PORT"
 const EnvFunctionsList = "P2PFAAS_FNS_LIST"
 const EnvDataPath = "P2PFAAS_DATA_PATH"
+const EnvJobLogEnabled = "P2PFAAS_JOB_LOG_ENABLED"
+const EnvJobLogSyncMode = "P2PFAAS_JOB_LOG_SYNC_MODE"
+const EnvJobLogSyncInterval = "P2PFAAS_JOB_LOG_SYNC_INTERVAL_MS"
+const EnvJobLogSegmentMaxSize = "P2PFAAS_JOB_LOG_SEGMENT_MAX_BYTES"
+const EnvAutoscalerEnabled = "P2PFAAS_AUTOSCALER_ENABLED"
+const EnvAutoscalerInterval = "P2PFAAS_AUTOSCALER_INTERVAL_MS"
+const EnvClusterDeployRetryInterval = "P2PFAAS_CLUSTER_DEPLOY_RETRY_INTERVAL_MS"
+const EnvClusterDeployRetryMax = "P2PFAAS_CLUSTER_DEPLOY_RETRY_MAX"
+const EnvFunctionsAdvertisementInterval = "P2PFAAS_FUNCTIONS_ADVERTISEMENT_INTERVAL_MS"
+const EnvStreamingEnabled = "P2PFAAS_STREAMING_ENABLED"
+const EnvPayloadMaxSize = "P2PFAAS_PAYLOAD_MAX_BYTES"
+const EnvResponseMaxSize = "P2PFAAS_RESPONSE_MAX_BYTES"
+const EnvCacheMaxSize = "P2PFAAS_CACHE_MAX_BYTES"
+const EnvPeerKeepAlive = "P2PFAAS_PEER_KEEP_ALIVE"
+const EnvPeerHttp2Enabled = "P2PFAAS_PEER_HTTP2_ENABLED"
+const EnvPeerMaxIdleConnsPerHost = "P2PFAAS_PEER_MAX_IDLE_CONNS_PER_HOST"
+const EnvPeerIdleConnTimeout = "P2PFAAS_PEER_IDLE_CONN_TIMEOUT_MS"
+const EnvPeerTransport = "P2PFAAS_PEER_TRANSPORT"
+const EnvPeerMinProtocolVersion = "P2PFAAS_PEER_MIN_PROTOCOL_VERSION"
+const EnvPeerRequiredCapabilities = "P2PFAAS_PEER_REQUIRED_CAPABILITIES"
+const EnvLinkThroughputDefault = "P2PFAAS_LINK_THROUGHPUT_DEFAULT_BPS"
+const EnvGrpcListeningPort = "P2PFAAS_GRPC_PORT"
+const EnvGossipEnabled = "P2PFAAS_GOSSIP_ENABLED"
+const EnvGossipPort = "P2PFAAS_GOSSIP_PORT"
+const EnvGossipFanout = "P2PFAAS_GOSSIP_FANOUT"
+const EnvGossipInterval = "P2PFAAS_GOSSIP_INTERVAL_MS"
+const EnvGossipStaleness = "P2PFAAS_GOSSIP_STALENESS_MS"
+const EnvTlsEnabled = "P2PFAAS_TLS_ENABLED"
+const EnvTlsCaPath = "P2PFAAS_TLS_CA_PATH"
+const EnvTlsCertPath = "P2PFAAS_TLS_CERT_PATH"
+const EnvTlsKeyPath = "P2PFAAS_TLS_KEY_PATH"
+const EnvTlsReloadInterval = "P2PFAAS_TLS_RELOAD_INTERVAL_MS"
+const EnvClusterKeys = "P2PFAAS_CLUSTER_KEYS"
+const EnvSignatureWindow = "P2PFAAS_SIGNATURE_WINDOW_MS"
 
 const EnvProfiling = "P2PFAAS_PROF"
 
@@ -66,11 +108,107 @@ const DefaultServiceLearningListeningPort = 19020
 const RunningEnvironmentProduction = "production"
 const RunningEnvironmentDevelopment = "development"
 
+// FaasBackendOpenFaas is the name of the faas backend which uses OpenFaaS
+const FaasBackendOpenFaas = "openfaas"
+
+// FaasBackendContainers is the name of the faas backend which uses plain containers
+const FaasBackendContainers = "containers"
+
+// FaasBackendProcess is the name of the faas backend which runs functions as local processes
+const FaasBackendProcess = "process"
+
+// FaasBackendKubernetes is the name of the faas backend which runs functions as Kubernetes deployments
+const FaasBackendKubernetes = "kubernetes"
+
+const DefaultKubernetesApiUrl = "https://kubernetes.default.svc"
+const DefaultKubernetesNamespace = "default"
+
+// KubernetesServiceAccountPath is where Kubernetes mounts the token, the ca and the namespace of the service account
+const KubernetesServiceAccountPath = "/var/run/secrets/kubernetes.io/serviceaccount"
+
 const DefaultOpenFaaSListeningHost = "faas_containers-openfaas-swarm"
 const DefaultOpenFaaSListeningPort = 8080
 
 const DefaultDataPath = "/data"
 
+const JobLogDirName = "joblog"
+
+// JobLogSyncModeAlways syncs the job log to disk after every record
+const JobLogSyncModeAlways = "always"
+
+// JobLogSyncModeInterval syncs the job log to disk periodically
+const JobLogSyncModeInterval = "interval"
+
+// JobLogSyncModeNever leaves to the operating system when to flush the job log to disk
+const JobLogSyncModeNever = "never"
+
+const DefaultJobLogSyncMode = JobLogSyncModeInterval
+const DefaultJobLogSyncInterval = 1000              // ms
+const DefaultJobLogSegmentMaxSize = 8 * 1024 * 1024 // bytes
+
+const DefaultAutoscalerInterval = 5000 // ms
+
+const DefaultClusterDeployRetryInterval = 30000 // ms
+const DefaultClusterDeployRetryMax = 10
+
+const DefaultFunctionsAdvertisementInterval = 5000 // ms
+
+const DefaultPayloadMaxSize = 64 * 1024 * 1024  // bytes
+const DefaultResponseMaxSize = 64 * 1024 * 1024 // bytes
+
+const DefaultCacheMaxSize = 32 * 1024 * 1024 // bytes
+
+const DefaultPeerMaxIdleConnsPerHost = 4
+const DefaultPeerIdleConnTimeout = 90000 // ms
+
+// PeerTransportHttp sends probes and jobs to peers with the http peer api
+const PeerTransportHttp = "http"
+
+// PeerTransportGrpc sends probes and jobs to peers with the grpc peer service, peers which do not advertise it are
+// reached with the http peer api
+const PeerTransportGrpc = "grpc"
+
+const DefaultGrpcListeningPort = 18081
+
+const DefaultGossipPort = 18082
+const DefaultGossipFanout = 3
+const DefaultGossipInterval = 1000 // ms
+
+// DefaultGossipStaleness is the age after which the load gossiped by a node is dropped from the view
+const DefaultGossipStaleness = 10000 // ms
+
+// DefaultLinkThroughput is the throughput of the links to the peers before it is measured by the forwards
+const DefaultLinkThroughput = 12500000 // bytes/s
+
+// DefaultPeerMinProtocolVersion accepts also the peers which do not advertise their protocol version
+const DefaultPeerMinProtocolVersion = 0
+
+// DefaultTlsCaPath is the certificate of the cluster CA, which signs the certificates of all the services
+const DefaultTlsCaPath = "/tls/ca.crt"
+const DefaultTlsCertPath = "/tls/tls.crt"
+const DefaultTlsKeyPath = "/tls/tls.key"
+const DefaultTlsReloadInterval = 30000 // ms
+
+// DefaultSignatureWindow is the maximum age of a signed request, older requests are rejected as replays
+const DefaultSignatureWindow = 30000 // ms
+
+// RetryTargetLocal retries a failed job on the node which executed it, while it keeps its execution slot
+const RetryTargetLocal = "local"
+
+// RetryTargetPeer retries a failed job on another peer which hosts the function
+const RetryTargetPeer = "peer"
+
+// RetryErrorTimeout is the class of the executions which exceeded the function timeout, they are retried only with the
+// peer target
+const RetryErrorTimeout = "timeout"
+
+// RetryErrorConnection is the class of the executions which failed since the function or the peer was not reachable
+const RetryErrorConnection = "connection"
+
+// RetryErrorCircuitOpen is the class of the executions which were not started since the circuit breaker of the
+// function was open
+const RetryErrorCircuitOpen = "circuit_open"
+
 const UserAgentMachine = "Machine"
 
 /*
@@ -94,7 +232,7 @@ func init() {
 	// if os.Getenv(EnvDevelopmentEnvironment) == Configuration.GetRunningEnvironment() {
 	log.Log.Infof("Starting in %s environment", GetRunningEnvironment())
 
-	if GetOpenFaasEnabled() {
+	if GetFaasBackend() == FaasBackendOpenFaas {
 		username, _ := ioutil.ReadFile("/run/secrets/basic-auth-user")
 		OpenFaaSUsername = strings.TrimSpace(string(username))
 		password, _ := ioutil.ReadFile("/run/secrets/basic-auth-password")
@@ -104,6 +242,7 @@ func init() {
 	// }
 
 	// log.Log.Debug("Init with user %s and password %s", OpenFaaSUsername, OpenFaaSPassword)
+	log.Log.Infof("Init with faas backend %s", GetFaasBackend())
 	log.Log.Infof("Init with RunningFunctionsMax %d, QueueMaxLength %d, QueueEnabled %v",
 		GetRunningFunctionMax(), GetQueueLengthMax(), GetQueueEnabled())
 	// log.Log.Infof("Init with functions=%v", GetFunctionsList())
@@ -24,6 +24,7 @@ import (
 	"scheduler/log"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type ConfigError struct{}
@@ -42,17 +43,173 @@ type ConfigurationStatic struct {
 	profilingEnabled              bool
 	dataPath                      string
 
+	faasBackend         string
+	processManifestPath string
+
+	kubernetesApiUrl    string
+	kubernetesNamespace string
+	kubernetesNodeName  string
+	kubernetesNodeLocal bool
+
 	openFaasEnabled       bool
 	openFaasListeningPort uint
 	openFaasListeningHost string
 
 	fnList []string
+
+	jobLogEnabled        bool
+	jobLogSyncMode       string
+	jobLogSyncInterval   uint
+	jobLogSegmentMaxSize uint
+
+	autoscalerEnabled  bool
+	autoscalerInterval uint
+
+	clusterDeployRetryInterval uint
+	clusterDeployRetryMax      uint
+
+	advertisementInterval uint
+
+	streamingEnabled bool
+	payloadMaxSize   uint
+	responseMaxSize  uint
+
+	cacheMaxSize uint
+
+	peerKeepAlive           bool
+	peerHttp2Enabled        bool
+	peerMaxIdleConnsPerHost uint
+	peerIdleConnTimeout     uint
+	peerTransport           string
+	grpcListeningPort       uint
+
+	peerMinProtocolVersion   uint
+	peerRequiredCapabilities []string
+	linkThroughputDefault    uint
+
+	gossipEnabled   bool
+	gossipPort      uint
+	gossipFanout    uint
+	gossipInterval  uint
+	gossipStaleness uint
+
+	tlsEnabled        bool
+	tlsCaPath         string
+	tlsCertPath       string
+	tlsKeyPath        string
+	tlsReloadInterval uint
+
+	clusterKeys     []ClusterKey
+	signatureWindow uint
+}
+
+// ClusterKey is a secret shared by all the nodes of the cluster for signing the requests between them
+type ClusterKey struct {
+	Id     string
+	Secret string
 }
 
 type ConfigurationDynamic struct {
 	ParallelRunningFunctionsMax uint `json:"parallel_running_functions_max" bson:"parallel_running_functions_max"`
 	QueueLengthMax              uint `json:"queue_length_max" bson:"queue_length_max"`
 	QueueEnabled                bool `json:"queue_enabled" bson:"queue_enabled"`
+	// FunctionTimeout is the default execution timeout of functions in ms, 0 disables it
+	FunctionTimeout uint `json:"function_timeout" bson:"function_timeout"`
+	// FunctionTimeouts overrides the execution timeout in ms of single functions
+	FunctionTimeouts map[string]uint `json:"function_timeouts" bson:"function_timeouts"`
+	// AutoscalerPolicy is the autoscaling policy of the functions without an own one
+	AutoscalerPolicy AutoscalerPolicy `json:"autoscaler_policy" bson:"autoscaler_policy"`
+	// AutoscalerPolicies are the autoscaling policies of single functions
+	AutoscalerPolicies map[string]AutoscalerPolicy `json:"autoscaler_policies" bson:"autoscaler_policies"`
+	// FunctionCaches enables the memoization of the results of single functions, which must be deterministic
+	FunctionCaches map[string]CachePolicy `json:"function_caches" bson:"function_caches"`
+	// RetryPolicy is the retry policy of the functions without an own one
+	RetryPolicy RetryPolicy `json:"retry_policy" bson:"retry_policy"`
+	// RetryPolicies are the retry policies of single functions
+	RetryPolicies map[string]RetryPolicy `json:"retry_policies" bson:"retry_policies"`
+	// CircuitBreakerPolicy is the circuit breaker policy of the functions without an own one
+	CircuitBreakerPolicy CircuitBreakerPolicy `json:"circuit_breaker_policy" bson:"circuit_breaker_policy"`
+	// CircuitBreakerPolicies are the circuit breaker policies of single functions
+	CircuitBreakerPolicies map[string]CircuitBreakerPolicy `json:"circuit_breaker_policies" bson:"circuit_breaker_policies"`
+	// Draining is advertised to peers in the load, which stop sending jobs to the node
+	Draining bool `json:"draining" bson:"draining"`
+	// OutlierEjectionPolicy defines when peers are excluded from the selection
+	OutlierEjectionPolicy OutlierEjectionPolicy `json:"outlier_ejection_policy" bson:"outlier_ejection_policy"`
+}
+
+// CachePolicy defines how the results of a function are memoized, times are in ms
+type CachePolicy struct {
+	// TTL is the time after which a cached result expires
+	TTL uint `json:"ttl" bson:"ttl"`
+}
+
+// AutoscalerPolicy defines how the replicas of a function are scaled, times are in ms
+type AutoscalerPolicy struct {
+	MinReplicas uint `json:"min_replicas" bson:"min_replicas"`
+	MaxReplicas uint `json:"max_replicas" bson:"max_replicas"`
+	// ScaleUpQueueLength is the number of queued jobs per replica above which the function is scaled up
+	ScaleUpQueueLength uint `json:"scale_up_queue_length" bson:"scale_up_queue_length"`
+	// ScaleUpLatency is the average latency above which the function is scaled up, 0 disables it
+	ScaleUpLatency    uint `json:"scale_up_latency" bson:"scale_up_latency"`
+	ScaleUpCooldown   uint `json:"scale_up_cooldown" bson:"scale_up_cooldown"`
+	ScaleDownCooldown uint `json:"scale_down_cooldown" bson:"scale_down_cooldown"`
+	// IdleTimeToZero is the time without executions after which the function is scaled to zero, only if MinReplicas is
+	// 0. The value 0 disables the scale to zero.
+	IdleTimeToZero uint `json:"idle_time_to_zero" bson:"idle_time_to_zero"`
+}
+
+// RetryPolicy defines how the failed executions of a function are retried, times are in ms
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of executions of a job, comprising the first one. Values below 2 disable retries.
+	MaxAttempts uint `json:"max_attempts" bson:"max_attempts"`
+	// Backoff is the time waited before the first retry, it is multiplied by BackoffMultiplier at every retry
+	Backoff           uint    `json:"backoff" bson:"backoff"`
+	BackoffMultiplier float64 `json:"backoff_multiplier" bson:"backoff_multiplier"`
+	BackoffMax        uint    `json:"backoff_max" bson:"backoff_max"`
+	// RetryableStatusCodes are the status codes of the function which are retried
+	RetryableStatusCodes []int `json:"retryable_status_codes" bson:"retryable_status_codes"`
+	// RetryableErrors are the classes of the errors without a status code which are retried, see RetryErrorTimeout and
+	// RetryErrorConnection
+	RetryableErrors []string `json:"retryable_errors" bson:"retryable_errors"`
+	// Target tells where a job is retried, see RetryTargetLocal and RetryTargetPeer
+	Target string `json:"target" bson:"target"`
+}
+
+// CircuitBreakerPolicy defines when the executions of a function are stopped since they keep failing, times are in ms
+type CircuitBreakerPolicy struct {
+	// ErrorRateThreshold is the rate of failed executions in the window above which the breaker opens, 0 disables it
+	ErrorRateThreshold float64 `json:"error_rate_threshold" bson:"error_rate_threshold"`
+	// LatencyThreshold is the duration above which an execution is counted as failed, 0 disables it
+	LatencyThreshold uint `json:"latency_threshold" bson:"latency_threshold"`
+	// WindowSize is the number of the last executions on which the error rate is computed
+	WindowSize uint `json:"window_size" bson:"window_size"`
+	// MinExecutions is the number of executions in the window needed before the breaker can open
+	MinExecutions uint `json:"min_executions" bson:"min_executions"`
+	// OpenDuration is the time the breaker stays open before letting trial executions pass
+	OpenDuration uint `json:"open_duration" bson:"open_duration"`
+	// HalfOpenExecutions is the number of trial executions which must succeed for closing the breaker
+	HalfOpenExecutions uint `json:"half_open_executions" bson:"half_open_executions"`
+}
+
+// OutlierEjectionPolicy defines when peers are excluded from the selection since they keep failing or they are much
+// slower than the others, times are in ms
+type OutlierEjectionPolicy struct {
+	// ConsecutiveFailures is the number of consecutive failed probes or forwards after which a peer is ejected, 0
+	// disables it
+	ConsecutiveFailures uint `json:"consecutive_failures" bson:"consecutive_failures"`
+	// LatencyFactor ejects a peer whose probe latency is above LatencyFactor times the median one of the peers, 0
+	// disables it
+	LatencyFactor float64 `json:"latency_factor" bson:"latency_factor"`
+	// LatencyMin is the probe latency under which a peer is never an outlier
+	LatencyMin uint `json:"latency_min" bson:"latency_min"`
+	// LatencyMinPeers is the number of peers with a measured latency needed for detecting the outliers
+	LatencyMinPeers uint `json:"latency_min_peers" bson:"latency_min_peers"`
+	// EjectionTime is the duration of the first ejection of a peer, it is doubled at every following ejection up to
+	// EjectionTimeMax. A peer which is not ejected for EjectionTimeMax starts again from EjectionTime.
+	EjectionTime    uint `json:"ejection_time" bson:"ejection_time"`
+	EjectionTimeMax uint `json:"ejection_time_max" bson:"ejection_time_max"`
+	// MaxEjectedPercent is the maximum percentage of the known peers which can be ejected at the same time
+	MaxEjectedPercent uint `json:"max_ejected_percent" bson:"max_ejected_percent"`
 }
 
 /*
@@ -72,12 +229,112 @@ func GetQueueLengthMax() uint {
 func GetQueueEnabled() bool {
 	return configurationDynamic.QueueEnabled
 }
+func GetDraining() bool {
+	return configurationDynamic.Draining
+}
+func GetFunctionTimeout() uint {
+	return configurationDynamic.FunctionTimeout
+}
+
+// GetAutoscalerPolicy returns the autoscaling policy of the passed function
+func GetAutoscalerPolicy(functionName string) AutoscalerPolicy {
+	if policy, exists := configurationDynamic.AutoscalerPolicies[functionName]; exists {
+		return policy
+	}
+	return configurationDynamic.AutoscalerPolicy
+}
+
+// GetRetryPolicy returns the retry policy of the passed function, false if its failed executions are not retried
+func GetRetryPolicy(functionName string) (RetryPolicy, bool) {
+	policy, exists := configurationDynamic.RetryPolicies[functionName]
+	if !exists {
+		policy = configurationDynamic.RetryPolicy
+	}
+	return policy, policy.MaxAttempts > 1
+}
+
+// GetCircuitBreakerPolicy returns the circuit breaker policy of the passed function, false if it has no breaker
+func GetCircuitBreakerPolicy(functionName string) (CircuitBreakerPolicy, bool) {
+	policy, exists := configurationDynamic.CircuitBreakerPolicies[functionName]
+	if !exists {
+		policy = configurationDynamic.CircuitBreakerPolicy
+	}
+	return policy, policy.ErrorRateThreshold > 0 && policy.WindowSize > 0
+}
+
+// GetOutlierEjectionPolicy returns the outlier ejection policy of the peers, false if peers are never ejected
+func GetOutlierEjectionPolicy() (OutlierEjectionPolicy, bool) {
+	policy := configurationDynamic.OutlierEjectionPolicy
+	return policy, (policy.ConsecutiveFailures > 0 || policy.LatencyFactor > 0) && policy.EjectionTime > 0
+}
+
+// GetCachePolicy returns the memoization policy of the passed function, false if its results must not be cached
+func GetCachePolicy(functionName string) (CachePolicy, bool) {
+	policy, exists := configurationDynamic.FunctionCaches[functionName]
+	return policy, exists && policy.TTL > 0
+}
+
+// GetFunctionTimeoutOverride returns the execution timeout set in the configuration for the passed function, if any
+func GetFunctionTimeoutOverride(functionName string) (uint, bool) {
+	timeout, exists := configurationDynamic.FunctionTimeouts[functionName]
+	return timeout, exists
+}
+
+// GetFunctionTimeoutOf returns the execution timeout of the passed function set in the configuration, its override or
+// the default one, 0 means no timeout
+func GetFunctionTimeoutOf(functionName string) time.Duration {
+	if timeout, exists := GetFunctionTimeoutOverride(functionName); exists {
+		return time.Duration(timeout) * time.Millisecond
+	}
+	return time.Duration(GetFunctionTimeout()) * time.Millisecond
+}
+
 func GetListeningPort() uint {
 	return configurationStatic.listeningPort
 }
 func GetDataPath() string {
 	return configurationStatic.dataPath
 }
+
+// GetFaasBackend returns the name of the faas backend to use. If not set, OpenFaaS is used when enabled, otherwise
+// plain containers.
+func GetFaasBackend() string {
+	if configurationStatic.faasBackend != "" {
+		return configurationStatic.faasBackend
+	}
+	if configurationStatic.openFaasEnabled {
+		return FaasBackendOpenFaas
+	}
+	return FaasBackendContainers
+}
+func GetProcessManifestPath() string {
+	if configurationStatic.processManifestPath != "" {
+		return configurationStatic.processManifestPath
+	}
+	return GetDataPath() + "/" + ProcessManifestFileName
+}
+func GetKubernetesApiUrl() string {
+	return configurationStatic.kubernetesApiUrl
+}
+
+// GetKubernetesNamespace returns the namespace of the functions. If not set, the namespace of the service account of
+// the scheduler is used.
+func GetKubernetesNamespace() string {
+	if configurationStatic.kubernetesNamespace != "" {
+		return configurationStatic.kubernetesNamespace
+	}
+	namespace, err := os.ReadFile(KubernetesServiceAccountPath + "/namespace")
+	if err == nil && strings.TrimSpace(string(namespace)) != "" {
+		return strings.TrimSpace(string(namespace))
+	}
+	return DefaultKubernetesNamespace
+}
+func GetKubernetesNodeName() string {
+	return configurationStatic.kubernetesNodeName
+}
+func GetKubernetesNodeLocal() bool {
+	return configurationStatic.kubernetesNodeLocal
+}
 func GetOpenFaasEnabled() bool {
 	return configurationStatic.openFaasEnabled
 }
@@ -110,6 +367,109 @@ func IsRunningEnvironmentDevelopment() bool {
 	return configurationStatic.runningEnvironment == RunningEnvironmentDevelopment
 }
 
+func GetJobLogEnabled() bool {
+	return configurationStatic.jobLogEnabled
+}
+func GetJobLogSyncMode() string {
+	return configurationStatic.jobLogSyncMode
+}
+func GetJobLogSyncInterval() uint {
+	return configurationStatic.jobLogSyncInterval
+}
+func GetJobLogSegmentMaxSize() uint {
+	return configurationStatic.jobLogSegmentMaxSize
+}
+func GetAutoscalerEnabled() bool {
+	return configurationStatic.autoscalerEnabled
+}
+func GetAutoscalerInterval() uint {
+	return configurationStatic.autoscalerInterval
+}
+func GetClusterDeployRetryInterval() uint {
+	return configurationStatic.clusterDeployRetryInterval
+}
+func GetClusterDeployRetryMax() uint {
+	return configurationStatic.clusterDeployRetryMax
+}
+func GetFunctionsAdvertisementInterval() uint {
+	return configurationStatic.advertisementInterval
+}
+func GetStreamingEnabled() bool {
+	return configurationStatic.streamingEnabled
+}
+func GetPayloadMaxSize() uint {
+	return configurationStatic.payloadMaxSize
+}
+func GetResponseMaxSize() uint {
+	return configurationStatic.responseMaxSize
+}
+func GetCacheMaxSize() uint {
+	return configurationStatic.cacheMaxSize
+}
+func GetPeerKeepAlive() bool {
+	return configurationStatic.peerKeepAlive
+}
+func GetPeerHttp2Enabled() bool {
+	return configurationStatic.peerHttp2Enabled
+}
+func GetPeerMaxIdleConnsPerHost() uint {
+	return configurationStatic.peerMaxIdleConnsPerHost
+}
+func GetPeerIdleConnTimeout() uint {
+	return configurationStatic.peerIdleConnTimeout
+}
+func GetPeerTransport() string {
+	return configurationStatic.peerTransport
+}
+func GetPeerMinProtocolVersion() uint {
+	return configurationStatic.peerMinProtocolVersion
+}
+func GetPeerRequiredCapabilities() []string {
+	return configurationStatic.peerRequiredCapabilities
+}
+func GetLinkThroughputDefault() uint {
+	return configurationStatic.linkThroughputDefault
+}
+func GetGrpcListeningPort() uint {
+	return configurationStatic.grpcListeningPort
+}
+func GetGossipEnabled() bool {
+	return configurationStatic.gossipEnabled
+}
+func GetGossipPort() uint {
+	return configurationStatic.gossipPort
+}
+func GetGossipFanout() uint {
+	return configurationStatic.gossipFanout
+}
+func GetGossipInterval() uint {
+	return configurationStatic.gossipInterval
+}
+func GetGossipStaleness() uint {
+	return configurationStatic.gossipStaleness
+}
+func GetTlsEnabled() bool {
+	return configurationStatic.tlsEnabled
+}
+func GetTlsCaPath() string {
+	return configurationStatic.tlsCaPath
+}
+func GetTlsCertPath() string {
+	return configurationStatic.tlsCertPath
+}
+func GetTlsKeyPath() string {
+	return configurationStatic.tlsKeyPath
+}
+func GetTlsReloadInterval() uint {
+	return configurationStatic.tlsReloadInterval
+}
+func GetClusterKeys() []ClusterKey {
+	return configurationStatic.clusterKeys
+}
+func GetSignatureWindow() uint {
+	return configurationStatic.signatureWindow
+}
+
 // GetFunctionsList is currently unused!
 func GetFunctionsList() []string {
 	return configurationStatic.fnList
@@ -118,6 +478,28 @@ func GetFunctionsList() []string {
 func GetConfigurationDynamicCopy() *ConfigurationDynamic {
 	copiedConf := *configurationDynamic
 
+	// the maps must not be shared with the current configuration
+	copiedConf.FunctionTimeouts = make(map[string]uint)
+	for name, timeout := range configurationDynamic.FunctionTimeouts {
+		copiedConf.FunctionTimeouts[name] = timeout
+	}
+	copiedConf.AutoscalerPolicies = make(map[string]AutoscalerPolicy)
+	for name, policy := range configurationDynamic.AutoscalerPolicies {
+		copiedConf.AutoscalerPolicies[name] = policy
+	}
+	copiedConf.RetryPolicies = make(map[string]RetryPolicy)
+	for name, policy := range configurationDynamic.RetryPolicies {
+		copiedConf.RetryPolicies[name] = policy
+	}
+	copiedConf.CircuitBreakerPolicies = make(map[string]CircuitBreakerPolicy)
+	for name, policy := range configurationDynamic.CircuitBreakerPolicies {
+		copiedConf.CircuitBreakerPolicies[name] = policy
+	}
+	copiedConf.FunctionCaches = make(map[string]CachePolicy)
+	for name, policy := range configurationDynamic.FunctionCaches {
+		copiedConf.FunctionCaches[name] = policy
+	}
+
 	return &copiedConf
 }
 
@@ -134,6 +516,39 @@ func SetQueueLengthMax(n uint) {
 func SetQueueEnabled(b bool) {
 	configurationDynamic.QueueEnabled = b
 }
+func SetDraining(b bool) {
+	configurationDynamic.Draining = b
+}
+func SetFunctionTimeout(ms uint) {
+	configurationDynamic.FunctionTimeout = ms
+}
+func SetFunctionTimeouts(timeouts map[string]uint) {
+	configurationDynamic.FunctionTimeouts = timeouts
+}
+func SetAutoscalerPolicy(policy AutoscalerPolicy) {
+	configurationDynamic.AutoscalerPolicy = policy
+}
+func SetAutoscalerPolicies(policies map[string]AutoscalerPolicy) {
+	configurationDynamic.AutoscalerPolicies = policies
+}
+func SetRetryPolicy(policy RetryPolicy) {
+	configurationDynamic.RetryPolicy = policy
+}
+func SetRetryPolicies(policies map[string]RetryPolicy) {
+	configurationDynamic.RetryPolicies = policies
+}
+func SetCircuitBreakerPolicy(policy CircuitBreakerPolicy) {
+	configurationDynamic.CircuitBreakerPolicy = policy
+}
+func SetCircuitBreakerPolicies(policies map[string]CircuitBreakerPolicy) {
+	configurationDynamic.CircuitBreakerPolicies = policies
+}
+func SetOutlierEjectionPolicy(policy OutlierEjectionPolicy) {
+	configurationDynamic.OutlierEjectionPolicy = policy
+}
+func SetFunctionCaches(policies map[string]CachePolicy) {
+	configurationDynamic.FunctionCaches = policies
+}
 
 /*
  * Inits
@@ -175,6 +590,33 @@ func InitConfigurationStatic() {
 		}
 	}
 
+	if envVar := os.Getenv(EnvFaasBackend); envVar != "" {
+		configurationStatic.faasBackend = strings.ToLower(envVar)
+	}
+
+	if envVar := os.Getenv(EnvProcessManifestPath); envVar != "" {
+		configurationStatic.processManifestPath = envVar
+	}
+
+	if envVar := os.Getenv(EnvKubernetesApiUrl); envVar != "" {
+		configurationStatic.kubernetesApiUrl = strings.TrimRight(envVar, "/")
+	}
+
+	if envVar := os.Getenv(EnvKubernetesNamespace); envVar != "" {
+		configurationStatic.kubernetesNamespace = envVar
+	}
+
+	if envVar := os.Getenv(EnvKubernetesNodeName); envVar != "" {
+		configurationStatic.kubernetesNodeName = envVar
+	}
+
+	if envVar := os.Getenv(EnvKubernetesNodeLocal); envVar != "" {
+		enabled, err := strconv.ParseBool(envVar)
+		if err == nil {
+			configurationStatic.kubernetesNodeLocal = enabled
+		}
+	}
+
 	if envVar := os.Getenv(EnvOpenFaasEnabled); envVar != "" {
 		enabled, err := strconv.ParseBool(envVar)
 		if err == nil {
@@ -204,6 +646,243 @@ func InitConfigurationStatic() {
 		}
 	}
 
+	if envVar := os.Getenv(EnvJobLogEnabled); envVar != "" {
+		enabled, err := strconv.ParseBool(envVar)
+		if err == nil {
+			configurationStatic.jobLogEnabled = enabled
+		}
+	}
+
+	if envVar := os.Getenv(EnvJobLogSyncMode); envVar != "" {
+		if envVar == JobLogSyncModeAlways || envVar == JobLogSyncModeInterval || envVar == JobLogSyncModeNever {
+			configurationStatic.jobLogSyncMode = envVar
+		}
+	}
+
+	if envVar := os.Getenv(EnvJobLogSyncInterval); envVar != "" {
+		interval, err := strconv.Atoi(envVar)
+		if err == nil && interval > 0 {
+			configurationStatic.jobLogSyncInterval = uint(interval)
+		}
+	}
+
+	if envVar := os.Getenv(EnvJobLogSegmentMaxSize); envVar != "" {
+		size, err := strconv.Atoi(envVar)
+		if err == nil && size > 0 {
+			configurationStatic.jobLogSegmentMaxSize = uint(size)
+		}
+	}
+
+	if envVar := os.Getenv(EnvAutoscalerEnabled); envVar != "" {
+		enabled, err := strconv.ParseBool(envVar)
+		if err == nil {
+			configurationStatic.autoscalerEnabled = enabled
+		}
+	}
+
+	if envVar := os.Getenv(EnvAutoscalerInterval); envVar != "" {
+		interval, err := strconv.Atoi(envVar)
+		if err == nil && interval > 0 {
+			configurationStatic.autoscalerInterval = uint(interval)
+		}
+	}
+
+	if envVar := os.Getenv(EnvClusterDeployRetryInterval); envVar != "" {
+		interval, err := strconv.Atoi(envVar)
+		if err == nil && interval > 0 {
+			configurationStatic.clusterDeployRetryInterval = uint(interval)
+		}
+	}
+
+	if envVar := os.Getenv(EnvClusterDeployRetryMax); envVar != "" {
+		retries, err := strconv.Atoi(envVar)
+		if err == nil && retries >= 0 {
+			configurationStatic.clusterDeployRetryMax = uint(retries)
+		}
+	}
+
+	if envVar := os.Getenv(EnvFunctionsAdvertisementInterval); envVar != "" {
+		interval, err := strconv.Atoi(envVar)
+		if err == nil && interval > 0 {
+			configurationStatic.advertisementInterval = uint(interval)
+		}
+	}
+
+	if envVar := os.Getenv(EnvStreamingEnabled); envVar != "" {
+		enabled, err := strconv.ParseBool(envVar)
+		if err == nil {
+			configurationStatic.streamingEnabled = enabled
+		}
+	}
+
+	if envVar := os.Getenv(EnvPayloadMaxSize); envVar != "" {
+		size, err := strconv.Atoi(envVar)
+		if err == nil && size >= 0 {
+			configurationStatic.payloadMaxSize = uint(size)
+		}
+	}
+
+	if envVar := os.Getenv(EnvResponseMaxSize); envVar != "" {
+		size, err := strconv.Atoi(envVar)
+		if err == nil && size >= 0 {
+			configurationStatic.responseMaxSize = uint(size)
+		}
+	}
+
+	if envVar := os.Getenv(EnvCacheMaxSize); envVar != "" {
+		size, err := strconv.Atoi(envVar)
+		if err == nil && size >= 0 {
+			configurationStatic.cacheMaxSize = uint(size)
+		}
+	}
+
+	if envVar := os.Getenv(EnvPeerKeepAlive); envVar != "" {
+		enabled, err := strconv.ParseBool(envVar)
+		if err == nil {
+			configurationStatic.peerKeepAlive = enabled
+		}
+	}
+
+	if envVar := os.Getenv(EnvPeerHttp2Enabled); envVar != "" {
+		enabled, err := strconv.ParseBool(envVar)
+		if err == nil {
+			configurationStatic.peerHttp2Enabled = enabled
+		}
+	}
+
+	if envVar := os.Getenv(EnvPeerMaxIdleConnsPerHost); envVar != "" {
+		conns, err := strconv.Atoi(envVar)
+		if err == nil && conns > 0 {
+			configurationStatic.peerMaxIdleConnsPerHost = uint(conns)
+		}
+	}
+
+	if envVar := os.Getenv(EnvPeerIdleConnTimeout); envVar != "" {
+		timeout, err := strconv.Atoi(envVar)
+		if err == nil && timeout > 0 {
+			configurationStatic.peerIdleConnTimeout = uint(timeout)
+		}
+	}
+
+	if envVar := os.Getenv(EnvPeerTransport); envVar != "" {
+		if envVar == PeerTransportHttp || envVar == PeerTransportGrpc {
+			configurationStatic.peerTransport = envVar
+		} else {
+			log.Log.Warningf("Unknown peer transport %s, using %s", envVar, configurationStatic.peerTransport)
+		}
+	}
+
+	if envVar := os.Getenv(EnvPeerMinProtocolVersion); envVar != "" {
+		version, err := strconv.Atoi(envVar)
+		if err == nil && version >= 0 {
+			configurationStatic.peerMinProtocolVersion = uint(version)
+		}
+	}
+
+	if envVar := os.Getenv(EnvPeerRequiredCapabilities); envVar != "" {
+		for _, capability := range strings.Split(envVar, ",") {
+			if capability = strings.TrimSpace(capability); capability != "" {
+				configurationStatic.peerRequiredCapabilities = append(configurationStatic.peerRequiredCapabilities, capability)
+			}
+		}
+	}
+
+	if envVar := os.Getenv(EnvLinkThroughputDefault); envVar != "" {
+		throughput, err := strconv.Atoi(envVar)
+		if err == nil && throughput > 0 {
+			configurationStatic.linkThroughputDefault = uint(throughput)
+		}
+	}
+
+	if envVar := os.Getenv(EnvGossipEnabled); envVar != "" {
+		enabled, err := strconv.ParseBool(envVar)
+		if err == nil {
+			configurationStatic.gossipEnabled = enabled
+		}
+	}
+
+	if envVar := os.Getenv(EnvGossipPort); envVar != "" {
+		port, err := strconv.Atoi(envVar)
+		if err == nil && port > 0 {
+			configurationStatic.gossipPort = uint(port)
+		}
+	}
+
+	if envVar := os.Getenv(EnvGossipFanout); envVar != "" {
+		fanout, err := strconv.Atoi(envVar)
+		if err == nil && fanout > 0 {
+			configurationStatic.gossipFanout = uint(fanout)
+		}
+	}
+
+	if envVar := os.Getenv(EnvGossipInterval); envVar != "" {
+		interval, err := strconv.Atoi(envVar)
+		if err == nil && interval > 0 {
+			configurationStatic.gossipInterval = uint(interval)
+		}
+	}
+
+	if envVar := os.Getenv(EnvGossipStaleness); envVar != "" {
+		staleness, err := strconv.Atoi(envVar)
+		if err == nil && staleness > 0 {
+			configurationStatic.gossipStaleness = uint(staleness)
+		}
+	}
+
+	if envVar := os.Getenv(EnvGrpcListeningPort); envVar != "" {
+		port, err := strconv.Atoi(envVar)
+		if err == nil && port > 0 {
+			configurationStatic.grpcListeningPort = uint(port)
+		}
+	}
+
+	if envVar := os.Getenv(EnvTlsEnabled); envVar != "" {
+		enabled, err := strconv.ParseBool(envVar)
+		if err == nil {
+			configurationStatic.tlsEnabled = enabled
+		}
+	}
+
+	if envVar := os.Getenv(EnvTlsCaPath); envVar != "" {
+		configurationStatic.tlsCaPath = envVar
+	}
+
+	if envVar := os.Getenv(EnvTlsCertPath); envVar != "" {
+		configurationStatic.tlsCertPath = envVar
+	}
+
+	if envVar := os.Getenv(EnvTlsKeyPath); envVar != "" {
+		configurationStatic.tlsKeyPath = envVar
+	}
+
+	if envVar := os.Getenv(EnvTlsReloadInterval); envVar != "" {
+		interval, err := strconv.Atoi(envVar)
+		if err == nil && interval >= 0 {
+			configurationStatic.tlsReloadInterval = uint(interval)
+		}
+	}
+
+	// keys are in the form id:secret, the first one signs and all of them verify, so that keys can be rotated
+	if envVar := os.Getenv(EnvClusterKeys); envVar != "" {
+		for _, key := range strings.Split(envVar, ",") {
+			separator := strings.Index(key, ":")
+			if separator <= 0 || separator == len(key)-1 {
+				log.Log.Warningf("Cluster key must be in the form id:secret, ignoring it")
+				continue
+			}
+			configurationStatic.clusterKeys = append(configurationStatic.clusterKeys, ClusterKey{
+				Id:     key[:separator],
+				Secret: key[separator+1:],
+			})
+		}
+	}
+
+	if envVar := os.Getenv(EnvSignatureWindow); envVar != "" {
+		window, err := strconv.Atoi(envVar)
+		if err == nil && window > 0 {
+			configurationStatic.signatureWindow = uint(window)
+		}
+	}
 }
 
 // InitConfigurationDynamic prepare the configuration object, returns if config is read from file
@@ -249,6 +928,48 @@ func GetDefaultConfigurationDynamic() *ConfigurationDynamic {
 		ParallelRunningFunctionsMax: 4,
 		QueueLengthMax:              4, // put always > 0
 		QueueEnabled:                true,
+		FunctionTimeout:             DefaultFunctionTimeout,
+		FunctionTimeouts:            map[string]uint{},
+		AutoscalerPolicy: AutoscalerPolicy{
+			MinReplicas:        1,
+			MaxReplicas:        4,
+			ScaleUpQueueLength: 1,
+			ScaleUpLatency:     0,
+			ScaleUpCooldown:    10000,
+			ScaleDownCooldown:  60000,
+			IdleTimeToZero:     0,
+		},
+		AutoscalerPolicies: map[string]AutoscalerPolicy{},
+		FunctionCaches:     map[string]CachePolicy{},
+		RetryPolicy: RetryPolicy{
+			MaxAttempts:          1,
+			Backoff:              100,
+			BackoffMultiplier:    2,
+			BackoffMax:           2000,
+			RetryableStatusCodes: []int{502, 503, 504},
+			RetryableErrors:      []string{RetryErrorConnection},
+			Target:               RetryTargetLocal,
+		},
+		RetryPolicies: map[string]RetryPolicy{},
+		CircuitBreakerPolicy: CircuitBreakerPolicy{
+			ErrorRateThreshold: 0,
+			LatencyThreshold:   0,
+			WindowSize:         20,
+			MinExecutions:      10,
+			OpenDuration:       10000,
+			HalfOpenExecutions: 3,
+		},
+		CircuitBreakerPolicies: map[string]CircuitBreakerPolicy{},
+		Draining:               false,
+		OutlierEjectionPolicy: OutlierEjectionPolicy{
+			ConsecutiveFailures: 5,
+			LatencyFactor:       3,
+			LatencyMin:          50,
+			LatencyMinPeers:     3,
+			EjectionTime:        10000,
+			EjectionTimeMax:     300000,
+			MaxEjectedPercent:   50,
+		},
 	}
 }
 
@@ -262,10 +983,50 @@ func GetDefaultConfigurationStatic() *ConfigurationStatic {
 		serviceLearningListeningHost:  DefaultServiceLearningListeningHost,
 		dataPath:                      DefaultDataPath,
 		runningEnvironment:            DefaultRunningEnvironment,
+		faasBackend:                   "",
+		processManifestPath:           "",
+		kubernetesApiUrl:              DefaultKubernetesApiUrl,
+		kubernetesNamespace:           "",
+		kubernetesNodeName:            "",
+		kubernetesNodeLocal:           true,
 		openFaasEnabled:               false,
 		openFaasListeningPort:         DefaultOpenFaaSListeningPort,
 		openFaasListeningHost:         DefaultOpenFaaSListeningHost,
 		fnList:                        []string{},
 		profilingEnabled:              false,
+		jobLogEnabled:                 false,
+		jobLogSyncMode:                DefaultJobLogSyncMode,
+		jobLogSyncInterval:            DefaultJobLogSyncInterval,
+		jobLogSegmentMaxSize:          DefaultJobLogSegmentMaxSize,
+		autoscalerEnabled:             false,
+		autoscalerInterval:            DefaultAutoscalerInterval,
+		clusterDeployRetryInterval:    DefaultClusterDeployRetryInterval,
+		clusterDeployRetryMax:         DefaultClusterDeployRetryMax,
+		advertisementInterval:         DefaultFunctionsAdvertisementInterval,
+		streamingEnabled:              false,
+		payloadMaxSize:                DefaultPayloadMaxSize,
+		responseMaxSize:               DefaultResponseMaxSize,
+		cacheMaxSize:                  DefaultCacheMaxSize,
+		peerKeepAlive:                 true,
+		peerHttp2Enabled:              false,
+		peerMaxIdleConnsPerHost:       DefaultPeerMaxIdleConnsPerHost,
+		peerIdleConnTimeout:           DefaultPeerIdleConnTimeout,
+		peerTransport:                 PeerTransportHttp,
+		grpcListeningPort:             DefaultGrpcListeningPort,
+		peerMinProtocolVersion:        DefaultPeerMinProtocolVersion,
+		peerRequiredCapabilities:      []string{},
+		linkThroughputDefault:         DefaultLinkThroughput,
+		gossipEnabled:                 false,
+		gossipPort:                    DefaultGossipPort,
+		gossipFanout:                  DefaultGossipFanout,
+		gossipInterval:                DefaultGossipInterval,
+		gossipStaleness:               DefaultGossipStaleness,
+		tlsEnabled:                    false,
+		tlsCaPath:                     DefaultTlsCaPath,
+		tlsCertPath:                   DefaultTlsCertPath,
+		tlsKeyPath:                    DefaultTlsKeyPath,
+		tlsReloadInterval:             DefaultTlsReloadInterval,
+		clusterKeys:                   []ClusterKey{},
+		signatureWindow:               DefaultSignatureWindow,
 	}
 }
@@ -33,6 +33,10 @@ func GetConfigSchedulerFilePath() string {
 	return GetDataPath() + "/" + ConfigurationSchedulerFileName
 }
 
+func GetJobLogPath() string {
+	return GetDataPath() + "/" + JobLogDirName
+}
+
 func SaveConfigurationDynamicToConfigFile() error {
 	// create folder if not exists
 	err := CreateDataFolder() // os.Mkdir(GetDataPath(), 0664)
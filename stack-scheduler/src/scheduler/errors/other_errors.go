@@ -18,6 +18,11 @@
 
 package errors
 
+import (
+	"fmt"
+	"time"
+)
+
 type ErrorJSONEncode struct{}
 
 func (e ErrorJSONEncode) Error() string {
@@ -29,3 +34,29 @@ type ErrorJSONDecoding struct{}
 func (e ErrorJSONDecoding) Error() string {
 	return "Error while decoding JSON"
 }
+
+type ErrorFaasOperationNotSupported struct {
+	Backend   string
+	Operation string
+}
+
+func (e ErrorFaasOperationNotSupported) Error() string {
+	return fmt.Sprintf("Operation %s is not supported by the %s faas backend", e.Operation, e.Backend)
+}
+
+type ErrorFaasExecutionTimeout struct {
+	Function string
+	Timeout  time.Duration
+}
+
+func (e ErrorFaasExecutionTimeout) Error() string {
+	return fmt.Sprintf("Execution of function %s timed out after %s", e.Function, e.Timeout)
+}
+
+type ErrorFaasCircuitOpen struct {
+	Function string
+}
+
+func (e ErrorFaasCircuitOpen) Error() string {
+	return fmt.Sprintf("Circuit breaker of function %s is open", e.Function)
+}
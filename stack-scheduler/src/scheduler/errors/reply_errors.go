@@ -37,15 +37,25 @@ const (
 	InputNotValid               int = 4
 	FaasConnectError            int = 5
 	MarshalError                int = 6
+	DiscoveryConnectError       int = 7
+	PayloadTooLarge             int = 8
 	ServiceNotValid             int = 100
 	GenericDeployError          int = 200
+	GenericUpdateError          int = 201
+	GenericRemoveError          int = 202
+	ClusterNoNodeSelected       int = 203
 	GenericOpenFaasError        int = 300
+	FaasOperationNotSupported   int = 301
+	FaasBackendNotAvailable     int = 302
 	JobCannotBeScheduledError   int = 400
 	JobDeliberatelyRejected     int = 401
 	CannotRetrieveAction        int = 402
 	JobCouldNotBeForwarded      int = 403
 	PeerResponseNil             int = 404
 	CannotRetrieveRecipientNode int = 405
+	JobExecutionTimeout         int = 406
+	FunctionCircuitOpen         int = 407
+	MachineDraining             int = 408
 
 	DBDuplicateKey int = 11000
 )
@@ -57,12 +67,19 @@ var errorMessages = map[int]string{
 	4: "Passed input is not correct or malformed",
 	5: "Could not contact OpenFaaS backend",
 	6: "Cannot marshal the struct",
+	7: "Could not contact the discovery service",
+	8: "Payload exceeds the maximum size",
 	// service validation
 	100: "Passed service is not valid",
 	// deploy
 	200: "Error while deploying the service",
+	201: "Error while updating the service",
+	202: "Error while removing the service",
+	203: "No node of the cluster matches the selector",
 	// openfaas
 	300: "OpenFaas generic error, see logs",
+	301: "Operation not supported by the faas backend",
+	302: "The configured faas backend is not available",
 	// scheduler
 	400: "Job cannot be scheduled due to physical limitation (e.g. queue full)",
 	401: "Job have been deliberately rejected by the scheduler",
@@ -70,6 +87,9 @@ var errorMessages = map[int]string{
 	403: "It was not possible to forward the request to the neighbor",
 	404: "Peer replied with nil response",
 	405: "Recipient node to which the job must be forwarded cannot be retrieved",
+	406: "Job execution exceeded the function timeout",
+	407: "Function is unavailable since its circuit breaker is open",
+	408: "Node is draining and does not accept jobs from peers",
 	// mongo
 	11000: "A key is duplicated",
 }
@@ -81,12 +101,19 @@ var errorStatus = map[int]int{
 	4: 400,
 	5: 500,
 	6: 500,
+	7: 500,
+	8: 413,
 	// service validation
 	100: 400,
 	// deploy
 	200: 500,
+	201: 500,
+	202: 500,
+	203: 400,
 	// openfaas
 	300: 500,
+	301: 501,
+	302: 500,
 	// scheduler
 	400: 500,
 	401: 503,
@@ -94,6 +121,9 @@ var errorStatus = map[int]int{
 	403: 500,
 	404: 500,
 	405: 500,
+	406: 504,
+	407: 503,
+	408: 503,
 	// mongo
 	11000: 400,
 }
@@ -0,0 +1,165 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package faas
+
+import (
+	"scheduler/types"
+	"sort"
+	"sync"
+)
+
+// functionWarmth tracks whether the replicas of a function on this node are ready to serve calls. The state of a
+// function is unknown until the backend tells its replicas or a call is executed, the replicas which were already
+// available when the state is first seen are assumed warm since they were started before this scheduler.
+type functionWarmth struct {
+	// executed is true when the function served at least a call since it has replicas
+	executed bool
+	// replicas is the last known number of replicas, -1 if unknown
+	replicas int
+	// coldReplicas is the number of replicas added by a scale up which did not serve any call yet
+	coldReplicas int
+}
+
+func (s *functionWarmth) isWarm() bool {
+	return s.executed && s.replicas != 0
+}
+
+var functionsWarmth = make(map[string]*functionWarmth)
+var functionsWarmthMutex sync.Mutex
+
+// IsFunctionWarm returns true if the function has a replica on this node which already served a call, so that a new
+// call is not expected to pay a cold start
+func IsFunctionWarm(functionName string) bool {
+	functionsWarmthMutex.Lock()
+	defer functionsWarmthMutex.Unlock()
+
+	state, exists := functionsWarmth[functionName]
+	return exists && state.isWarm()
+}
+
+// GetWarmFunctions returns the sorted names of the functions which are warm on this node, they are advertised to peers
+func GetWarmFunctions() []string {
+	functionsWarmthMutex.Lock()
+	defer functionsWarmthMutex.Unlock()
+
+	var names []string
+	for name, state := range functionsWarmth {
+		if state.isWarm() {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// isFunctionWarmthKnown tells if the state of the function was already seen
+func isFunctionWarmthKnown(functionName string) bool {
+	functionsWarmthMutex.Lock()
+	defer functionsWarmthMutex.Unlock()
+
+	_, exists := functionsWarmth[functionName]
+	return exists
+}
+
+// detectColdStart tells if the execution which produced the response paid a cold start. The response of the backend is
+// used if it reports it, otherwise a call is cold when it is the first one since the function has no replicas or
+// when it is among the first calls after a scale up. Nil is returned when the state of the function is unknown.
+func detectColdStart(functionName string, res *types.FaasApiResponse) *bool {
+	functionsWarmthMutex.Lock()
+	defer functionsWarmthMutex.Unlock()
+
+	state, known := functionsWarmth[functionName]
+	if !known {
+		state = &functionWarmth{replicas: -1}
+		functionsWarmth[functionName] = state
+	}
+
+	var cold bool
+	if res != nil && res.ColdStart != nil {
+		cold = *res.ColdStart
+	} else if !known {
+		state.executed = true
+		return nil
+	} else if !state.executed || state.replicas == 0 {
+		cold = true
+	} else if state.coldReplicas > 0 {
+		cold = true
+		state.coldReplicas -= 1
+	}
+
+	state.executed = true
+	if state.replicas == 0 {
+		// the backend scaled from zero to serve the call
+		state.replicas = 1
+	}
+
+	return &cold
+}
+
+// updateFunctionReplicas records the replicas of the function as they are told by the backend, when no replica is
+// available the next call will pay a cold start
+func updateFunctionReplicas(functionName string, replicas uint, availableReplicas uint) {
+	functionsWarmthMutex.Lock()
+	defer functionsWarmthMutex.Unlock()
+
+	state, exists := functionsWarmth[functionName]
+	if !exists {
+		functionsWarmth[functionName] = &functionWarmth{executed: availableReplicas > 0, replicas: int(replicas)}
+		return
+	}
+	if availableReplicas == 0 {
+		state.executed = false
+		state.coldReplicas = 0
+	}
+	state.replicas = int(replicas)
+}
+
+// scaleFunctionReplicas records a scale of the function, the new replicas will pay a cold start at their first call
+func scaleFunctionReplicas(functionName string, replicas uint) {
+	functionsWarmthMutex.Lock()
+	defer functionsWarmthMutex.Unlock()
+
+	state := getFunctionWarmth(functionName)
+	if replicas == 0 {
+		state.executed = false
+		state.coldReplicas = 0
+	} else if state.replicas >= 0 && int(replicas) > state.replicas {
+		state.coldReplicas += int(replicas) - state.replicas
+	} else if int(replicas) < state.coldReplicas {
+		state.coldReplicas = int(replicas)
+	}
+	state.replicas = int(replicas)
+}
+
+// resetFunctionWarmth forgets the replicas of the function, the next call will pay a cold start
+func resetFunctionWarmth(functionName string) {
+	functionsWarmthMutex.Lock()
+	defer functionsWarmthMutex.Unlock()
+
+	functionsWarmth[functionName] = &functionWarmth{replicas: -1}
+}
+
+func getFunctionWarmth(functionName string) *functionWarmth {
+	state, exists := functionsWarmth[functionName]
+	if !exists {
+		state = &functionWarmth{replicas: -1}
+		functionsWarmth[functionName] = state
+	}
+	return state
+}
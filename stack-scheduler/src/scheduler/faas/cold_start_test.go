@@ -0,0 +1,69 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package faas
+
+import (
+	"testing"
+)
+
+func expectColdStart(t *testing.T, functionName string, expected bool) {
+	t.Helper()
+	cold := detectColdStart(functionName, nil)
+	if cold == nil || *cold != expected {
+		t.Fatalf("expected cold start %t for %s, got %v", expected, functionName, cold)
+	}
+}
+
+func TestColdStartUnknownState(t *testing.T) {
+	if cold := detectColdStart("unknown-fn", nil); cold != nil {
+		t.Fatalf("expected an unknown cold start, got %t", *cold)
+	}
+	expectColdStart(t, "unknown-fn", false)
+}
+
+func TestColdStartSeededFromAvailableReplicas(t *testing.T) {
+	updateFunctionReplicas("available-fn", 2, 2)
+	expectColdStart(t, "available-fn", false)
+
+	updateFunctionReplicas("scaled-to-zero-fn", 0, 0)
+	expectColdStart(t, "scaled-to-zero-fn", true)
+	expectColdStart(t, "scaled-to-zero-fn", false)
+}
+
+func TestColdStartAfterScaleUp(t *testing.T) {
+	updateFunctionReplicas("scaled-fn", 1, 1)
+	scaleFunctionReplicas("scaled-fn", 3)
+
+	expectColdStart(t, "scaled-fn", true)
+	expectColdStart(t, "scaled-fn", true)
+	expectColdStart(t, "scaled-fn", false)
+}
+
+func TestColdStartAfterReset(t *testing.T) {
+	updateFunctionReplicas("updated-fn", 1, 1)
+	resetFunctionWarmth("updated-fn")
+	if IsFunctionWarm("updated-fn") {
+		t.Fatalf("expected updated-fn to be cold")
+	}
+
+	expectColdStart(t, "updated-fn", true)
+	if !IsFunctionWarm("updated-fn") {
+		t.Fatalf("expected updated-fn to be warm")
+	}
+}
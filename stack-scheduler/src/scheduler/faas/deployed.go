@@ -0,0 +1,81 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package faas
+
+import (
+	"scheduler/config"
+	"scheduler/types"
+	"sync"
+	"time"
+)
+
+// deployedFunctions caches the functions deployed in the backend, which are advertised to the other nodes
+var deployedFunctions map[string]string
+var deployedFunctionsUpdatedAt time.Time
+var deployedFunctionsMutex sync.Mutex
+
+// GetDeployedFunctions returns the functions deployed in the backend as name -> version, where the version is the
+// image of the function. The list is refreshed at most once every advertisement interval.
+func GetDeployedFunctions() (map[string]string, error) {
+	deployedFunctionsMutex.Lock()
+	fresh := deployedFunctions != nil &&
+		time.Since(deployedFunctionsUpdatedAt) < time.Duration(config.GetFunctionsAdvertisementInterval())*time.Millisecond
+	if fresh {
+		out := copyDeployedFunctions()
+		deployedFunctionsMutex.Unlock()
+		return out, nil
+	}
+	deployedFunctionsMutex.Unlock()
+
+	_, _, err := FunctionsGet()
+	if err != nil {
+		return nil, err
+	}
+
+	deployedFunctionsMutex.Lock()
+	defer deployedFunctionsMutex.Unlock()
+	return copyDeployedFunctions(), nil
+}
+
+func setDeployedFunctions(functions []types.FaasFunction) {
+	deployedFunctionsMutex.Lock()
+	defer deployedFunctionsMutex.Unlock()
+
+	deployedFunctions = make(map[string]string, len(functions))
+	for _, function := range functions {
+		deployedFunctions[function.Name] = function.Image
+	}
+	deployedFunctionsUpdatedAt = time.Now()
+}
+
+// invalidateDeployedFunctions forces the refresh of the deployed functions at the next request
+func invalidateDeployedFunctions() {
+	deployedFunctionsMutex.Lock()
+	defer deployedFunctionsMutex.Unlock()
+
+	deployedFunctions = nil
+}
+
+func copyDeployedFunctions() map[string]string {
+	out := make(map[string]string, len(deployedFunctions))
+	for name, version := range deployedFunctions {
+		out[name] = version
+	}
+	return out
+}
@@ -0,0 +1,45 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package faas
+
+import (
+	"fmt"
+	"scheduler/faas_containers"
+	"scheduler/faas_kubernetes"
+	"scheduler/faas_openfaas"
+	"scheduler/faas_process"
+)
+
+type ErrorBackendNotRegistered struct {
+	name string
+}
+
+func (e ErrorBackendNotRegistered) Error() string {
+	return fmt.Sprintf("Faas backend %s is not registered", e.name)
+}
+
+// IsErrorFunctionNotFound returns true if the error tells that the function does not exist in the faas backend
+func IsErrorFunctionNotFound(err error) bool {
+	switch err.(type) {
+	case faas_openfaas.ErrorFunctionNotFound, faas_containers.ErrorFunctionNotFound, faas_process.ErrorFunctionNotFound,
+		faas_kubernetes.ErrorFunctionNotFound:
+		return true
+	}
+	return false
+}
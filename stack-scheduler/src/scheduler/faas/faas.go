@@ -17,4 +17,56 @@
  */
 
 // Package faas implements the logic for dispatching FaaS calls according to the selected underlying framework.
+//
+// Every framework is implemented as a Backend which is registered by name, the backend in use is selected by name in
+// the configuration.
 package faas
+
+import (
+	"context"
+	"scheduler/config"
+	"scheduler/faas_containers"
+	"scheduler/faas_kubernetes"
+	"scheduler/faas_openfaas"
+	"scheduler/faas_process"
+	"scheduler/log"
+	"scheduler/types"
+)
+
+/*
+ * Interfaces
+ */
+
+// Backend defines how a faas backend is made
+type Backend interface {
+	// GetName returns the name with which the backend is registered
+	GetName() string
+	// FunctionExecute executes the function and returns its response. If the backend is able to measure the execution
+	// time of the function it must be set in the response. The execution is aborted when the context is done or when
+	// the execution timeout of the function expires, in the latter case errors.ErrorFaasExecutionTimeout is returned.
+	FunctionExecute(ctx context.Context, functionName string, payload []byte, contentType string) (*types.FaasApiResponse, error)
+	// FunctionsGet returns all the functions deployed in the backend
+	FunctionsGet() ([]types.FaasFunction, *types.FaasApiResponse, error)
+	// FunctionGet returns the function with the passed name
+	FunctionGet(functionName string) (*types.FaasFunction, *types.FaasApiResponse, error)
+	// FunctionDeploy deploys the passed function
+	FunctionDeploy(function types.FaasFunction) (*types.FaasApiResponse, error)
+	// FunctionUpdate updates the image, the environment, the labels and the limits of an already deployed function
+	FunctionUpdate(function types.FaasFunction) (*types.FaasApiResponse, error)
+	// FunctionScale sets the replicas of the function
+	FunctionScale(functionName string, replicas uint) (*types.FaasApiResponse, error)
+	// FunctionRemove removes the function from the backend
+	FunctionRemove(functionName string) (*types.FaasApiResponse, error)
+}
+
+func init() {
+	RegisterBackend(faas_openfaas.Backend{})
+	RegisterBackend(faas_containers.Backend{})
+	RegisterBackend(faas_process.Backend{})
+	RegisterBackend(faas_kubernetes.NewBackend(nil, ""))
+
+	_, err := GetBackend()
+	if err != nil {
+		log.Log.Errorf("Faas backend \"%s\" is not registered, function calls will fail", config.GetFaasBackend())
+	}
+}
@@ -0,0 +1,69 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package faas
+
+import (
+	"scheduler/config"
+	"scheduler/log"
+	"sort"
+	"sync"
+)
+
+var backends = make(map[string]Backend)
+var backendsMutex sync.RWMutex
+
+// RegisterBackend makes the backend available to be selected by its name, a backend with the same name is replaced
+func RegisterBackend(backend Backend) {
+	backendsMutex.Lock()
+	backends[backend.GetName()] = backend
+	backendsMutex.Unlock()
+
+	log.Log.Debugf("Registered faas backend %s", backend.GetName())
+}
+
+// GetBackend returns the backend selected in the configuration
+func GetBackend() (Backend, error) {
+	return GetBackendByName(config.GetFaasBackend())
+}
+
+// GetBackendByName returns the registered backend with the passed name
+func GetBackendByName(name string) (Backend, error) {
+	backendsMutex.RLock()
+	defer backendsMutex.RUnlock()
+
+	backend, exists := backends[name]
+	if !exists {
+		return nil, ErrorBackendNotRegistered{name}
+	}
+	return backend, nil
+}
+
+// GetBackendsNames returns the names of all the registered backends
+func GetBackendsNames() []string {
+	var names []string
+
+	backendsMutex.RLock()
+	for name := range backends {
+		names = append(names, name)
+	}
+	backendsMutex.RUnlock()
+
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,53 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package faas
+
+// ScalingLeaderBackend is implemented by the backends whose functions are shared by all the schedulers, as the ones of
+// a cluster, in which only one scheduler at a time must scale the functions
+type ScalingLeaderBackend interface {
+	// IsScalingLeader tells if this scheduler is the one which scales the functions
+	IsScalingLeader() bool
+}
+
+// IsScalingLeader tells if this scheduler must scale the functions, it is always true for backends whose functions are
+// only used by this scheduler
+func IsScalingLeader() bool {
+	backend, err := GetBackend()
+	if err != nil {
+		return false
+	}
+
+	leaderBackend, ok := backend.(ScalingLeaderBackend)
+	if !ok {
+		return true
+	}
+	return leaderBackend.IsScalingLeader()
+}
+
+// HasSharedFunctions tells if the replicas of the functions are shared by all the schedulers, so that they must be
+// scaled according to the load of all of them
+func HasSharedFunctions() bool {
+	backend, err := GetBackend()
+	if err != nil {
+		return false
+	}
+
+	_, ok := backend.(ScalingLeaderBackend)
+	return ok
+}
@@ -19,17 +19,142 @@
 package faas
 
 import (
-	"scheduler/config"
-	"scheduler/faas_containers"
-	"scheduler/faas_openfaas"
+	"context"
+	"scheduler/circuit_breaker"
+	"scheduler/log"
+	"scheduler/result_cache"
 	"scheduler/types"
 )
 
 // FunctionExecute execute the FaaS function by selecting the appropriate dispatcher
-func FunctionExecute(functionName string, payload []byte, contentType string) (*types.FaasApiResponse, error) {
-	if config.GetOpenFaasEnabled() {
-		return faas_openfaas.FunctionExecute(functionName, payload, contentType)
-	} else {
-		return faas_containers.FunctionExecute(functionName, payload, contentType)
+func FunctionExecute(ctx context.Context, functionName string, payload []byte, contentType string) (*types.FaasApiResponse, error) {
+	backend, err := GetBackend()
+	if err != nil {
+		return nil, err
 	}
+	seedFunctionWarmth(functionName)
+	res, err := backend.FunctionExecute(ctx, functionName, payload, contentType)
+	if err == nil && res != nil {
+		res.ColdStart = detectColdStart(functionName, res)
+	}
+	return res, err
+}
+
+// seedFunctionWarmth gets the replicas of the function from the backend if they are not known yet, as after a restart
+// of the scheduler, so that the first call is not taken as cold when the function has already warm replicas
+func seedFunctionWarmth(functionName string) {
+	if isFunctionWarmthKnown(functionName) {
+		return
+	}
+	_, _, err := FunctionGet(functionName)
+	if err != nil {
+		log.Log.Debugf("Cannot get the replicas of %s: %s", functionName, err.Error())
+	}
+}
+
+func FunctionsGet() ([]types.FaasFunction, *types.FaasApiResponse, error) {
+	backend, err := GetBackend()
+	if err != nil {
+		return nil, nil, err
+	}
+	functions, res, err := backend.FunctionsGet()
+	if err == nil {
+		setDeployedFunctions(functions)
+	}
+	for _, function := range functions {
+		updateFunctionReplicas(function.Name, function.Replicas, function.AvailableReplicas)
+	}
+	return functions, res, err
+}
+
+func FunctionGet(functionName string) (*types.FaasFunction, *types.FaasApiResponse, error) {
+	backend, err := GetBackend()
+	if err != nil {
+		return nil, nil, err
+	}
+	function, res, err := backend.FunctionGet(functionName)
+	if err == nil && function != nil {
+		updateFunctionReplicas(function.Name, function.Replicas, function.AvailableReplicas)
+	}
+	return function, res, err
+}
+
+func FunctionDeploy(function types.FaasFunction) (*types.FaasApiResponse, error) {
+	backend, err := GetBackend()
+	if err != nil {
+		return nil, err
+	}
+	res, err := backend.FunctionDeploy(function)
+	if err == nil {
+		resetFunctionWarmth(function.Service)
+		invalidateDeployedFunctions()
+		// a redeployed function can give different results and its past failures do not count anymore
+		result_cache.RemoveFunction(function.Service)
+		circuit_breaker.Remove(function.Service)
+	}
+	return res, err
+}
+
+func FunctionUpdate(function types.FaasFunction) (*types.FaasApiResponse, error) {
+	backend, err := GetBackend()
+	if err != nil {
+		return nil, err
+	}
+	res, err := backend.FunctionUpdate(function)
+	if err == nil {
+		// the replicas are replaced with the updated ones
+		resetFunctionWarmth(function.Service)
+		invalidateDeployedFunctions()
+		result_cache.RemoveFunction(function.Service)
+		circuit_breaker.Remove(function.Service)
+	}
+	return res, err
+}
+
+func FunctionScale(functionName string, replicas uint) (*types.FaasApiResponse, error) {
+	backend, err := GetBackend()
+	if err != nil {
+		return nil, err
+	}
+	res, err := backend.FunctionScale(functionName, replicas)
+	if err == nil {
+		scaleFunctionReplicas(functionName, replicas)
+	}
+	return res, err
+}
+
+func FunctionRemove(functionName string) (*types.FaasApiResponse, error) {
+	backend, err := GetBackend()
+	if err != nil {
+		return nil, err
+	}
+	res, err := backend.FunctionRemove(functionName)
+	if err == nil {
+		resetFunctionWarmth(functionName)
+		invalidateDeployedFunctions()
+		result_cache.RemoveFunction(functionName)
+		circuit_breaker.Remove(functionName)
+	}
+	return res, err
+}
+
+func FunctionScaleByOne(functionName string) (*types.FaasApiResponse, error) {
+	function, _, err := FunctionGet(functionName)
+	if err != nil {
+		log.Log.Debugf("Could not scale by one service %s: %s", functionName, err.Error())
+		return nil, err
+	}
+	return FunctionScale(functionName, function.Replicas+1)
+}
+
+func FunctionScaleDownByOne(functionName string) (*types.FaasApiResponse, error) {
+	function, _, err := FunctionGet(functionName)
+	if err != nil {
+		log.Log.Debugf("Could not scale down by one service %s: %s", functionName, err.Error())
+		return nil, err
+	}
+	if function.Replicas == 0 {
+		return FunctionScale(functionName, 0)
+	}
+	return FunctionScale(functionName, function.Replicas-1)
 }
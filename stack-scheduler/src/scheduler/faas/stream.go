@@ -0,0 +1,58 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package faas
+
+import (
+	"context"
+	"io"
+	"scheduler/types"
+)
+
+// StreamingBackend is implemented by the backends which can execute functions without buffering the payload and the
+// response. Backends which do not implement it are used by buffering both.
+type StreamingBackend interface {
+	// FunctionExecuteStream executes the function reading the payload from the passed reader. The body of the
+	// response is returned in BodyStream only if the execution succeeded, and the caller must close it. The execution
+	// timeout of the function covers also the reading of the body.
+	FunctionExecuteStream(ctx context.Context, functionName string, payload io.Reader, contentType string) (*types.FaasApiResponse, error)
+}
+
+// FunctionExecuteStream executes the function streaming the payload and the response when the backend supports it
+func FunctionExecuteStream(ctx context.Context, functionName string, payload io.Reader, contentType string) (*types.FaasApiResponse, error) {
+	backend, err := GetBackend()
+	if err != nil {
+		return nil, err
+	}
+
+	streamingBackend, ok := backend.(StreamingBackend)
+	if !ok {
+		payloadBytes, err := io.ReadAll(payload)
+		if err != nil {
+			return nil, err
+		}
+		return FunctionExecute(ctx, functionName, payloadBytes, contentType)
+	}
+
+	seedFunctionWarmth(functionName)
+	res, err := streamingBackend.FunctionExecuteStream(ctx, functionName, payload, contentType)
+	if err == nil && res != nil {
+		res.ColdStart = detectColdStart(functionName, res)
+	}
+	return res, err
+}
@@ -18,9 +18,12 @@
 
 package faas_containers
 
-import "scheduler/types"
+import (
+	"context"
+	"scheduler/types"
+)
 
 // FunctionExecute executes the passed function name
-func FunctionExecute(functionName string, payload []byte, contentType string) (*types.FaasApiResponse, error) {
-	return GenFunctionExecute(functionName, payload, contentType)
+func FunctionExecute(ctx context.Context, functionName string, payload []byte, contentType string) (*types.FaasApiResponse, error) {
+	return GenFunctionExecute(ctx, functionName, payload, contentType)
 }
@@ -19,22 +19,31 @@
 package faas_containers
 
 import (
+	"context"
 	"io/ioutil"
+	"scheduler/errors"
 	"scheduler/log"
 	"scheduler/types"
 	"scheduler/utils"
 )
 
-func GenFunctionExecute(functionName string, payload []byte, contentType string) (*types.FaasApiResponse, error) {
+// GenFunctionExecute executes the function, the call is aborted when the execution timeout of the function expires
+func GenFunctionExecute(ctx context.Context, functionName string, payload []byte, contentType string) (*types.FaasApiResponse, error) {
+	ctx, cancel, timeout := utils.FunctionTimeoutContext(ctx, functionName)
+	defer cancel()
+
 	var res *types.FaasApiResponse
 	var err error
 
 	if payload == nil {
-		res, err = functionExecuteApiCall(functionName)
+		res, err = functionExecuteApiCall(ctx, functionName)
 	} else {
-		res, err = functionExecutePostApiCall(functionName, payload, contentType)
+		res, err = functionExecutePostApiCall(ctx, functionName, payload, contentType)
 	}
 
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, errors.ErrorFaasExecutionTimeout{Function: functionName, Timeout: timeout}
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -55,8 +64,8 @@ func GenFunctionExecute(functionName string, payload []byte, contentType string)
  * Utils
  */
 
-func functionExecuteApiCall(functionName string) (*types.FaasApiResponse, error) {
-	res, err := utils.HttpGet(GetApiFunctionUrl(functionName))
+func functionExecuteApiCall(ctx context.Context, functionName string) (*types.FaasApiResponse, error) {
+	res, err := utils.HttpGetWithContext(ctx, GetApiFunctionUrl(functionName))
 	if err != nil {
 		log.Log.Debugf("Cannot create GET request to %s", err.Error(), GetApiFunctionUrl(functionName))
 		return nil, err
@@ -74,8 +83,8 @@ func functionExecuteApiCall(functionName string) (*types.FaasApiResponse, error)
 	return &response, err
 }
 
-func functionExecutePostApiCall(functionName string, payload []byte, contentType string) (*types.FaasApiResponse, error) {
-	res, err := utils.HttpPost(GetApiFunctionUrl(functionName), payload, contentType)
+func functionExecutePostApiCall(ctx context.Context, functionName string, payload []byte, contentType string) (*types.FaasApiResponse, error) {
+	res, err := utils.HttpPostWithContext(ctx, GetApiFunctionUrl(functionName), payload, contentType)
 	if err != nil {
 		log.Log.Debugf("Cannot create POST request to %s", err.Error(), GetApiFunctionUrl(functionName))
 		return nil, err
@@ -0,0 +1,58 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package faas_kubernetes
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+const BackendName = "kubernetes"
+
+// Backend implements the faas backend in which every function is a Kubernetes Deployment with a Service in the
+// configured namespace
+type Backend struct {
+	// client is the client of the api server, when nil it is built with NewApiHttpClient at the first call
+	client     *http.Client
+	clientOnce sync.Once
+	// apiUrl is the base url of the api server, when empty the configured one is used
+	apiUrl string
+
+	endpoints      map[string]*functionEndpoints
+	endpointsMutex sync.Mutex
+
+	scalingLeaseHeld      bool
+	scalingLeaseCheckedAt time.Time
+	scalingLeaseMutex     sync.Mutex
+}
+
+// NewBackend returns the backend which calls the api server at apiUrl with the passed client. When the client is nil it
+// is built with NewApiHttpClient at the first call, and when apiUrl is empty the configured one is used.
+func NewBackend(client *http.Client, apiUrl string) *Backend {
+	return &Backend{
+		client:    client,
+		apiUrl:    apiUrl,
+		endpoints: map[string]*functionEndpoints{},
+	}
+}
+
+func (b *Backend) GetName() string {
+	return BackendName
+}
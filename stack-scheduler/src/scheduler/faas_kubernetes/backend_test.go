@@ -0,0 +1,250 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package faas_kubernetes
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"scheduler/config"
+	"scheduler/types"
+	"sync"
+	"testing"
+)
+
+const testNamespace = "functions"
+
+// fakeApiServer records the requests to the api server and replies with the handler of their method and path
+type fakeApiServer struct {
+	mutex    sync.Mutex
+	requests []string
+	bodies   map[string][]byte
+	handlers map[string]func(w http.ResponseWriter, r *http.Request)
+}
+
+func (s *fakeApiServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := r.Method + " " + r.URL.Path
+	body, _ := ioutil.ReadAll(r.Body)
+
+	s.mutex.Lock()
+	s.requests = append(s.requests, key)
+	s.bodies[key] = body
+	handler, exists := s.handlers[key]
+	s.mutex.Unlock()
+
+	if !exists {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	handler(w, r)
+}
+
+func (s *fakeApiServer) received(key string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, request := range s.requests {
+		if request == key {
+			return true
+		}
+	}
+	return false
+}
+
+// newTestBackend starts a fake api server with the passed handlers and returns a backend which calls it
+func newTestBackend(t *testing.T, handlers map[string]func(w http.ResponseWriter, r *http.Request)) (*Backend, *fakeApiServer) {
+	t.Setenv(config.EnvKubernetesNamespace, testNamespace)
+	config.InitConfigurationStatic()
+
+	fake := &fakeApiServer{bodies: map[string][]byte{}, handlers: handlers}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	return NewBackend(server.Client(), server.URL), fake
+}
+
+func replyStatus(statusCode int) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(statusCode)
+		_, _ = w.Write([]byte("{}"))
+	}
+}
+
+func replyJson(value interface{}) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentTypeJson)
+		_ = json.NewEncoder(w).Encode(value)
+	}
+}
+
+func testEndpointSlices() EndpointSliceList {
+	ready := true
+	notReady := false
+	port := 9000
+	return EndpointSliceList{Items: []EndpointSlice{{
+		Ports: []EndpointPort{{Name: FunctionPortName, Port: &port}},
+		Endpoints: []Endpoint{
+			{Addresses: []string{"10.0.0.1"}, NodeName: "node-1", Conditions: EndpointConditions{Ready: &ready}},
+			{Addresses: []string{"10.0.0.2"}, NodeName: "node-2"},
+			{Addresses: []string{"10.0.0.3"}, NodeName: "node-1", Conditions: EndpointConditions{Ready: &notReady}},
+		},
+	}}}
+}
+
+const testEndpointSlicesPath = "GET /apis/discovery.k8s.io/v1/namespaces/" + testNamespace + "/endpointslices"
+
+func TestResolveNodeLocalEndpoints(t *testing.T) {
+	t.Setenv(config.EnvKubernetesNodeName, "node-1")
+	t.Setenv(config.EnvKubernetesNodeLocal, "true")
+	backend, fake := newTestBackend(t, map[string]func(w http.ResponseWriter, r *http.Request){
+		testEndpointSlicesPath: replyJson(testEndpointSlices()),
+	})
+
+	for i := 0; i < 3; i++ {
+		url, err := backend.GetFunctionUrl("fn")
+		if err != nil {
+			t.Fatalf("cannot get the url of the function: %s", err)
+		}
+		if url != "http://10.0.0.1:9000" {
+			t.Fatalf("expected the ready endpoint on the node of the scheduler, got %s", url)
+		}
+	}
+	if !fake.received(testEndpointSlicesPath) {
+		t.Fatalf("endpoint slices have not been requested")
+	}
+}
+
+func TestResolveEndpointsOfAllNodes(t *testing.T) {
+	t.Setenv(config.EnvKubernetesNodeName, "node-1")
+	t.Setenv(config.EnvKubernetesNodeLocal, "false")
+	backend, _ := newTestBackend(t, map[string]func(w http.ResponseWriter, r *http.Request){
+		testEndpointSlicesPath: replyJson(testEndpointSlices()),
+	})
+
+	urls := map[string]bool{}
+	for i := 0; i < 4; i++ {
+		url, err := backend.GetFunctionUrl("fn")
+		if err != nil {
+			t.Fatalf("cannot get the url of the function: %s", err)
+		}
+		urls[url] = true
+	}
+	if len(urls) != 2 || !urls["http://10.0.0.1:9000"] || !urls["http://10.0.0.2:9000"] {
+		t.Fatalf("expected the two ready endpoints in round-robin, got %v", urls)
+	}
+}
+
+func TestDeployRemovesDeploymentWhenServiceFails(t *testing.T) {
+	deploymentsPath := "/apis/apps/v1/namespaces/" + testNamespace + "/deployments"
+	backend, fake := newTestBackend(t, map[string]func(w http.ResponseWriter, r *http.Request){
+		"POST " + deploymentsPath:                                replyStatus(http.StatusCreated),
+		"POST /api/v1/namespaces/" + testNamespace + "/services": replyStatus(http.StatusInternalServerError),
+		"DELETE " + deploymentsPath + "/fn":                      replyStatus(http.StatusOK),
+	})
+
+	_, err := backend.FunctionDeploy(types.FaasFunction{Service: "fn", Image: "image"})
+	if err == nil {
+		t.Fatalf("expected the deploy to fail")
+	}
+	if !fake.received("DELETE " + deploymentsPath + "/fn") {
+		t.Fatalf("expected the deployment to be removed after the failed deploy")
+	}
+}
+
+func TestDeployReusesExistingService(t *testing.T) {
+	deploymentsPath := "/apis/apps/v1/namespaces/" + testNamespace + "/deployments"
+	backend, fake := newTestBackend(t, map[string]func(w http.ResponseWriter, r *http.Request){
+		"POST " + deploymentsPath:                                replyStatus(http.StatusCreated),
+		"POST /api/v1/namespaces/" + testNamespace + "/services": replyStatus(http.StatusConflict),
+	})
+
+	_, err := backend.FunctionDeploy(types.FaasFunction{Service: "fn", Image: "image"})
+	if err != nil {
+		t.Fatalf("expected the deploy to succeed, got %s", err)
+	}
+	if fake.received("DELETE " + deploymentsPath + "/fn") {
+		t.Fatalf("the deployment must not be removed")
+	}
+
+	var deployment Deployment
+	_ = json.Unmarshal(fake.bodies["POST "+deploymentsPath], &deployment)
+	if deployment.Metadata.Labels[FunctionLabel] != "fn" || deployment.Spec.Template.Spec.Containers[0].Image != "image" {
+		t.Fatalf("unexpected deployment %+v", deployment)
+	}
+}
+
+func TestDeployAlreadyExisting(t *testing.T) {
+	deploymentsPath := "/apis/apps/v1/namespaces/" + testNamespace + "/deployments"
+	servicesPath := "/api/v1/namespaces/" + testNamespace + "/services"
+
+	for _, serviceStatusCode := range []int{http.StatusCreated, http.StatusConflict, http.StatusInternalServerError} {
+		backend, fake := newTestBackend(t, map[string]func(w http.ResponseWriter, r *http.Request){
+			"POST " + deploymentsPath: replyStatus(http.StatusConflict),
+			"POST " + servicesPath:    replyStatus(serviceStatusCode),
+		})
+
+		res, err := backend.FunctionDeploy(types.FaasFunction{Service: "fn", Image: "image"})
+		if serviceStatusCode == http.StatusInternalServerError {
+			if err == nil {
+				t.Fatalf("expected the deploy to fail when the service cannot be created")
+			}
+		} else if err != nil || res.StatusCode != http.StatusOK {
+			t.Fatalf("expected the existing deployment to be reused with service status %d, got %v", serviceStatusCode, err)
+		}
+		// the deployment has been created by another scheduler, which is using it
+		if fake.received("DELETE " + deploymentsPath + "/fn") {
+			t.Fatalf("the existing deployment must not be removed")
+		}
+	}
+}
+
+func TestScale(t *testing.T) {
+	scalePath := "PATCH /apis/apps/v1/namespaces/" + testNamespace + "/deployments/fn/scale"
+	var contentType string
+	backend, fake := newTestBackend(t, map[string]func(w http.ResponseWriter, r *http.Request){
+		scalePath: func(w http.ResponseWriter, r *http.Request) {
+			contentType = r.Header.Get("Content-Type")
+			replyStatus(http.StatusOK)(w, r)
+		},
+	})
+
+	_, err := backend.FunctionScale("fn", 3)
+	if err != nil {
+		t.Fatalf("cannot scale the function: %s", err)
+	}
+	if contentType != contentTypeMergePatch {
+		t.Fatalf("expected a merge patch, got %s", contentType)
+	}
+
+	var scale Scale
+	_ = json.Unmarshal(fake.bodies[scalePath], &scale)
+	if scale.Spec.Replicas != 3 {
+		t.Fatalf("expected 3 replicas, got %d", scale.Spec.Replicas)
+	}
+}
+
+func TestScaleNotFound(t *testing.T) {
+	backend, _ := newTestBackend(t, nil)
+
+	_, err := backend.FunctionScale("fn", 3)
+	if _, ok := err.(ErrorFunctionNotFound); !ok {
+		t.Fatalf("expected function not found, got %v", err)
+	}
+}
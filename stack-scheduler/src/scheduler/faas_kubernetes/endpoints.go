@@ -0,0 +1,137 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package faas_kubernetes
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"scheduler/config"
+	"scheduler/log"
+	"strconv"
+	"time"
+)
+
+// EndpointsCacheTtl is how long the resolved endpoints of a function are reused before asking again the api server
+const EndpointsCacheTtl = 2 * time.Second
+
+type functionEndpoints struct {
+	addresses []string
+	updatedAt time.Time
+	next      int
+}
+
+// GetFunctionUrl returns the url of one of the ready endpoints of the function, endpoints are picked in round-robin
+func (b *Backend) GetFunctionUrl(functionName string) (string, error) {
+	b.endpointsMutex.Lock()
+	cached, exists := b.endpoints[functionName]
+	b.endpointsMutex.Unlock()
+
+	if !exists || time.Since(cached.updatedAt) > EndpointsCacheTtl {
+		addresses, err := b.resolveFunctionEndpoints(functionName)
+		if err != nil {
+			return "", err
+		}
+
+		b.endpointsMutex.Lock()
+		cached = &functionEndpoints{addresses: addresses, updatedAt: time.Now()}
+		b.endpoints[functionName] = cached
+		b.endpointsMutex.Unlock()
+	}
+
+	b.endpointsMutex.Lock()
+	defer b.endpointsMutex.Unlock()
+
+	if len(cached.addresses) == 0 {
+		return "", ErrorNoReadyEndpoints{functionName}
+	}
+	address := cached.addresses[cached.next%len(cached.addresses)]
+	cached.next++
+
+	return fmt.Sprintf("http://%s", address), nil
+}
+
+// invalidateFunctionEndpoints forces the resolution of the endpoints at the next execution, for example because one of
+// them is not reachable anymore
+func (b *Backend) invalidateFunctionEndpoints(functionName string) {
+	b.endpointsMutex.Lock()
+	defer b.endpointsMutex.Unlock()
+
+	delete(b.endpoints, functionName)
+}
+
+// resolveFunctionEndpoints returns the ready addresses of the service of the function, read from its endpoint slices.
+// When node-local endpoints are enabled only the addresses on the node of the scheduler are returned, if there is at
+// least one.
+func (b *Backend) resolveFunctionEndpoints(functionName string) ([]string, error) {
+	res, err := b.apiCall("GET", b.GetApiEndpointSlicesUrl(functionName), nil, "")
+	if err != nil {
+		return nil, err
+	}
+	if err = checkApiResponse(res); err != nil {
+		return nil, err
+	}
+
+	var slices EndpointSliceList
+	err = json.Unmarshal(res.Body, &slices)
+	if err != nil {
+		log.Log.Debugf("Cannot decode endpoint slices of %s: %s", functionName, err.Error())
+		return nil, err
+	}
+
+	nodeName := config.GetKubernetesNodeName()
+	var addresses []string
+	var localAddresses []string
+
+	for _, slice := range slices.Items {
+		port := slicePort(slice)
+		for _, endpoint := range slice.Endpoints {
+			if endpoint.Conditions.Ready != nil && !*endpoint.Conditions.Ready {
+				continue
+			}
+			// the addresses of an endpoint are of the same pod, so only the first is used
+			if len(endpoint.Addresses) == 0 {
+				continue
+			}
+			hostPort := net.JoinHostPort(endpoint.Addresses[0], strconv.Itoa(port))
+			addresses = append(addresses, hostPort)
+			if nodeName != "" && endpoint.NodeName == nodeName {
+				localAddresses = append(localAddresses, hostPort)
+			}
+		}
+	}
+
+	if config.GetKubernetesNodeLocal() && len(localAddresses) > 0 {
+		return localAddresses, nil
+	}
+	return addresses, nil
+}
+
+// slicePort returns the port named http of the endpoint slice, or the first one
+func slicePort(slice EndpointSlice) int {
+	for _, port := range slice.Ports {
+		if port.Name == FunctionPortName && port.Port != nil {
+			return *port.Port
+		}
+	}
+	if len(slice.Ports) > 0 && slice.Ports[0].Port != nil {
+		return *slice.Ports[0].Port
+	}
+	return DefaultFunctionsListeningPort
+}
@@ -0,0 +1,186 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package faas_kubernetes
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"scheduler/config"
+	"scheduler/log"
+	"scheduler/types"
+	"strings"
+	"time"
+)
+
+const DefaultFunctionsListeningPort = 8080
+const FunctionPortName = "http"
+
+// FunctionLabel is the label which marks the deployments managed as functions, its value is the name of the function
+const FunctionLabel = "p2pfaas/function"
+
+// EndpointSliceServiceLabel is the label which Kubernetes sets on the endpoint slices of a service
+const EndpointSliceServiceLabel = "kubernetes.io/service-name"
+
+const contentTypeJson = "application/json"
+const contentTypeMergePatch = "application/merge-patch+json"
+
+// NewApiHttpClient returns a client of the api server which trusts the ca of the service account
+func NewApiHttpClient() *http.Client {
+	tlsConfig := &tls.Config{}
+	// the ca is available only when running in a pod
+	if ca, err := ioutil.ReadFile(config.KubernetesServiceAccountPath + "/ca.crt"); err == nil {
+		pool := x509.NewCertPool()
+		pool.AppendCertsFromPEM(ca)
+		tlsConfig.RootCAs = pool
+	}
+
+	return &http.Client{
+		Timeout: 30 * time.Second,
+		Transport: &http.Transport{
+			TLSClientConfig:     tlsConfig,
+			MaxIdleConnsPerHost: 8,
+			DialContext: (&net.Dialer{
+				Timeout:   30 * time.Second,
+				KeepAlive: 120 * time.Second,
+			}).DialContext,
+		},
+	}
+}
+
+func (b *Backend) getApiHttpClient() *http.Client {
+	b.clientOnce.Do(func() {
+		if b.client == nil {
+			b.client = NewApiHttpClient()
+		}
+	})
+	return b.client
+}
+
+func (b *Backend) getApiUrl() string {
+	if b.apiUrl != "" {
+		return b.apiUrl
+	}
+	return config.GetKubernetesApiUrl()
+}
+
+/*
+ * APIs
+ */
+
+func (b *Backend) GetApiDeploymentsUrl() string {
+	return fmt.Sprintf("%s/apis/apps/v1/namespaces/%s/deployments", b.getApiUrl(), config.GetKubernetesNamespace())
+}
+
+func (b *Backend) GetApiDeploymentUrl(functionName string) string {
+	return fmt.Sprintf("%s/%s", b.GetApiDeploymentsUrl(), functionName)
+}
+
+func (b *Backend) GetApiDeploymentScaleUrl(functionName string) string {
+	return fmt.Sprintf("%s/scale", b.GetApiDeploymentUrl(functionName))
+}
+
+func (b *Backend) GetApiServicesUrl() string {
+	return fmt.Sprintf("%s/api/v1/namespaces/%s/services", b.getApiUrl(), config.GetKubernetesNamespace())
+}
+
+func (b *Backend) GetApiServiceUrl(functionName string) string {
+	return fmt.Sprintf("%s/%s", b.GetApiServicesUrl(), functionName)
+}
+
+// GetApiEndpointSlicesUrl returns the url of the endpoint slices of the service of the function
+func (b *Backend) GetApiEndpointSlicesUrl(functionName string) string {
+	return fmt.Sprintf("%s/apis/discovery.k8s.io/v1/namespaces/%s/endpointslices?labelSelector=%s", b.getApiUrl(),
+		config.GetKubernetesNamespace(), url.QueryEscape(EndpointSliceServiceLabel+"="+functionName))
+}
+
+func (b *Backend) GetApiLeasesUrl() string {
+	return fmt.Sprintf("%s/apis/coordination.k8s.io/v1/namespaces/%s/leases", b.getApiUrl(), config.GetKubernetesNamespace())
+}
+
+func (b *Backend) GetApiLeaseUrl(leaseName string) string {
+	return fmt.Sprintf("%s/%s", b.GetApiLeasesUrl(), leaseName)
+}
+
+func GetApiFunctionsSelector() string {
+	return "labelSelector=" + url.QueryEscape(FunctionLabel)
+}
+
+/*
+ * Http utils
+ */
+
+// setAuthHeader sets the token of the service account, it is read at every request since Kubernetes rotates it
+func setAuthHeader(req *http.Request) {
+	token, err := ioutil.ReadFile(config.KubernetesServiceAccountPath + "/token")
+	if err != nil {
+		return
+	}
+	req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+}
+
+// apiCall performs a request to the api server, the body of the response is read
+func (b *Backend) apiCall(method string, url string, payload []byte, contentType string) (*types.FaasApiResponse, error) {
+	req, err := http.NewRequest(method, url, bytes.NewBuffer(payload))
+	if err != nil {
+		return nil, ErrorHttpCannotCreateRequest{}
+	}
+
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Accept", contentTypeJson)
+	setAuthHeader(req)
+
+	res, err := b.getApiHttpClient().Do(req)
+	if err != nil {
+		log.Log.Debugf("cannot %s to %s: %s", method, url, err.Error())
+		return nil, err
+	}
+
+	body, _ := ioutil.ReadAll(res.Body)
+	_ = res.Body.Close()
+
+	response := types.FaasApiResponse{
+		Headers:    res.Header,
+		Body:       body,
+		StatusCode: res.StatusCode,
+	}
+
+	return &response, nil
+}
+
+// checkApiResponse returns the error which corresponds to the status code of the response
+func checkApiResponse(res *types.FaasApiResponse) error {
+	if res.StatusCode == 404 {
+		return ErrorFunctionNotFound{}
+	}
+	if res.StatusCode >= 500 {
+		return ErrorInternal{string(res.Body)}
+	}
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return ErrorGeneric{string(res.Body)}
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package faas_kubernetes
+
+import (
+	"encoding/json"
+	"os"
+	"scheduler/config"
+	"scheduler/log"
+	"time"
+)
+
+// ScalingLeaseName is the name of the lease held by the scheduler which scales the functions, since all the schedulers
+// of the DaemonSet see the same deployments
+const ScalingLeaseName = "p2pfaas-scaling"
+
+// ScalingLeaseDuration is how long the lease is valid without being renewed
+const ScalingLeaseDuration = 15 * time.Second
+
+// ScalingLeaseRenewInterval is how often the lease is acquired or renewed, the last outcome is used in between
+const ScalingLeaseRenewInterval = 5 * time.Second
+
+const leaseTimeFormat = "2006-01-02T15:04:05.000000Z07:00"
+
+// IsScalingLeader tells if this scheduler holds the scaling lease, acquiring or renewing it when needed. When the api
+// server cannot be reached the scheduler is not the leader, so that two schedulers never scale at the same time.
+func (b *Backend) IsScalingLeader() bool {
+	b.scalingLeaseMutex.Lock()
+	defer b.scalingLeaseMutex.Unlock()
+
+	if time.Since(b.scalingLeaseCheckedAt) < ScalingLeaseRenewInterval {
+		return b.scalingLeaseHeld
+	}
+
+	held, err := b.acquireScalingLease(getLeaseIdentity(), time.Now())
+	if err != nil {
+		log.Log.Warningf("Cannot acquire the scaling lease: %s", err)
+	}
+	if held != b.scalingLeaseHeld {
+		log.Log.Infof("Scaling lease held: %t", held)
+	}
+	b.scalingLeaseHeld = held
+	b.scalingLeaseCheckedAt = time.Now()
+
+	return b.scalingLeaseHeld
+}
+
+// acquireScalingLease creates the lease or takes it if it is ours or it expired. Concurrent updates are rejected by the
+// api server thanks to the resource version.
+func (b *Backend) acquireScalingLease(identity string, now time.Time) (bool, error) {
+	res, err := b.apiCall("GET", b.GetApiLeaseUrl(ScalingLeaseName), nil, "")
+	if err != nil {
+		return false, err
+	}
+
+	if res.StatusCode == 404 {
+		lease := Lease{
+			ApiVersion: "coordination.k8s.io/v1",
+			Kind:       "Lease",
+			Metadata:   ObjectMeta{Name: ScalingLeaseName},
+			Spec:       newLeaseSpec(identity, now, now, 0),
+		}
+		leaseJson, _ := json.Marshal(lease)
+		res, err = b.apiCall("POST", b.GetApiLeasesUrl(), leaseJson, contentTypeJson)
+		if err != nil {
+			return false, err
+		}
+		// another scheduler created it first
+		if res.StatusCode == 409 {
+			return false, nil
+		}
+		if err = checkApiResponse(res); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+	if err = checkApiResponse(res); err != nil {
+		return false, err
+	}
+
+	var lease Lease
+	err = json.Unmarshal(res.Body, &lease)
+	if err != nil {
+		return false, err
+	}
+
+	acquireTime := lease.Spec.AcquireTime
+	transitions := lease.Spec.LeaseTransitions
+	if lease.Spec.HolderIdentity != identity {
+		if !isLeaseExpired(lease.Spec, now) {
+			return false, nil
+		}
+		acquireTime = now.UTC().Format(leaseTimeFormat)
+		transitions += 1
+	}
+
+	lease.Spec = newLeaseSpec(identity, now, now, transitions)
+	lease.Spec.AcquireTime = acquireTime
+	leaseJson, _ := json.Marshal(lease)
+	res, err = b.apiCall("PUT", b.GetApiLeaseUrl(ScalingLeaseName), leaseJson, contentTypeJson)
+	if err != nil {
+		return false, err
+	}
+	// the lease has been updated by another scheduler in the meantime
+	if res.StatusCode == 409 {
+		return false, nil
+	}
+	if err = checkApiResponse(res); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func newLeaseSpec(identity string, acquireTime time.Time, renewTime time.Time, transitions int) LeaseSpec {
+	return LeaseSpec{
+		HolderIdentity:       identity,
+		LeaseDurationSeconds: int(ScalingLeaseDuration.Seconds()),
+		AcquireTime:          acquireTime.UTC().Format(leaseTimeFormat),
+		RenewTime:            renewTime.UTC().Format(leaseTimeFormat),
+		LeaseTransitions:     transitions,
+	}
+}
+
+// isLeaseExpired tells if the holder did not renew the lease within its duration, leases which cannot be parsed are
+// considered expired
+func isLeaseExpired(spec LeaseSpec, now time.Time) bool {
+	if spec.HolderIdentity == "" {
+		return true
+	}
+	renewTime, err := time.Parse(time.RFC3339Nano, spec.RenewTime)
+	if err != nil {
+		return true
+	}
+	return now.After(renewTime.Add(time.Duration(spec.LeaseDurationSeconds) * time.Second))
+}
+
+// getLeaseIdentity returns the identity of the scheduler in the lease, which is the node since schedulers are a
+// DaemonSet
+func getLeaseIdentity() string {
+	if nodeName := config.GetKubernetesNodeName(); nodeName != "" {
+		return nodeName
+	}
+	hostname, _ := os.Hostname()
+	return hostname
+}
@@ -0,0 +1,370 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package faas_kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"scheduler/errors"
+	"scheduler/log"
+	"scheduler/types"
+	"scheduler/utils"
+	"sort"
+)
+
+// FunctionExecute executes the passed function on one of its endpoints, the call is aborted when the execution timeout
+// of the function expires
+func (b *Backend) FunctionExecute(ctx context.Context, functionName string, payload []byte, contentType string) (*types.FaasApiResponse, error) {
+	functionUrl, err := b.GetFunctionUrl(functionName)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel, timeout := utils.FunctionTimeoutContext(ctx, functionName)
+	defer cancel()
+
+	res, err := functionExecuteApiCall(ctx, functionUrl, payload, contentType)
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, errors.ErrorFaasExecutionTimeout{Function: functionName, Timeout: timeout}
+	}
+	if err != nil {
+		// the pod could have been moved or removed
+		b.invalidateFunctionEndpoints(functionName)
+		return nil, err
+	}
+
+	if res.StatusCode >= 500 {
+		return res, ErrorInternal{string(res.Body)}
+	}
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return res, ErrorGeneric{string(res.Body)}
+	}
+	return res, nil
+}
+
+func (b *Backend) FunctionsGet() ([]types.FaasFunction, *types.FaasApiResponse, error) {
+	res, err := b.apiCall("GET", b.GetApiDeploymentsUrl()+"?"+GetApiFunctionsSelector(), nil, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = checkApiResponse(res); err != nil {
+		return nil, res, err
+	}
+
+	var deployments DeploymentList
+	err = json.Unmarshal(res.Body, &deployments)
+	if err != nil {
+		log.Log.Debugf("Cannot decode deployments: %s", err.Error())
+		return nil, res, errors.ErrorJSONDecoding{}
+	}
+
+	functions := []types.FaasFunction{}
+	for _, deployment := range deployments.Items {
+		functions = append(functions, deploymentToFunction(&deployment))
+	}
+	return functions, res, nil
+}
+
+func (b *Backend) FunctionGet(functionName string) (*types.FaasFunction, *types.FaasApiResponse, error) {
+	res, err := b.apiCall("GET", b.GetApiDeploymentUrl(functionName), nil, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = checkApiResponse(res); err != nil {
+		return nil, res, err
+	}
+
+	var deployment Deployment
+	err = json.Unmarshal(res.Body, &deployment)
+	if err != nil {
+		log.Log.Debugf("Cannot decode deployment %s: %s", functionName, err.Error())
+		return nil, res, errors.ErrorJSONDecoding{}
+	}
+	// deployments not created as functions are not exposed
+	if _, isFunction := deployment.Metadata.Labels[FunctionLabel]; !isFunction {
+		return nil, res, ErrorFunctionNotFound{}
+	}
+
+	function := deploymentToFunction(&deployment)
+	return &function, res, nil
+}
+
+// FunctionDeploy creates the deployment and the service of the function, an already existing deployment or service is
+// reused since all the schedulers deploy the same function in a cluster-wide deploy. If the service cannot be created
+// the deployment created by this call is removed, so that the deploy can be retried.
+func (b *Backend) FunctionDeploy(function types.FaasFunction) (*types.FaasApiResponse, error) {
+	functionName := getFunctionName(function)
+
+	deploymentJson, _ := json.Marshal(functionToDeployment(function))
+	res, err := b.apiCall("POST", b.GetApiDeploymentsUrl(), deploymentJson, contentTypeJson)
+	if err != nil {
+		return nil, err
+	}
+	deploymentExists := res.StatusCode == 409
+	if deploymentExists {
+		log.Log.Debugf("Deployment of %s already exists", functionName)
+		res = &types.FaasApiResponse{Headers: res.Headers, Body: res.Body, StatusCode: 200}
+	} else if err = checkApiResponse(res); err != nil {
+		log.Log.Debugf("Cannot create deployment of %s: %s", functionName, err.Error())
+		return res, err
+	}
+
+	serviceJson, _ := json.Marshal(functionToService(functionName))
+	serviceRes, err := b.apiCall("POST", b.GetApiServicesUrl(), serviceJson, contentTypeJson)
+	if err != nil {
+		if !deploymentExists {
+			b.removeDeployment(functionName)
+		}
+		return nil, err
+	}
+	if serviceRes.StatusCode == 409 {
+		return res, nil
+	}
+	if err = checkApiResponse(serviceRes); err != nil {
+		log.Log.Debugf("Cannot create service of %s: %s", functionName, err.Error())
+		if !deploymentExists {
+			b.removeDeployment(functionName)
+		}
+		return serviceRes, err
+	}
+
+	return res, nil
+}
+
+// removeDeployment removes the deployment of a function whose deploy failed
+func (b *Backend) removeDeployment(functionName string) {
+	res, err := b.apiCall("DELETE", b.GetApiDeploymentUrl(functionName)+"?propagationPolicy=Background", nil, "")
+	if err == nil {
+		err = checkApiResponse(res)
+	}
+	if err != nil {
+		log.Log.Errorf("Cannot remove deployment of %s after a failed deploy: %s", functionName, err.Error())
+	}
+}
+
+// FunctionUpdate replaces the container and merges the labels of the deployment of the function
+func (b *Backend) FunctionUpdate(function types.FaasFunction) (*types.FaasApiResponse, error) {
+	deployment := functionToDeployment(function)
+	patch := Deployment{
+		Metadata: ObjectMeta{Labels: deployment.Metadata.Labels},
+		Spec: DeploymentSpec{
+			Template: deployment.Spec.Template,
+		},
+	}
+
+	patchJson, _ := json.Marshal(patch)
+	res, err := b.apiCall("PATCH", b.GetApiDeploymentUrl(getFunctionName(function)), patchJson, contentTypeMergePatch)
+	if err != nil {
+		return nil, err
+	}
+	return res, checkApiResponse(res)
+}
+
+func (b *Backend) FunctionScale(functionName string, replicas uint) (*types.FaasApiResponse, error) {
+	patchJson, _ := json.Marshal(Scale{Spec: ScaleSpec{Replicas: replicas}})
+	res, err := b.apiCall("PATCH", b.GetApiDeploymentScaleUrl(functionName), patchJson, contentTypeMergePatch)
+	if err != nil {
+		return nil, err
+	}
+	return res, checkApiResponse(res)
+}
+
+// FunctionRemove removes the deployment, with its pods, and the service of the function
+func (b *Backend) FunctionRemove(functionName string) (*types.FaasApiResponse, error) {
+	res, err := b.apiCall("DELETE", b.GetApiDeploymentUrl(functionName)+"?propagationPolicy=Background", nil, "")
+	if err != nil {
+		return nil, err
+	}
+	if err = checkApiResponse(res); err != nil {
+		return res, err
+	}
+
+	serviceRes, err := b.apiCall("DELETE", b.GetApiServiceUrl(functionName), nil, "")
+	if err != nil {
+		return nil, err
+	}
+	if serviceRes.StatusCode != 404 {
+		if err = checkApiResponse(serviceRes); err != nil {
+			return serviceRes, err
+		}
+	}
+
+	b.invalidateFunctionEndpoints(functionName)
+	return res, nil
+}
+
+/*
+ * Utils
+ */
+
+func functionExecuteApiCall(ctx context.Context, functionUrl string, payload []byte, contentType string) (*types.FaasApiResponse, error) {
+	var res *http.Response
+	var err error
+
+	if payload == nil {
+		res, err = utils.HttpGetWithContext(ctx, functionUrl)
+	} else {
+		res, err = utils.HttpPostWithContext(ctx, functionUrl, payload, contentType)
+	}
+	if err != nil {
+		log.Log.Debugf("Cannot execute function at %s: %s", functionUrl, err.Error())
+		return nil, err
+	}
+
+	body, _ := ioutil.ReadAll(res.Body)
+	_ = res.Body.Close()
+
+	response := types.FaasApiResponse{
+		Headers:    res.Header,
+		Body:       body,
+		StatusCode: res.StatusCode,
+	}
+
+	return &response, nil
+}
+
+// getFunctionName returns the name of the function to deploy, which is the service in the OpenFaaS format
+func getFunctionName(function types.FaasFunction) string {
+	if function.Service != "" {
+		return function.Service
+	}
+	return function.Name
+}
+
+func functionToDeployment(function types.FaasFunction) Deployment {
+	functionName := getFunctionName(function)
+
+	labels := map[string]string{}
+	for key, value := range function.Labels {
+		labels[key] = value
+	}
+	labels[FunctionLabel] = functionName
+
+	var env []EnvVar
+	for name, value := range function.EnvVars {
+		env = append(env, EnvVar{Name: name, Value: value})
+	}
+	// the process run by the OpenFaaS watchdog
+	if function.EnvProcess != "" {
+		env = append(env, EnvVar{Name: "fprocess", Value: function.EnvProcess})
+	}
+	sort.Slice(env, func(i, j int) bool { return env[i].Name < env[j].Name })
+
+	replicas := function.Replicas
+	if replicas == 0 {
+		replicas = 1
+	}
+
+	return Deployment{
+		ApiVersion: "apps/v1",
+		Kind:       "Deployment",
+		Metadata:   ObjectMeta{Name: functionName, Labels: labels},
+		Spec: DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &LabelSelector{MatchLabels: map[string]string{FunctionLabel: functionName}},
+			Template: PodTemplateSpec{
+				Metadata: ObjectMeta{Labels: labels},
+				Spec: PodSpec{
+					Containers: []Container{{
+						Name:  functionName,
+						Image: function.Image,
+						Env:   env,
+						Ports: []ContainerPort{{Name: FunctionPortName, ContainerPort: DefaultFunctionsListeningPort}},
+						Resources: ResourceRequirements{
+							Limits:   machineResourcesToMap(function.Limits),
+							Requests: machineResourcesToMap(function.Requests),
+						},
+					}},
+				},
+			},
+		},
+	}
+}
+
+func functionToService(functionName string) Service {
+	return Service{
+		ApiVersion: "v1",
+		Kind:       "Service",
+		Metadata:   ObjectMeta{Name: functionName, Labels: map[string]string{FunctionLabel: functionName}},
+		Spec: ServiceSpec{
+			Selector: map[string]string{FunctionLabel: functionName},
+			Ports: []ServicePort{{
+				Name:       FunctionPortName,
+				Port:       DefaultFunctionsListeningPort,
+				TargetPort: DefaultFunctionsListeningPort,
+			}},
+		},
+	}
+}
+
+func deploymentToFunction(deployment *Deployment) types.FaasFunction {
+	function := types.FaasFunction{
+		Name:    deployment.Metadata.Name,
+		Service: deployment.Metadata.Name,
+		Labels:  deployment.Metadata.Labels,
+	}
+	if deployment.Status != nil {
+		function.AvailableReplicas = deployment.Status.AvailableReplicas
+	}
+	if deployment.Spec.Replicas != nil {
+		function.Replicas = *deployment.Spec.Replicas
+	}
+
+	if len(deployment.Spec.Template.Spec.Containers) > 0 {
+		container := deployment.Spec.Template.Spec.Containers[0]
+		function.Image = container.Image
+		function.Limits = mapToMachineResources(container.Resources.Limits)
+		function.Requests = mapToMachineResources(container.Resources.Requests)
+		if len(container.Env) > 0 {
+			function.EnvVars = map[string]string{}
+		}
+		for _, env := range container.Env {
+			if env.Name == "fprocess" {
+				function.EnvProcess = env.Value
+				continue
+			}
+			function.EnvVars[env.Name] = env.Value
+		}
+	}
+
+	return function
+}
+
+func machineResourcesToMap(resources types.FaasMachineResources) map[string]string {
+	out := map[string]string{}
+	if resources.CPU != "" {
+		out["cpu"] = resources.CPU
+	}
+	if resources.Memory != "" {
+		out["memory"] = resources.Memory
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+func mapToMachineResources(resources map[string]string) types.FaasMachineResources {
+	return types.FaasMachineResources{
+		CPU:    resources["cpu"],
+		Memory: resources["memory"],
+	}
+}
@@ -19,6 +19,8 @@
 package faas_openfaas
 
 import (
+	"context"
+	"io"
 	"io/ioutil"
 	"scheduler/log"
 	"scheduler/types"
@@ -27,8 +29,8 @@ import (
 
 var executeApiCallResponseHeaderDuration = "X-Duration-Seconds"
 
-func functionExecuteApiCall(host string, functionName string) (*types.FaasApiResponse, error) {
-	res, err := HttpGet(GetApiFunctionUrl(host, functionName))
+func functionExecuteApiCall(ctx context.Context, host string, functionName string) (*types.FaasApiResponse, error) {
+	res, err := HttpGetWithContext(ctx, GetApiFunctionUrl(host, functionName))
 	if err != nil {
 		log.Log.Debugf("Cannot create GET request to %s", err.Error(), GetApiFunctionUrl(host, functionName))
 		return nil, err
@@ -46,8 +48,8 @@ func functionExecuteApiCall(host string, functionName string) (*types.FaasApiRes
 	return &response, err
 }
 
-func functionExecutePostApiCall(host string, functionName string, payload []byte, contentType string) (*types.FaasApiResponse, error) {
-	res, err := HttpPost(GetApiFunctionUrl(host, functionName), payload, contentType)
+func functionExecutePostApiCall(ctx context.Context, host string, functionName string, payload []byte, contentType string) (*types.FaasApiResponse, error) {
+	res, err := HttpPost(ctx, GetApiFunctionUrl(host, functionName), payload, contentType)
 	if err != nil {
 		log.Log.Debugf("Cannot create POST request to %s", err.Error(), GetApiFunctionUrl(host, functionName))
 		return nil, err
@@ -65,6 +67,24 @@ func functionExecutePostApiCall(host string, functionName string, payload []byte
 	return &response, err
 }
 
+// functionExecuteStreamApiCall executes the function without reading the body of the response, which is returned in
+// BodyStream
+func functionExecuteStreamApiCall(ctx context.Context, host string, functionName string, payload io.Reader, contentType string) (*types.FaasApiResponse, error) {
+	res, err := HttpPostStream(ctx, GetApiFunctionUrl(host, functionName), payload, contentType)
+	if err != nil {
+		log.Log.Debugf("Cannot create POST request to %s: %s", GetApiFunctionUrl(host, functionName), err.Error())
+		return nil, err
+	}
+
+	response := types.FaasApiResponse{
+		Headers:    res.Header,
+		BodyStream: res.Body,
+		StatusCode: res.StatusCode,
+	}
+
+	return &response, err
+}
+
 func GetDurationFromExecuteApiCallResponse(res *types.FaasApiResponse) float64 {
 	if res == nil || res.Headers == nil {
 		log.Log.Errorf("Response or Response.Headers are nil")
@@ -21,8 +21,10 @@ package faas_openfaas
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"scheduler/config"
@@ -106,8 +108,49 @@ func HttpPostJSON(url string, json string) (*http.Response, error) {
 	return res, err
 }
 
+func HttpPutJSON(url string, json string) (*http.Response, error) {
+	req, err := http.NewRequest("PUT", url, bytes.NewBufferString(json))
+	if err != nil {
+		return nil, ErrorHttpCannotCreateRequest{}
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	SetAuthHeader(req)
+
+	client := &http.Client{Transport: httpTransport}
+	res, err := client.Do(req)
+	if err != nil {
+		log.Log.Debugf("cannot PUT to %s: %s", url, err.Error())
+	}
+
+	return res, err
+}
+
+func HttpDeleteJSON(url string, json string) (*http.Response, error) {
+	req, err := http.NewRequest("DELETE", url, bytes.NewBufferString(json))
+	if err != nil {
+		return nil, ErrorHttpCannotCreateRequest{}
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	SetAuthHeader(req)
+
+	client := &http.Client{Transport: httpTransport}
+	res, err := client.Do(req)
+	if err != nil {
+		log.Log.Debugf("cannot DELETE to %s: %s", url, err.Error())
+	}
+
+	return res, err
+}
+
 func HttpGet(url string) (*http.Response, error) {
-	req, err := http.NewRequest("GET", url, nil)
+	return HttpGetWithContext(context.Background(), url)
+}
+
+// HttpGetWithContext gets the passed url, the request is aborted when the context is done
+func HttpGetWithContext(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, ErrorHttpCannotCreateRequest{}
 	}
@@ -123,8 +166,32 @@ func HttpGet(url string) (*http.Response, error) {
 	return res, err
 }
 
-func HttpPost(url string, payload []byte, contentType string) (*http.Response, error) {
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(payload))
+// HttpPost posts the payload, the request is aborted when the context is done
+func HttpPost(ctx context.Context, url string, payload []byte, contentType string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(payload))
+	if err != nil {
+		return nil, ErrorHttpCannotCreateRequest{}
+	}
+
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	SetAuthHeader(req)
+
+	client := &http.Client{Transport: httpTransport}
+	res, err := client.Do(req)
+	if err != nil {
+		log.Log.Debugf("cannot POST to %s: %s", url, err.Error())
+	}
+
+	return res, err
+}
+
+// HttpPostStream posts the payload read from the passed reader, the body of the response is not read. The request,
+// including the reading of the body, is aborted when the context is done
+func HttpPostStream(ctx context.Context, url string, payload io.Reader, contentType string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", url, payload)
 	if err != nil {
 		return nil, ErrorHttpCannotCreateRequest{}
 	}
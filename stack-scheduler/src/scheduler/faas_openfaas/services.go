@@ -19,6 +19,8 @@
 package faas_openfaas
 
 import (
+	"context"
+	"io"
 	"scheduler/config"
 	"scheduler/types"
 )
@@ -35,8 +37,20 @@ func FunctionDeploy(function Function) (*types.FaasApiResponse, error) {
 	return GenFunctionDeploy(config.GetOpenFaasListeningHost(), function)
 }
 
-func FunctionExecute(functionName string, payload []byte, contentType string) (*types.FaasApiResponse, error) {
-	return GenFunctionExecute(config.GetOpenFaasListeningHost(), functionName, payload, contentType)
+func FunctionUpdate(function Function) (*types.FaasApiResponse, error) {
+	return GenFunctionUpdate(config.GetOpenFaasListeningHost(), function)
+}
+
+func FunctionExecute(ctx context.Context, functionName string, payload []byte, contentType string) (*types.FaasApiResponse, error) {
+	return GenFunctionExecute(ctx, config.GetOpenFaasListeningHost(), functionName, payload, contentType)
+}
+
+func FunctionExecuteStream(ctx context.Context, functionName string, payload io.Reader, contentType string) (*types.FaasApiResponse, error) {
+	return GenFunctionExecuteStream(ctx, config.GetOpenFaasListeningHost(), functionName, payload, contentType)
+}
+
+func FunctionRemove(functionName string) (*types.FaasApiResponse, error) {
+	return GenFunctionRemove(config.GetOpenFaasListeningHost(), functionName)
 }
 
 func FunctionScale(functionName string, replicas uint) (*types.FaasApiResponse, error) {
@@ -19,9 +19,15 @@
 package faas_openfaas
 
 import (
+	"context"
 	"encoding/json"
+	"io"
+	"io/ioutil"
+	"scheduler/errors"
 	"scheduler/log"
 	"scheduler/types"
+	"scheduler/utils"
+	"time"
 )
 
 func GenFunctionsGet(host string) ([]Function, *types.FaasApiResponse, error) {
@@ -66,16 +72,121 @@ func GenFunctionDeploy(host string, function Function) (*types.FaasApiResponse,
 	return res, err
 }
 
-func GenFunctionExecute(host string, functionName string, payload []byte, contentType string) (*types.FaasApiResponse, error) {
+func GenFunctionUpdate(host string, function Function) (*types.FaasApiResponse, error) {
+	res, err := functionUpdateApiCall(host, function)
+	if err != nil {
+		log.Log.Debugf("Cannot update service %s: %s", function.Service, err.Error())
+		return nil, err
+	}
+	if res.StatusCode == 404 {
+		return res, ErrorFunctionNotFound{}
+	}
+	if res.StatusCode >= 500 {
+		return res, ErrorInternal{string(res.Body)}
+	}
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return res, ErrorGeneric{string(res.Body)}
+	}
+	return res, nil
+}
+
+// GenFunctionExecute executes the function, the call is aborted when the execution timeout of the function expires
+func GenFunctionExecute(ctx context.Context, host string, functionName string, payload []byte, contentType string) (*types.FaasApiResponse, error) {
+	ctx, cancel, timeout := utils.FunctionTimeoutContext(ctx, functionName)
+	defer cancel()
+
 	var res *types.FaasApiResponse
 	var err error
 
 	if payload == nil {
-		res, err = functionExecuteApiCall(host, functionName)
+		res, err = functionExecuteApiCall(ctx, host, functionName)
 	} else {
-		res, err = functionExecutePostApiCall(host, functionName, payload, contentType)
+		res, err = functionExecutePostApiCall(ctx, host, functionName, payload, contentType)
+	}
+
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, errors.ErrorFaasExecutionTimeout{Function: functionName, Timeout: timeout}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	executionTime := GetDurationFromExecuteApiCallResponse(res)
+	res.ExecutionTime = &executionTime
+
+	if res.StatusCode == 404 {
+		return res, ErrorFunctionNotFound{}
+	}
+	if res.StatusCode >= 500 {
+		return res, ErrorInternal{string(res.Body)}
 	}
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return res, ErrorGeneric{string(res.Body)}
+	}
+	return res, nil
+}
+
+// GenFunctionExecuteStream executes the function streaming the payload and the response, when the execution fails the
+// body is read and closed so that the returned error is the same of GenFunctionExecute. The execution timeout of the
+// function covers also the reading of the returned body.
+func GenFunctionExecuteStream(ctx context.Context, host string, functionName string, payload io.Reader, contentType string) (*types.FaasApiResponse, error) {
+	ctx, cancel, timeout := utils.FunctionTimeoutContext(ctx, functionName)
+
+	res, err := functionExecuteStreamApiCall(ctx, host, functionName, payload, contentType)
+	if ctx.Err() == context.DeadlineExceeded {
+		cancel()
+		return nil, errors.ErrorFaasExecutionTimeout{Function: functionName, Timeout: timeout}
+	}
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	executionTime := GetDurationFromExecuteApiCallResponse(res)
+	res.ExecutionTime = &executionTime
+
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		body := &timeoutReadCloser{ReadCloser: res.BodyStream, ctx: ctx, functionName: functionName, timeout: timeout}
+		res.BodyStream = utils.NewReadCloserWithCallback(body, cancel)
+		return res, nil
+	}
+
+	res.Body, _ = ioutil.ReadAll(res.BodyStream)
+	_ = res.BodyStream.Close()
+	res.BodyStream = nil
+	cancel()
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, errors.ErrorFaasExecutionTimeout{Function: functionName, Timeout: timeout}
+	}
+
+	if res.StatusCode == 404 {
+		return res, ErrorFunctionNotFound{}
+	}
+	if res.StatusCode >= 500 {
+		return res, ErrorInternal{string(res.Body)}
+	}
+	return res, ErrorGeneric{string(res.Body)}
+}
+
+// timeoutReadCloser returns errors.ErrorFaasExecutionTimeout when the body cannot be read since the execution timeout
+// of the function expired
+type timeoutReadCloser struct {
+	io.ReadCloser
+	ctx          context.Context
+	functionName string
+	timeout      time.Duration
+}
+
+func (r *timeoutReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if err != nil && err != io.EOF && r.ctx.Err() == context.DeadlineExceeded {
+		return n, errors.ErrorFaasExecutionTimeout{Function: r.functionName, Timeout: r.timeout}
+	}
+	return n, err
+}
 
+func GenFunctionRemove(host string, functionName string) (*types.FaasApiResponse, error) {
+	res, err := functionRemoveApiCall(host, functionName)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,100 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package faas_openfaas
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"scheduler/config"
+	"scheduler/errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// startTestOpenFaas starts a fake OpenFaaS gateway which serves the functions with the passed handler and returns
+// its host
+func startTestOpenFaas(t *testing.T, handler http.HandlerFunc) string {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	host, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	t.Setenv(config.EnvOpenFaasListeningPort, port)
+	config.InitConfigurationStatic()
+	config.SetFunctionTimeouts(map[string]uint{"slow": 100})
+	t.Cleanup(func() { config.SetFunctionTimeouts(map[string]uint{}) })
+
+	return host
+}
+
+func TestExecuteStream(t *testing.T) {
+	host := startTestOpenFaas(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(executeApiCallResponseHeaderDuration, strconv.FormatFloat(0.01, 'f', -1, 64))
+		_, _ = w.Write([]byte("done"))
+	})
+
+	res, err := GenFunctionExecuteStream(context.Background(), host, "slow", strings.NewReader(""), "text/plain")
+	if err != nil {
+		t.Fatalf("cannot execute: %s", err.Error())
+	}
+	body, err := ioutil.ReadAll(res.BodyStream)
+	_ = res.BodyStream.Close()
+	if err != nil || string(body) != "done" {
+		t.Fatalf("expected the body done, got %q, %v", body, err)
+	}
+}
+
+func TestExecuteStreamTimeoutWhileReadingBody(t *testing.T) {
+	host := startTestOpenFaas(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("partial"))
+		w.(http.Flusher).Flush()
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+	})
+
+	res, err := GenFunctionExecuteStream(context.Background(), host, "slow", strings.NewReader(""), "text/plain")
+	if err != nil {
+		t.Fatalf("cannot execute: %s", err.Error())
+	}
+	_, err = ioutil.ReadAll(res.BodyStream)
+	_ = res.BodyStream.Close()
+	if _, ok := err.(errors.ErrorFaasExecutionTimeout); !ok {
+		t.Fatalf("expected a timeout error, got %v", err)
+	}
+}
+
+func TestExecuteStreamTimeoutBeforeHeaders(t *testing.T) {
+	host := startTestOpenFaas(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+	})
+
+	_, err := GenFunctionExecuteStream(context.Background(), host, "slow", strings.NewReader(""), "text/plain")
+	if _, ok := err.(errors.ErrorFaasExecutionTimeout); !ok {
+		t.Fatalf("expected a timeout error, got %v", err)
+	}
+}
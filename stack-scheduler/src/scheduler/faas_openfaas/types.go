@@ -18,10 +18,16 @@
 
 package faas_openfaas
 
-type Service struct {
-	OpenFaaSFunction Function `json:"openfaas_service,omitempty" bson:"openfaas_service"`
-	Deadline         uint64   `json:"deadline,omitempty" bson:"deadline"`
-}
+import "scheduler/types"
+
+// Service, Function, MachineResources, FunctionScalePayload and FunctionRemovePayload are shared by all faas backends, we keep the names
+// used by OpenFaaS in this package
+
+type Service = types.FaasService
+type Function = types.FaasFunction
+type MachineResources = types.FaasMachineResources
+type FunctionScalePayload = types.FaasFunctionScalePayload
+type FunctionRemovePayload = types.FaasFunctionRemovePayload
 
 type CurrentLoad struct {
 	NumberOfServices       uint `json:"total_services" bson:"total_services"`
@@ -29,11 +35,6 @@ type CurrentLoad struct {
 	TotalAvailableReplicas uint `json:"total_available_replicas" bson:"total_available_replicas"`
 }
 
-type MachineResources struct {
-	Memory string `json:"memory,omitempty" bson:"memory"`
-	CPU    string `json:"cpu,omitempty" bson:"cpu"`
-}
-
 /*
 {
   "service": "nodeinfo",
@@ -69,31 +70,6 @@ type MachineResources struct {
 }
 */
 
-type Function struct {
-	Name         string            `json:"name,omitempty" bson:"name"`
-	Service      string            `json:"service,omitempty" bson:"service"`
-	Network      string            `json:"network,omitempty" bson:"network"`
-	Image        string            `json:"image,omitempty" bson:"image"`
-	EnvProcess   string            `json:"envProcess,omitempty" bson:"envProcess"`
-	EnvVars      map[string]string `json:"envVars,omitempty" bson:"envVars"`
-	Constraints  []string          `json:"constraints,omitempty" bson:"constraints"`
-	Labels       map[string]string `json:"labels,omitempty" bson:"labels"`
-	Annotations  []string          `json:"annotations,omitempty" bson:"annotations"`
-	Secrets      []string          `json:"secrets,omitempty" bson:"secrets"`
-	RegistryAuth string            `json:"registryAuth,omitempty" bson:"registryAuth"`
-	Limits       MachineResources  `json:"limits,omitempty" bson:"limits"`
-	Requests     MachineResources  `json:"requests,omitempty" bson:"requests"`
-
-	InvocationCount   uint `json:"invocationCount,omitempty" bson:"invocationCount"`
-	Replicas          uint `json:"replicas,omitempty" bson:"replicas"`
-	AvailableReplicas uint `json:"availableReplicas,omitempty" bson:"availableReplicas"`
-}
-
-type FunctionScalePayload struct {
-	Service  string `json:"service,omitempty" bson:"service"`
-	Replicas uint   `json:"replicas,omitempty" bson:"replicas"`
-}
-
 /*
 type APIResponse struct {
 	Headers    http.Header
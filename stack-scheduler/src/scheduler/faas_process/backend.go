@@ -0,0 +1,64 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package faas_process
+
+import (
+	"context"
+	"scheduler/errors"
+	"scheduler/types"
+)
+
+const BackendName = "process"
+
+// Backend implements the faas backend in which every function is a local process described in the manifest. Functions
+// are managed by editing the manifest, so deploy, scale and remove are not supported.
+type Backend struct{}
+
+func (Backend) GetName() string {
+	return BackendName
+}
+
+func (Backend) FunctionExecute(ctx context.Context, functionName string, payload []byte, contentType string) (*types.FaasApiResponse, error) {
+	return FunctionExecute(ctx, functionName, payload, contentType)
+}
+
+func (Backend) FunctionsGet() ([]types.FaasFunction, *types.FaasApiResponse, error) {
+	return FunctionsGet(), nil, nil
+}
+
+func (Backend) FunctionGet(functionName string) (*types.FaasFunction, *types.FaasApiResponse, error) {
+	function, err := FunctionGet(functionName)
+	return function, nil, err
+}
+
+func (Backend) FunctionDeploy(function types.FaasFunction) (*types.FaasApiResponse, error) {
+	return nil, errors.ErrorFaasOperationNotSupported{Backend: BackendName, Operation: "deploy"}
+}
+
+func (Backend) FunctionUpdate(function types.FaasFunction) (*types.FaasApiResponse, error) {
+	return nil, errors.ErrorFaasOperationNotSupported{Backend: BackendName, Operation: "update"}
+}
+
+func (Backend) FunctionScale(functionName string, replicas uint) (*types.FaasApiResponse, error) {
+	return nil, errors.ErrorFaasOperationNotSupported{Backend: BackendName, Operation: "scale"}
+}
+
+func (Backend) FunctionRemove(functionName string) (*types.FaasApiResponse, error) {
+	return nil, errors.ErrorFaasOperationNotSupported{Backend: BackendName, Operation: "remove"}
+}
@@ -0,0 +1,49 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package faas_process
+
+import "fmt"
+
+type ErrorFunctionNotFound struct{}
+
+func (ErrorFunctionNotFound) Error() string {
+	return "Function not found"
+}
+
+type ErrorInternal struct {
+	ResponseBody string
+}
+
+func (e ErrorInternal) Error() string {
+	return fmt.Sprintf("Internal error: %s", e.ResponseBody)
+}
+
+type ErrorInvalidManifest struct {
+	Reason string
+}
+
+func (e ErrorInvalidManifest) Error() string {
+	return fmt.Sprintf("Invalid function manifest: %s", e.Reason)
+}
+
+type ErrorPoolStopped struct{}
+
+func (ErrorPoolStopped) Error() string {
+	return "Process pool has been stopped"
+}
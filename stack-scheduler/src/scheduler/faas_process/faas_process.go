@@ -0,0 +1,85 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package faas_process implements a faas execution logic in which functions are run as local processes, without any
+// container runtime. Functions are described in a manifest file and they can run in two modes:
+//
+//   - watchdog: a process is started for every call, the payload is written to stdin and the result is read from
+//     stdout, as the classic OpenFaaS watchdog does
+//   - http: a pool of long-lived processes is pre-forked for the function, every process listens on the port passed
+//     in the PORT environment variable and calls are forwarded to an idle process of the pool
+package faas_process
+
+import (
+	"scheduler/config"
+	"scheduler/log"
+	"sync"
+)
+
+var functions = make(map[string]*function)
+var functionsMutex sync.RWMutex
+
+func init() {
+	if config.GetFaasBackend() != BackendName {
+		return
+	}
+
+	err := LoadManifest(config.GetProcessManifestPath())
+	if err != nil {
+		log.Log.Errorf("Cannot load process manifest at %s: %s", config.GetProcessManifestPath(), err)
+	}
+}
+
+// LoadManifest loads the manifest at the passed path and replaces all the functions, the pools of the previous
+// functions are stopped
+func LoadManifest(path string) error {
+	manifest, err := readManifest(path)
+	if err != nil {
+		return err
+	}
+
+	newFunctions := make(map[string]*function)
+	for name, fnManifest := range manifest.Functions {
+		fn, err := newFunction(name, fnManifest)
+		if err != nil {
+			log.Log.Errorf("Cannot prepare function %s from manifest: %s", name, err)
+			continue
+		}
+		newFunctions[name] = fn
+	}
+
+	functionsMutex.Lock()
+	oldFunctions := functions
+	functions = newFunctions
+	functionsMutex.Unlock()
+
+	for _, fn := range oldFunctions {
+		fn.stop()
+	}
+
+	log.Log.Infof("Loaded %d functions from process manifest %s", len(newFunctions), path)
+
+	return nil
+}
+
+func getFunction(name string) *function {
+	functionsMutex.RLock()
+	defer functionsMutex.RUnlock()
+
+	return functions[name]
+}
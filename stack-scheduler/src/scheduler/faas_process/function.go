@@ -0,0 +1,167 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package faas_process
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"scheduler/config"
+	"scheduler/errors"
+	"scheduler/types"
+	"time"
+)
+
+type function struct {
+	name     string
+	manifest FunctionManifest
+	timeout  time.Duration
+	// slots limits the parallel calls, nil if unlimited
+	slots chan struct{}
+	// pool is the pool of processes in http mode, nil in watchdog mode
+	pool *pool
+}
+
+func newFunction(name string, manifest FunctionManifest) (*function, error) {
+	if len(manifest.Command) == 0 {
+		return nil, ErrorInvalidManifest{"command is empty"}
+	}
+	if manifest.Mode == "" {
+		manifest.Mode = ModeWatchdog
+	}
+	if manifest.Mode != ModeWatchdog && manifest.Mode != ModeHttp {
+		return nil, ErrorInvalidManifest{fmt.Sprintf("mode %s is not valid", manifest.Mode)}
+	}
+	if manifest.ContentType == "" {
+		manifest.ContentType = DefaultContentType
+	}
+
+	timeout, err := manifest.getTimeout()
+	if err != nil {
+		return nil, ErrorInvalidManifest{fmt.Sprintf("timeout %s is not valid", manifest.Timeout)}
+	}
+
+	fn := function{
+		name:     name,
+		manifest: manifest,
+		timeout:  timeout,
+	}
+
+	if manifest.MaxConcurrency > 0 {
+		fn.slots = make(chan struct{}, manifest.MaxConcurrency)
+	}
+
+	if manifest.Mode == ModeHttp {
+		poolSize := manifest.PoolSize
+		if poolSize == 0 {
+			poolSize = DefaultPoolSize
+		}
+		fn.pool = newPool(&fn, poolSize)
+		fn.pool.start()
+	}
+
+	return &fn, nil
+}
+
+// execute runs the function in the configured mode, the process is killed when the context is done
+func (fn *function) execute(ctx context.Context, payload []byte, contentType string) (*types.FaasApiResponse, error) {
+	timeout := fn.getTimeout()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if timeout > 0 {
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeout(ctx, timeout)
+		defer cancelTimeout()
+	}
+
+	if fn.slots != nil {
+		select {
+		case fn.slots <- struct{}{}:
+			defer func() { <-fn.slots }()
+		case <-ctx.Done():
+			return nil, errors.ErrorFaasExecutionTimeout{Function: fn.name, Timeout: timeout}
+		}
+	}
+
+	startTime := time.Now()
+
+	var res *types.FaasApiResponse
+	var err error
+
+	if fn.pool != nil {
+		res, err = fn.pool.execute(ctx, payload, contentType)
+	} else {
+		res, err = watchdogExecute(ctx, fn, payload, contentType)
+	}
+
+	if err == context.DeadlineExceeded || ctx.Err() == context.DeadlineExceeded {
+		return nil, errors.ErrorFaasExecutionTimeout{Function: fn.name, Timeout: timeout}
+	}
+
+	if res != nil {
+		executionTime := time.Since(startTime).Seconds()
+		res.ExecutionTime = &executionTime
+	}
+
+	return res, err
+}
+
+// getTimeout returns the execution timeout, the override in the configuration takes precedence over the manifest
+func (fn *function) getTimeout() time.Duration {
+	if _, exists := config.GetFunctionTimeoutOverride(fn.name); !exists && fn.timeout > 0 {
+		return fn.timeout
+	}
+	return config.GetFunctionTimeoutOf(fn.name)
+}
+
+// environment returns the environment of the process of the function
+func (fn *function) environment() []string {
+	env := os.Environ()
+	for key, value := range fn.manifest.Env {
+		env = append(env, fmt.Sprintf("%s=%s", key, value))
+	}
+	return env
+}
+
+func (fn *function) stop() {
+	if fn.pool != nil {
+		fn.pool.stop()
+	}
+}
+
+// toFaasFunction describes the function in the format shared by all the backends
+func (fn *function) toFaasFunction() types.FaasFunction {
+	replicas := uint(1)
+	availableReplicas := uint(1)
+	if fn.pool != nil {
+		replicas = fn.pool.size
+		availableReplicas = fn.pool.alive()
+	}
+
+	return types.FaasFunction{
+		Name:              fn.name,
+		Service:           fn.name,
+		EnvProcess:        fn.manifest.Command[0],
+		EnvVars:           fn.manifest.Env,
+		Labels:            map[string]string{"mode": fn.manifest.Mode},
+		Replicas:          replicas,
+		AvailableReplicas: availableReplicas,
+	}
+}
@@ -0,0 +1,79 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package faas_process
+
+import (
+	"context"
+	"scheduler/errors"
+	"testing"
+	"time"
+)
+
+func TestWatchdogExecute(t *testing.T) {
+	fn, err := newFunction("echo", FunctionManifest{Command: []string{"cat"}})
+	if err != nil {
+		t.Fatalf("cannot create function: %s", err)
+	}
+
+	res, err := fn.execute(context.Background(), []byte("hello"), "text/plain")
+	if err != nil {
+		t.Fatalf("cannot execute function: %s", err)
+	}
+	if res.StatusCode != 200 || string(res.Body) != "hello" {
+		t.Fatalf("expected the payload back, got %d %q", res.StatusCode, res.Body)
+	}
+}
+
+func TestWatchdogExecuteTimeout(t *testing.T) {
+	fn, err := newFunction("sleep", FunctionManifest{Command: []string{"sleep", "5"}, Timeout: "100ms"})
+	if err != nil {
+		t.Fatalf("cannot create function: %s", err)
+	}
+
+	startTime := time.Now()
+	_, err = fn.execute(context.Background(), nil, "")
+	if _, ok := err.(errors.ErrorFaasExecutionTimeout); !ok {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+	if time.Since(startTime) > 2*time.Second {
+		t.Fatalf("the process has not been killed at the timeout")
+	}
+}
+
+func TestPoolExecuteReturnsWhenStopped(t *testing.T) {
+	// a pool without processes never has an idle one
+	p := newPool(&function{name: "fn"}, 0)
+
+	result := make(chan error, 1)
+	go func() {
+		_, err := p.execute(context.Background(), nil, "")
+		result <- err
+	}()
+
+	p.stop()
+
+	select {
+	case err := <-result:
+		if _, ok := err.(ErrorPoolStopped); !ok {
+			t.Fatalf("expected the pool stopped error, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("the call waiting for a process has not returned after the pool has been stopped")
+	}
+}
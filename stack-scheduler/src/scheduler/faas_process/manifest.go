@@ -0,0 +1,105 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package faas_process
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+)
+
+const ModeWatchdog = "watchdog"
+const ModeHttp = "http"
+
+const DefaultPoolSize = 1
+const DefaultContentType = "text/plain"
+
+// DefaultStartupTimeout is the time a process in http mode has to start listening
+const DefaultStartupTimeout = 10 * time.Second
+
+/*
+ * Sample manifest file
+ *
+ * {
+ *   "functions": {
+ *     "figlet": {
+ *       "mode": "watchdog",
+ *       "command": ["./handler"],
+ *       "working_dir": "/opt/functions/figlet",
+ *       "env": {"LANG": "C"},
+ *       "timeout": "5s",
+ *       "max_concurrency": 2
+ *     },
+ *     "pigo-face-detector": {
+ *       "mode": "http",
+ *       "command": ["./server"],
+ *       "working_dir": "/opt/functions/pigo",
+ *       "timeout": "20s",
+ *       "pool_size": 2
+ *     }
+ *   }
+ * }
+ *
+ */
+
+type Manifest struct {
+	Functions map[string]FunctionManifest `json:"functions"`
+}
+
+type FunctionManifest struct {
+	// Mode is the execution mode, watchdog or http
+	Mode string `json:"mode"`
+	// Command is the executable and its arguments
+	Command []string `json:"command"`
+	// WorkingDir is the directory in which the process is started
+	WorkingDir string `json:"working_dir"`
+	// Env is added to the environment of the process
+	Env map[string]string `json:"env"`
+	// Timeout is the maximum duration of a call, as a duration string like 10s or 500ms
+	Timeout string `json:"timeout"`
+	// MaxConcurrency is the maximum number of parallel calls, 0 means unlimited
+	MaxConcurrency uint `json:"max_concurrency"`
+	// PoolSize is the number of processes pre-forked in http mode
+	PoolSize uint `json:"pool_size"`
+	// ContentType is the content type of the response in watchdog mode
+	ContentType string `json:"content_type"`
+}
+
+func readManifest(path string) (*Manifest, error) {
+	file, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest Manifest
+	err = json.Unmarshal(file, &manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	return &manifest, nil
+}
+
+// getTimeout returns the parsed timeout, 0 if not set
+func (m FunctionManifest) getTimeout() (time.Duration, error) {
+	if m.Timeout == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(m.Timeout)
+}
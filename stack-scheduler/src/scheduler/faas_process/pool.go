@@ -0,0 +1,302 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package faas_process
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"scheduler/log"
+	"scheduler/types"
+	"sync"
+	"time"
+)
+
+// respawnDelay is the time waited before starting again a process that failed to start
+const respawnDelay = 2 * time.Second
+
+var httpClient = &http.Client{}
+
+type worker struct {
+	cmd    *exec.Cmd
+	port   int
+	exited chan struct{}
+	// served is the number of calls served by the process
+	served uint64
+}
+
+func (w *worker) isExited() bool {
+	select {
+	case <-w.exited:
+		return true
+	default:
+		return false
+	}
+}
+
+func (w *worker) url() string {
+	return fmt.Sprintf("http://127.0.0.1:%d/", w.port)
+}
+
+// pool is a set of long-lived processes of a function in http mode, a process serves one call at a time
+type pool struct {
+	function *function
+	size     uint
+	idle     chan *worker
+
+	mutex   sync.Mutex
+	workers map[*worker]bool
+	stopped bool
+	// stopCh is closed when the pool is stopped, so that the calls waiting for an idle process return
+	stopCh chan struct{}
+}
+
+func newPool(fn *function, size uint) *pool {
+	return &pool{
+		function: fn,
+		size:     size,
+		idle:     make(chan *worker, size),
+		workers:  make(map[*worker]bool),
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// start pre-forks all the processes of the pool
+func (p *pool) start() {
+	for i := uint(0); i < p.size; i++ {
+		go p.spawn()
+	}
+}
+
+// stop kills all the processes of the pool, they are not started again
+func (p *pool) stop() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if !p.stopped {
+		p.stopped = true
+		close(p.stopCh)
+	}
+	for w := range p.workers {
+		_ = w.cmd.Process.Kill()
+	}
+}
+
+// alive returns the number of running processes
+func (p *pool) alive() uint {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return uint(len(p.workers))
+}
+
+// spawn starts a new process and adds it to the idle ones when it is listening, it retries until it succeeds or the
+// pool is stopped
+func (p *pool) spawn() {
+	for {
+		p.mutex.Lock()
+		stopped := p.stopped
+		p.mutex.Unlock()
+		if stopped {
+			return
+		}
+
+		w, err := p.startWorker()
+		if err == nil {
+			p.idle <- w
+			return
+		}
+		if _, ok := err.(ErrorPoolStopped); ok {
+			return
+		}
+
+		log.Log.Errorf("Cannot start process for function %s: %s", p.function.name, err)
+		time.Sleep(respawnDelay)
+	}
+}
+
+func (p *pool) startWorker() (*worker, error) {
+	port, err := getFreePort()
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := p.function.manifest
+	cmd := exec.Command(manifest.Command[0], manifest.Command[1:]...)
+	cmd.Dir = manifest.WorkingDir
+	cmd.Env = append(p.function.environment(), fmt.Sprintf("PORT=%d", port))
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	exited, err := startProcess(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	w := worker{
+		cmd:    cmd,
+		port:   port,
+		exited: make(chan struct{}),
+	}
+
+	// the pool may have been stopped while the process was starting, then it would not be killed by stop
+	p.mutex.Lock()
+	if p.stopped {
+		p.mutex.Unlock()
+		_ = cmd.Process.Kill()
+		return nil, ErrorPoolStopped{}
+	}
+	p.workers[&w] = true
+	p.mutex.Unlock()
+
+	go func() {
+		err := <-exited
+		log.Log.Debugf("Process %d of function %s exited: %v", cmd.Process.Pid, p.function.name, err)
+
+		p.mutex.Lock()
+		delete(p.workers, &w)
+		p.mutex.Unlock()
+
+		close(w.exited)
+	}()
+
+	err = waitListening(&w, DefaultStartupTimeout)
+	if err != nil {
+		_ = cmd.Process.Kill()
+		return nil, err
+	}
+
+	log.Log.Debugf("Started process %d of function %s on port %d", cmd.Process.Pid, p.function.name, port)
+
+	return &w, nil
+}
+
+// execute forwards the call to an idle process, processes which exited are started again
+func (p *pool) execute(ctx context.Context, payload []byte, contentType string) (*types.FaasApiResponse, error) {
+	var w *worker
+	for w == nil {
+		select {
+		case w = <-p.idle:
+			if w.isExited() {
+				w = nil
+				go p.spawn()
+			}
+		case <-p.stopCh:
+			return nil, ErrorPoolStopped{}
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	res, err := workerExecute(ctx, w, payload, contentType)
+	if res != nil {
+		// the first call served by a process pays its warm up
+		coldStart := w.served == 0
+		res.ColdStart = &coldStart
+	}
+	w.served += 1
+
+	if ctx.Err() == context.DeadlineExceeded {
+		// the process may be stuck on the call, so it is replaced
+		_ = w.cmd.Process.Kill()
+		go p.spawn()
+		return nil, ctx.Err()
+	}
+
+	if w.isExited() {
+		go p.spawn()
+	} else {
+		p.idle <- w
+	}
+
+	return res, err
+}
+
+func workerExecute(ctx context.Context, w *worker, payload []byte, contentType string) (*types.FaasApiResponse, error) {
+	method := http.MethodGet
+	if payload != nil {
+		method = http.MethodPost
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, w.url(), bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	httpRes, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, _ := ioutil.ReadAll(httpRes.Body)
+	_ = httpRes.Body.Close()
+
+	res := types.FaasApiResponse{
+		Headers:    httpRes.Header,
+		Body:       body,
+		StatusCode: httpRes.StatusCode,
+	}
+
+	if res.StatusCode >= 500 {
+		return &res, ErrorInternal{string(res.Body)}
+	}
+
+	return &res, nil
+}
+
+/*
+ * Utils
+ */
+
+func getFreePort() (int, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer listener.Close()
+
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
+
+// waitListening waits until the process accepts connections on its port
+func waitListening(w *worker, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if w.isExited() {
+			return fmt.Errorf("process exited before listening")
+		}
+
+		conn, err := net.DialTimeout("tcp", fmt.Sprintf("127.0.0.1:%d", w.port), 100*time.Millisecond)
+		if err == nil {
+			_ = conn.Close()
+			return nil
+		}
+
+		time.Sleep(100 * time.Millisecond)
+	}
+	return fmt.Errorf("process not listening on port %d after %s", w.port, timeout)
+}
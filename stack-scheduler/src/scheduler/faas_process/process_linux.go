@@ -0,0 +1,54 @@
+//go:build linux
+
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package faas_process
+
+import (
+	"os/exec"
+	"runtime"
+	"syscall"
+)
+
+// startProcess starts the process so that it is killed when the scheduler exits, in this way no function process is
+// left running after a restart. The parent death signal is sent when the thread which started the process exits, so
+// the process is started from a goroutine locked to its thread until the process exits. The returned channel receives
+// the outcome of the process when it exits.
+func startProcess(cmd *exec.Cmd) (<-chan error, error) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Pdeathsig: syscall.SIGKILL}
+
+	started := make(chan error, 1)
+	exited := make(chan error, 1)
+	go func() {
+		// the thread is never unlocked, so it is terminated with the goroutine after the process exited
+		runtime.LockOSThread()
+
+		err := cmd.Start()
+		started <- err
+		if err != nil {
+			return
+		}
+		exited <- cmd.Wait()
+	}()
+
+	if err := <-started; err != nil {
+		return nil, err
+	}
+	return exited, nil
+}
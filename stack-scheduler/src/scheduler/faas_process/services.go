@@ -0,0 +1,58 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package faas_process
+
+import (
+	"context"
+	"scheduler/types"
+	"sort"
+)
+
+// FunctionExecute executes the passed function name
+func FunctionExecute(ctx context.Context, functionName string, payload []byte, contentType string) (*types.FaasApiResponse, error) {
+	fn := getFunction(functionName)
+	if fn == nil {
+		return nil, ErrorFunctionNotFound{}
+	}
+	return fn.execute(ctx, payload, contentType)
+}
+
+// FunctionsGet returns all the functions in the manifest
+func FunctionsGet() []types.FaasFunction {
+	functionsMutex.RLock()
+	defer functionsMutex.RUnlock()
+
+	var output []types.FaasFunction
+	for _, fn := range functions {
+		output = append(output, fn.toFaasFunction())
+	}
+	sort.Slice(output, func(i, j int) bool { return output[i].Name < output[j].Name })
+
+	return output
+}
+
+// FunctionGet returns the function in the manifest with the passed name
+func FunctionGet(functionName string) (*types.FaasFunction, error) {
+	fn := getFunction(functionName)
+	if fn == nil {
+		return nil, ErrorFunctionNotFound{}
+	}
+	function := fn.toFaasFunction()
+	return &function, nil
+}
@@ -0,0 +1,74 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package faas_process
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"os/exec"
+	"scheduler/types"
+)
+
+// watchdogExecute starts a process for the call, the payload is written to stdin and the response is read from stdout.
+// As in the OpenFaaS watchdog, the request details are passed as Http_* environment variables.
+func watchdogExecute(ctx context.Context, fn *function, payload []byte, contentType string) (*types.FaasApiResponse, error) {
+	cmd := exec.CommandContext(ctx, fn.manifest.Command[0], fn.manifest.Command[1:]...)
+	cmd.Dir = fn.manifest.WorkingDir
+	cmd.Env = fn.environment()
+
+	method := http.MethodGet
+	if payload != nil {
+		method = http.MethodPost
+	}
+	cmd.Env = append(cmd.Env, "Http_Method="+method, "Http_Content_Type="+contentType, "Http_Path=/")
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	exited, err := startProcess(cmd)
+	if err == nil {
+		err = <-exited
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, ctx.Err()
+	}
+
+	if _, ok := err.(*exec.ExitError); ok {
+		res := types.FaasApiResponse{
+			Headers:    http.Header{"Content-Type": []string{"text/plain"}},
+			Body:       stderr.Bytes(),
+			StatusCode: http.StatusInternalServerError,
+		}
+		return &res, ErrorInternal{stderr.String()}
+	}
+	if err != nil {
+		return nil, ErrorInternal{err.Error()}
+	}
+
+	res := types.FaasApiResponse{
+		Headers:    http.Header{"Content-Type": []string{fn.manifest.ContentType}},
+		Body:       stdout.Bytes(),
+		StatusCode: http.StatusOK,
+	}
+
+	return &res, nil
+}
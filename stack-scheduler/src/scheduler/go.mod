@@ -7,6 +7,15 @@ require (
 	github.com/gorilla/mux v1.6.2
 	github.com/gorilla/websocket v1.5.0
 	github.com/op/go-logging v0.0.0-20160211212156-b2cb9fa56473
+	golang.org/x/net v0.17.0
+	google.golang.org/grpc v1.50.1
+	google.golang.org/protobuf v1.31.0
 )
 
-require github.com/gorilla/context v1.1.1 // indirect
+require (
+	github.com/golang/protobuf v1.5.2 // indirect
+	github.com/gorilla/context v1.1.1 // indirect
+	golang.org/x/sys v0.13.0 // indirect
+	golang.org/x/text v0.13.0 // indirect
+	google.golang.org/genproto v0.0.0-20200526211855-cb27e3aa2013 // indirect
+)
@@ -0,0 +1,280 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package gossip implements the gossip of the load between the schedulers. Periodically every node sends a compact
+// digest of its load, together with the freshest digests it received, to a few random peers over udp, so that every
+// node keeps a converging view of the load of the cluster which can be read without probing the peers.
+package gossip
+
+import (
+	"encoding/json"
+	"math/rand"
+	"net"
+	"scheduler/config"
+	"scheduler/faas"
+	"scheduler/log"
+	"scheduler/memdb"
+	"scheduler/queue"
+	"scheduler/service_discovery"
+	"scheduler/signature"
+	"sort"
+	"sync"
+	"time"
+)
+
+// maxDatagramSize is the maximum size of a gossip message, the digests which do not fit are left to next rounds
+const maxDatagramSize = 8192
+
+// signatureMethod and signatureUri are the method and the uri of the signatures of gossip messages
+const signatureMethod = "GOSSIP"
+const signatureUri = "/gossip"
+
+var view = make(map[string]Digest)
+var viewMutex sync.Mutex
+
+var conn *net.UDPConn
+
+// Start starts sending and receiving the gossip, it does nothing if the gossip is not enabled
+func Start() {
+	if !config.GetGossipEnabled() {
+		log.Log.Infof("Gossip is disabled")
+		return
+	}
+
+	var err error
+	conn, err = net.ListenUDP("udp", &net.UDPAddr{Port: int(config.GetGossipPort())})
+	if err != nil {
+		log.Log.Errorf("Cannot listen for gossip on port %d: %s", config.GetGossipPort(), err)
+		return
+	}
+
+	log.Log.Infof("Starting gossip on port %d with fan-out %d and interval %dms", config.GetGossipPort(),
+		config.GetGossipFanout(), config.GetGossipInterval())
+
+	go receiver()
+	go looper()
+}
+
+// IsStarted returns true if the gossip is running
+func IsStarted() bool {
+	return conn != nil
+}
+
+// GetClusterLoad returns the digests of the nodes which are not stale, this node included, ordered by node id
+func GetClusterLoad() []Digest {
+	out := []Digest{getDigest()}
+
+	viewMutex.Lock()
+	purge(time.Now())
+	for _, digest := range view {
+		out = append(out, digest)
+	}
+	viewMutex.Unlock()
+
+	sort.Slice(out, func(i, j int) bool { return out[i].NodeId < out[j].NodeId })
+	return out
+}
+
+// GetMachineLoad returns the last digest gossiped by the machine with the passed ip, if it is not stale
+func GetMachineLoad(ip string) (Digest, bool) {
+	viewMutex.Lock()
+	defer viewMutex.Unlock()
+
+	now := time.Now()
+	for _, digest := range view {
+		if digest.NodeIp == ip && !isStale(digest, now) {
+			return digest, true
+		}
+	}
+	return Digest{}, false
+}
+
+func looper() {
+	for {
+		time.Sleep(time.Duration(config.GetGossipInterval()) * time.Millisecond)
+		gossip()
+	}
+}
+
+// gossip sends the digests to fan-out random peers
+func gossip() {
+	machines, err := service_discovery.GetCachedMachinesIpsList()
+	if err != nil || len(machines) == 0 {
+		return
+	}
+
+	body, err := prepareMessage()
+	if err != nil {
+		log.Log.Errorf("Cannot prepare gossip message: %s", err)
+		return
+	}
+
+	fanout := int(config.GetGossipFanout())
+	for i, j := range rand.Perm(len(machines)) {
+		if i >= fanout {
+			break
+		}
+		addr := &net.UDPAddr{IP: net.ParseIP(machines[j]), Port: int(config.GetGossipPort())}
+		if addr.IP == nil {
+			continue
+		}
+		if _, err := conn.WriteToUDP(body, addr); err != nil {
+			log.Log.Debugf("Cannot gossip to machine %s: %s", machines[j], err)
+		}
+	}
+}
+
+// prepareMessage returns the message with the digest of this node and the freshest known ones which fit in a datagram
+func prepareMessage() ([]byte, error) {
+	digests := []Digest{getDigest()}
+
+	viewMutex.Lock()
+	purge(time.Now())
+	for _, digest := range view {
+		digests = append(digests, digest)
+	}
+	viewMutex.Unlock()
+
+	sort.SliceStable(digests[1:], func(i, j int) bool { return digests[1+i].Timestamp > digests[1+j].Timestamp })
+
+	for {
+		body, err := encodeMessage(digests)
+		if err != nil || len(body) <= maxDatagramSize || len(digests) == 1 {
+			return body, err
+		}
+		digests = digests[:len(digests)-1]
+	}
+}
+
+func encodeMessage(digests []Digest) ([]byte, error) {
+	msg := message{Digests: digests}
+	if signature.Enabled() {
+		digestsJson, err := json.Marshal(digests)
+		if err != nil {
+			return nil, err
+		}
+		msg.Signature = signature.Sign(signatureMethod, signatureUri, digestsJson)
+	}
+	return json.Marshal(msg)
+}
+
+func receiver() {
+	buffer := make([]byte, maxDatagramSize)
+	for {
+		n, addr, err := conn.ReadFromUDP(buffer)
+		if err != nil {
+			log.Log.Errorf("Cannot read gossip message: %s", err)
+			continue
+		}
+
+		digests, err := decodeMessage(buffer[:n])
+		if err != nil {
+			log.Log.Debugf("Discarding gossip message from %s: %s", addr, err)
+			continue
+		}
+		merge(digests)
+	}
+}
+
+func decodeMessage(body []byte) ([]Digest, error) {
+	var msg message
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return nil, err
+	}
+
+	if signature.Enabled() {
+		if msg.Signature == nil {
+			return nil, signature.ErrorMissing{}
+		}
+		digestsJson, err := json.Marshal(msg.Digests)
+		if err != nil {
+			return nil, err
+		}
+		if err := signature.Verify(msg.Signature, signatureMethod, signatureUri, digestsJson); err != nil {
+			return nil, err
+		}
+	}
+	return msg.Digests, nil
+}
+
+// merge keeps the received digests which are newer than the known ones of the same nodes
+func merge(digests []Digest) {
+	viewMutex.Lock()
+	defer viewMutex.Unlock()
+
+	now := time.Now()
+	self := getNodeId()
+	for _, digest := range digests {
+		if digest.NodeId == "" || digest.NodeId == self || isStale(digest, now) {
+			continue
+		}
+		if known, exists := view[digest.NodeId]; exists && known.Timestamp >= digest.Timestamp {
+			continue
+		}
+		view[digest.NodeId] = digest
+	}
+}
+
+// purge removes the stale digests, viewMutex must be held
+func purge(now time.Time) {
+	for id, digest := range view {
+		if isStale(digest, now) {
+			delete(view, id)
+		}
+	}
+}
+
+// isStale tells if the digest is older than the staleness, the age is measured from the clock of the sender
+func isStale(digest Digest, now time.Time) bool {
+	age := now.UnixNano()/int64(time.Millisecond) - digest.Timestamp
+	return age > int64(config.GetGossipStaleness())
+}
+
+// getDigest returns the digest of the current load of this node
+func getDigest() Digest {
+	digest := Digest{
+		NodeId:    getNodeId(),
+		NodeIp:    getNodeIp(),
+		Running:   memdb.GetTotalRunningFunctions(),
+		Max:       config.GetRunningFunctionMax(),
+		Queue:     uint(queue.GetLength()),
+		Timestamp: time.Now().UnixNano() / int64(time.Millisecond),
+	}
+	if functions, err := faas.GetDeployedFunctions(); err == nil {
+		digest.FunctionsHash = hashFunctions(functions)
+	}
+	return digest
+}
+
+// getNodeId returns the id of this node, or its ip if the id is not known
+func getNodeId() string {
+	if service_discovery.Configuration == nil {
+		return ""
+	}
+	if service_discovery.Configuration.MachineId != "" {
+		return service_discovery.Configuration.MachineId
+	}
+	return service_discovery.Configuration.MachineIp
+}
+
+func getNodeIp() string {
+	if service_discovery.Configuration == nil {
+		return ""
+	}
+	return service_discovery.Configuration.MachineIp
+}
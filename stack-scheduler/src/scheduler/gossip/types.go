@@ -0,0 +1,61 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package gossip
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"scheduler/signature"
+	"sort"
+	"strings"
+)
+
+// Digest is the compact load of a node which is gossiped to the others
+type Digest struct {
+	NodeId        string `json:"id"`
+	NodeIp        string `json:"ip"`
+	Running       uint   `json:"r"`
+	Max           uint   `json:"m"`
+	Queue         uint   `json:"q"`
+	Timestamp     int64  `json:"t"`  // unix ms at which the load has been read by the node
+	FunctionsHash string `json:"fh"` // the hash of the deployed functions, empty if they cannot be retrieved
+}
+
+// message is the datagram sent to a peer, the digests are the one of the sender and the freshest ones it knows
+type message struct {
+	Digests   []Digest             `json:"d"`
+	Signature *signature.Signature `json:"s,omitempty"`
+}
+
+// hashFunctions returns the hash of the deployed functions as name -> version, which is the same on all the nodes
+// which deployed the same functions
+func hashFunctions(functions map[string]string) string {
+	var names []string
+	for name := range functions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var entries []string
+	for _, name := range names {
+		entries = append(entries, name+"="+functions[name])
+	}
+	sum := sha256.Sum256([]byte(strings.Join(entries, ",")))
+	return hex.EncodeToString(sum[:8])
+}
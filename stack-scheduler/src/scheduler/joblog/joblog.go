@@ -0,0 +1,237 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package joblog implements a write-ahead log of the jobs accepted by the node, so that queued and asynchronous jobs
+// survive a restart of the scheduler. The log is made of segments, a segment is removed when all its jobs have been
+// completed, and the jobs not yet completed are moved to the newest segment only when the old segments are mostly made
+// of completed jobs.
+package joblog
+
+import (
+	"os"
+	"scheduler/config"
+	"scheduler/log"
+	"sync"
+	"time"
+)
+
+var mutex sync.Mutex
+var started = false
+
+var currentSegment *segment
+var closedSegments []*segment
+
+// pending holds all the accepted jobs which have not been completed yet
+var pending = make(map[string]*pendingEntry)
+
+// dirty tells if there are records not yet synced to disk, used only when the sync mode is interval
+var dirty = false
+
+// compactionLiveRatio is the ratio of the size of the jobs not yet completed to the size of the closed segments under
+// which the jobs are moved to the current segment, so that every record is copied a bounded number of times
+const compactionLiveRatio = 0.5
+
+type pendingEntry struct {
+	entry        *Entry
+	segmentIndex uint64
+	size         int64 // the size of the record of the entry
+}
+
+// Start opens the job log, loads the jobs not yet completed and compacts the old segments. It does nothing if the job
+// log is not enabled.
+func Start() {
+	if !config.GetJobLogEnabled() {
+		log.Log.Infof("Job log is disabled")
+		return
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	err := os.MkdirAll(config.GetJobLogPath(), 0755)
+	if err != nil {
+		log.Log.Errorf("Cannot create job log folder %s, job log is disabled: %s", config.GetJobLogPath(), err)
+		return
+	}
+
+	lastIndex, err := loadSegments()
+	if err != nil {
+		log.Log.Errorf("Cannot load job log segments, job log is disabled: %s", err)
+		return
+	}
+
+	// always start writing to a fresh segment, the last one may have been truncated by a crash
+	currentSegment, err = createSegment(lastIndex + 1)
+	if err != nil {
+		log.Log.Errorf("Cannot create job log segment, job log is disabled: %s", err)
+		return
+	}
+
+	compact()
+
+	started = true
+
+	if config.GetJobLogSyncMode() == config.JobLogSyncModeInterval {
+		go syncLooper()
+	}
+
+	log.Log.Infof("Job log started at %s with %d pending jobs, sync=%s", config.GetJobLogPath(), len(pending), config.GetJobLogSyncMode())
+}
+
+// Enabled tells if the job log has been started and jobs are being recorded
+func Enabled() bool {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	return started
+}
+
+/*
+ * Internals
+ */
+
+// appendEntry writes the entry to the current segment and returns the size of its record. Must be called with mutex
+// held.
+func appendEntry(entry *Entry) (int64, error) {
+	size, err := currentSegment.append(entry)
+	if err != nil {
+		return size, err
+	}
+
+	switch config.GetJobLogSyncMode() {
+	case config.JobLogSyncModeAlways:
+		err = currentSegment.sync()
+	case config.JobLogSyncModeInterval:
+		dirty = true
+	}
+	return size, err
+}
+
+// rotateIfNeeded rotates the current segment if it is too big. It must be called after the pending jobs have been
+// updated with the last appended entry, since the compaction relies on them. Must be called with mutex held.
+func rotateIfNeeded() {
+	if currentSegment.size >= int64(config.GetJobLogSegmentMaxSize()) {
+		rotate()
+	}
+}
+
+// rotate closes the current segment and opens a new one, then old segments are compacted. Must be called with mutex
+// held.
+func rotate() {
+	newSegment, err := createSegment(currentSegment.index + 1)
+	if err != nil {
+		log.Log.Errorf("Cannot rotate job log segment: %s", err)
+		return
+	}
+
+	_ = currentSegment.sync()
+	_ = currentSegment.close()
+	closedSegments = append(closedSegments, currentSegment)
+	currentSegment = newSegment
+	dirty = false
+
+	compact()
+}
+
+// compact removes the oldest closed segments without pending jobs, and when the pending jobs are less than
+// compactionLiveRatio of the closed segments it moves them to the current segment and removes all the closed ones.
+// Only a prefix of the segments is removed, since a segment without pending jobs can hold the completion of a job
+// accepted in an older segment which is kept, and the job would be replayed at startup. Must be called with mutex held.
+func compact() {
+	if len(closedSegments) == 0 {
+		return
+	}
+
+	liveOfSegments := make(map[uint64]int64)
+	for _, pendingJob := range pending {
+		if pendingJob.segmentIndex != currentSegment.index {
+			liveOfSegments[pendingJob.segmentIndex] += pendingJob.size
+		}
+	}
+
+	removed := 0
+	for removed < len(closedSegments) && liveOfSegments[closedSegments[removed].index] == 0 {
+		removeSegment(closedSegments[removed])
+		removed++
+	}
+	closedSegments = closedSegments[removed:]
+
+	var live, total int64
+	for _, s := range closedSegments {
+		live += liveOfSegments[s.index]
+		total += s.size
+	}
+
+	if len(closedSegments) == 0 || float64(live) >= compactionLiveRatio*float64(total) {
+		return
+	}
+
+	moved := 0
+	for _, pendingJob := range pending {
+		if pendingJob.segmentIndex == currentSegment.index {
+			continue
+		}
+
+		size, err := currentSegment.append(pendingJob.entry)
+		if err != nil {
+			log.Log.Errorf("Cannot compact job log, keeping old segments: %s", err)
+			return
+		}
+		pendingJob.segmentIndex = currentSegment.index
+		pendingJob.size = size
+		moved++
+	}
+
+	// the moved records must be on disk before removing the old segments
+	err := currentSegment.sync()
+	if err != nil {
+		log.Log.Errorf("Cannot sync job log, keeping old segments: %s", err)
+		return
+	}
+
+	for _, s := range closedSegments {
+		removeSegment(s)
+	}
+
+	log.Log.Debugf("Compacted %d job log segments, moved %d pending jobs", len(closedSegments), moved)
+
+	closedSegments = []*segment{}
+}
+
+func removeSegment(s *segment) {
+	err := os.Remove(s.path)
+	if err != nil {
+		log.Log.Warningf("Cannot remove job log segment %s: %s", s.path, err)
+	}
+}
+
+func syncLooper() {
+	for {
+		time.Sleep(time.Duration(config.GetJobLogSyncInterval()) * time.Millisecond)
+
+		mutex.Lock()
+		if dirty {
+			err := currentSegment.sync()
+			if err != nil {
+				log.Log.Errorf("Cannot sync job log: %s", err)
+			}
+			dirty = false
+		}
+		mutex.Unlock()
+	}
+}
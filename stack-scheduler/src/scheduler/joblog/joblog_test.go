@@ -0,0 +1,187 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package joblog
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"scheduler/config"
+	"scheduler/types"
+	"testing"
+)
+
+// startTestJobLog starts the job log in a temporary folder, with segments which are rotated only by the tests
+func startTestJobLog(t *testing.T) {
+	t.Setenv(config.EnvDataPath, t.TempDir())
+	t.Setenv(config.EnvJobLogEnabled, "true")
+	t.Setenv(config.EnvJobLogSyncMode, config.JobLogSyncModeAlways)
+	t.Setenv(config.EnvJobLogSegmentMaxSize, "1048576")
+	config.InitConfigurationStatic()
+	t.Cleanup(stop)
+
+	Start()
+	if !Enabled() {
+		t.Fatal("job log has not been started")
+	}
+}
+
+// stop closes the job log and forgets its state, as if the scheduler exited
+func stop() {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if currentSegment != nil {
+		_ = currentSegment.close()
+	}
+	currentSegment = nil
+	closedSegments = nil
+	pending = make(map[string]*pendingEntry)
+	started = false
+}
+
+func restart() {
+	stop()
+	Start()
+}
+
+func accept(t *testing.T, id string, payload []byte) {
+	_, err := Accepted(KindQueued, &types.ServiceRequest{JobLogId: id, ServiceName: "fn", Payload: payload}, "")
+	if err != nil {
+		t.Fatalf("cannot record accepted job %s: %s", id, err)
+	}
+}
+
+func complete(t *testing.T, id string) {
+	if err := Completed(id); err != nil {
+		t.Fatalf("cannot record completed job %s: %s", id, err)
+	}
+}
+
+func rotateSegment() {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	rotate()
+}
+
+func pendingIds() map[string]bool {
+	ids := make(map[string]bool)
+	for _, entry := range GetPending(KindQueued) {
+		ids[entry.Id] = true
+	}
+	return ids
+}
+
+func countSegments(t *testing.T) int {
+	paths, err := filepath.Glob(filepath.Join(config.GetJobLogPath(), segmentFileNameGlob))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(paths)
+}
+
+func TestReplayPendingJobs(t *testing.T) {
+	startTestJobLog(t)
+
+	accept(t, "a", []byte("payload-a"))
+	accept(t, "b", []byte("payload-b"))
+	complete(t, "b")
+
+	restart()
+
+	entries := GetPending(KindQueued)
+	if len(entries) != 1 || entries[0].Id != "a" {
+		t.Fatalf("expected only job a to be replayed, got %v", pendingIds())
+	}
+	if !bytes.Equal(entries[0].Payload, []byte("payload-a")) {
+		t.Fatalf("payload of job a has not been replayed, got %q", entries[0].Payload)
+	}
+}
+
+func TestCompactionKeepsCompletionOfJobsInKeptSegments(t *testing.T) {
+	startTestJobLog(t)
+
+	// a is large enough to keep its segment above the live ratio, so that it is not moved
+	accept(t, "a", bytes.Repeat([]byte("a"), 512))
+	accept(t, "b", []byte("b"))
+	rotateSegment()
+
+	// the segment with the completion of b has no pending jobs, but b has been accepted in the kept segment
+	complete(t, "b")
+	rotateSegment()
+
+	if countSegments(t) != 3 {
+		t.Fatalf("expected the segments with a and with the completion of b to be kept, got %d segments", countSegments(t))
+	}
+
+	restart()
+
+	ids := pendingIds()
+	if len(ids) != 1 || !ids["a"] {
+		t.Fatalf("expected only job a to be replayed after compaction, got %v", ids)
+	}
+}
+
+func TestCompactionMovesPendingJobs(t *testing.T) {
+	startTestJobLog(t)
+
+	accept(t, "a", []byte("a"))
+	for _, id := range []string{"b", "c", "d"} {
+		accept(t, id, bytes.Repeat([]byte(id), 512))
+		complete(t, id)
+	}
+	rotateSegment()
+
+	// a is less than the live ratio of the closed segment, so it is moved and the closed segment is removed
+	if countSegments(t) != 1 {
+		t.Fatalf("expected only the current segment to be kept, got %d segments", countSegments(t))
+	}
+
+	restart()
+
+	ids := pendingIds()
+	if len(ids) != 1 || !ids["a"] {
+		t.Fatalf("expected only job a to be replayed after compaction, got %v", ids)
+	}
+
+	complete(t, "a")
+	restart()
+
+	if ids = pendingIds(); len(ids) != 0 {
+		t.Fatalf("expected no job to be replayed, got %v", ids)
+	}
+}
+
+func TestDisabledJobLogRecordsNothing(t *testing.T) {
+	t.Setenv(config.EnvDataPath, t.TempDir())
+	t.Setenv(config.EnvJobLogEnabled, "false")
+	config.InitConfigurationStatic()
+	t.Cleanup(stop)
+
+	Start()
+
+	id, err := Accepted(KindQueued, &types.ServiceRequest{ServiceName: "fn"}, "")
+	if err != nil || id != "" {
+		t.Fatalf("expected nothing to be recorded, got id %q and error %v", id, err)
+	}
+	if _, err = os.Stat(config.GetJobLogPath()); !os.IsNotExist(err) {
+		t.Fatalf("expected the job log folder not to be created")
+	}
+}
@@ -0,0 +1,180 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package joblog
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"scheduler/config"
+	"scheduler/log"
+	"sort"
+)
+
+const segmentFileNameFormat = "segment-%020d.log"
+const segmentFileNameGlob = "segment-*.log"
+
+// recordHeaderSize is the size of the header of every record: the length of the data and its crc32
+const recordHeaderSize = 8
+
+type segment struct {
+	index uint64
+	path  string
+	size  int64
+	file  *os.File
+}
+
+func getSegmentPath(index uint64) string {
+	return filepath.Join(config.GetJobLogPath(), fmt.Sprintf(segmentFileNameFormat, index))
+}
+
+func createSegment(index uint64) (*segment, error) {
+	path := getSegmentPath(index)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	return &segment{index: index, path: path, size: info.Size(), file: file}, nil
+}
+
+// append writes the entry at the end of the segment and returns the size of its record
+func (s *segment) append(entry *Entry) (int64, error) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return 0, err
+	}
+
+	record := make([]byte, recordHeaderSize+len(data))
+	binary.BigEndian.PutUint32(record[0:4], uint32(len(data)))
+	binary.BigEndian.PutUint32(record[4:8], crc32.ChecksumIEEE(data))
+	copy(record[recordHeaderSize:], data)
+
+	n, err := s.file.Write(record)
+	s.size += int64(n)
+
+	return int64(len(record)), err
+}
+
+func (s *segment) sync() error {
+	if s.file == nil {
+		return nil
+	}
+	return s.file.Sync()
+}
+
+func (s *segment) close() error {
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+// loadSegments reads all the segments on disk, in order, filling the pending jobs. Segments are added to the closed
+// ones and the index of the last segment is returned.
+func loadSegments() (uint64, error) {
+	paths, err := filepath.Glob(filepath.Join(config.GetJobLogPath(), segmentFileNameGlob))
+	if err != nil {
+		return 0, err
+	}
+
+	var loaded []*segment
+	for _, path := range paths {
+		var index uint64
+		_, err = fmt.Sscanf(filepath.Base(path), segmentFileNameFormat, &index)
+		if err != nil {
+			log.Log.Warningf("Ignoring job log file %s: %s", path, err)
+			continue
+		}
+		loaded = append(loaded, &segment{index: index, path: path})
+	}
+
+	sort.Slice(loaded, func(i, j int) bool { return loaded[i].index < loaded[j].index })
+
+	lastIndex := uint64(0)
+	for _, s := range loaded {
+		err = s.load()
+		if err != nil {
+			log.Log.Warningf("Job log segment %s has been read partially: %s", s.path, err)
+		}
+		closedSegments = append(closedSegments, s)
+		lastIndex = s.index
+	}
+
+	return lastIndex, nil
+}
+
+// load reads all the records of the segment, stopping at the first truncated or corrupted one
+func (s *segment) load() error {
+	file, err := os.Open(s.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	header := make([]byte, recordHeaderSize)
+
+	for {
+		_, err = io.ReadFull(reader, header)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		data := make([]byte, binary.BigEndian.Uint32(header[0:4]))
+		_, err = io.ReadFull(reader, data)
+		if err != nil {
+			return err
+		}
+		if crc32.ChecksumIEEE(data) != binary.BigEndian.Uint32(header[4:8]) {
+			return ErrorCorruptedRecord{}
+		}
+
+		var entry Entry
+		err = json.Unmarshal(data, &entry)
+		if err != nil {
+			return err
+		}
+
+		switch entry.Type {
+		case EntryTypeAccepted:
+			pending[entry.Id] = &pendingEntry{entry: &entry, segmentIndex: s.index, size: int64(recordHeaderSize + len(data))}
+		case EntryTypeCompleted:
+			delete(pending, entry.Id)
+		}
+
+		s.size += int64(recordHeaderSize + len(data))
+	}
+}
@@ -0,0 +1,113 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package joblog
+
+import (
+	"scheduler/log"
+	"scheduler/types"
+	"scheduler/utils"
+	"sort"
+	"time"
+)
+
+// Accepted records that the passed request has been accepted by the node. If the request has no JobLogId a new one
+// is assigned to it. The id of the record is returned, or an empty string if the job log is not enabled.
+func Accepted(kind string, req *types.ServiceRequest, callbackUrl string) (string, error) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if !started {
+		return "", nil
+	}
+
+	if req.JobLogId == "" {
+		req.JobLogId = utils.GenerateUniqueId()
+	}
+
+	entry := &Entry{
+		Id:                 req.JobLogId,
+		Type:               EntryTypeAccepted,
+		Kind:               kind,
+		ServiceName:        req.ServiceName,
+		ServiceType:        req.ServiceType,
+		Payload:            req.Payload,
+		PayloadContentType: req.PayloadContentType,
+		CallbackUrl:        callbackUrl,
+		AcceptedAt:         time.Now().UnixNano(),
+	}
+	if req.Headers != nil {
+		entry.Headers = *req.Headers
+	}
+
+	size, err := appendEntry(entry)
+	if err != nil {
+		log.Log.Errorf("[R#%d,T%s] Cannot record accepted job %s: %s", req.Id, req.IdTracing, entry.Id, err)
+		return "", err
+	}
+
+	pending[entry.Id] = &pendingEntry{entry: entry, segmentIndex: currentSegment.index, size: size}
+	rotateIfNeeded()
+
+	return entry.Id, nil
+}
+
+// Completed records that the job with the passed id has been completed, so it will not be replayed at startup
+func Completed(id string) error {
+	if id == "" {
+		return nil
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if !started {
+		return nil
+	}
+	if _, exists := pending[id]; !exists {
+		return nil
+	}
+
+	_, err := appendEntry(&Entry{Id: id, Type: EntryTypeCompleted})
+	if err != nil {
+		log.Log.Errorf("Cannot record completed job %s: %s", id, err)
+		return err
+	}
+
+	delete(pending, id)
+	rotateIfNeeded()
+
+	return nil
+}
+
+// GetPending returns the accepted and not completed entries of the passed kind, ordered by acceptance time
+func GetPending(kind string) []*Entry {
+	var entries []*Entry
+
+	mutex.Lock()
+	for _, pendingJob := range pending {
+		if pendingJob.entry.Kind == kind {
+			entries = append(entries, pendingJob.entry)
+		}
+	}
+	mutex.Unlock()
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].AcceptedAt < entries[j].AcceptedAt })
+
+	return entries
+}
@@ -0,0 +1,70 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package joblog
+
+import "scheduler/types"
+
+const EntryTypeAccepted = "accepted"
+const EntryTypeCompleted = "completed"
+
+// KindQueued marks jobs accepted in the local queue
+const KindQueued = "queued"
+
+// KindAsync marks jobs accepted for asynchronous execution
+const KindAsync = "async"
+
+// Entry is a single record of the job log
+type Entry struct {
+	Id                 string            `json:"id"`
+	Type               string            `json:"type"`
+	Kind               string            `json:"kind,omitempty"`
+	ServiceName        string            `json:"service_name,omitempty"`
+	ServiceType        int64             `json:"service_type,omitempty"`
+	Payload            []byte            `json:"payload,omitempty"`
+	PayloadContentType string            `json:"payload_content_type,omitempty"`
+	Headers            map[string]string `json:"headers,omitempty"`
+	CallbackUrl        string            `json:"callback_url,omitempty"`
+	AcceptedAt         int64             `json:"accepted_at,omitempty"`
+}
+
+// ServiceRequest rebuilds the service request from an accepted entry
+func (e *Entry) ServiceRequest() *types.ServiceRequest {
+	headers := map[string]string{}
+	for key, value := range e.Headers {
+		headers[key] = value
+	}
+
+	return &types.ServiceRequest{
+		IdTracing:          e.Id,
+		ServiceName:        e.ServiceName,
+		ServiceType:        e.ServiceType,
+		Payload:            e.Payload,
+		PayloadContentType: e.PayloadContentType,
+		Headers:            &headers,
+		External:           false,
+		Async:              e.Kind == KindAsync,
+		JobLogId:           e.Id,
+	}
+}
+
+type ErrorCorruptedRecord struct{}
+
+func (ErrorCorruptedRecord) Error() string {
+	return "job log record is corrupted"
+}
@@ -24,13 +24,24 @@ import (
 	"scheduler/log"
 	"scheduler/metrics"
 	"sync"
+	"time"
 )
 
 type Function struct {
 	Name             string
 	RunningInstances uint
+	Timeouts         uint64
+	// LatencyAverage is the moving average in seconds of the time spent by jobs in the queue and in execution
+	LatencyAverage float64
+	// ExecutionTimeAverage is the moving average in seconds of the execution time of jobs
+	ExecutionTimeAverage float64
+	// LastExecutionAt is when the last execution stopped
+	LastExecutionAt time.Time
 }
 
+// latencyAverageWeight is the weight of the last sample in the moving average of the latency
+const latencyAverageWeight = 0.2
+
 type ErrorFunctionNotFound struct{}
 
 func (ErrorFunctionNotFound) Error() string {
@@ -43,6 +54,7 @@ func (ErrorFunctionNotFound) Error() string {
 
 var functions []*Function
 var totalRunningFunctions uint = 0
+var totalTimeouts uint64 = 0
 
 var totalRunningFunctionsOfTypes = make(map[int64]int64) // the number of running tasks according to the type
 
@@ -101,6 +113,7 @@ func SetFunctionStopped(functionName string, functionType int64) error {
 	}
 
 	fn.RunningInstances -= 1
+	fn.LastExecutionAt = time.Now()
 	totalRunningFunctions -= 1
 	totalRunningFunctionsOfTypeDecrease(functionType)
 
@@ -111,6 +124,93 @@ func SetFunctionStopped(functionName string, functionType int64) error {
 	return nil
 }
 
+// PostFunctionLatency updates the moving average of the latency of the function with the passed sample in seconds
+func PostFunctionLatency(functionName string, latency float64) {
+	mutexRunningFunctions.Lock()
+
+	fn := getFunction(functionName, true)
+	if fn.LatencyAverage == 0 {
+		fn.LatencyAverage = latency
+	} else {
+		fn.LatencyAverage = latencyAverageWeight*latency + (1-latencyAverageWeight)*fn.LatencyAverage
+	}
+
+	mutexRunningFunctions.Unlock()
+}
+
+// PostFunctionExecutionTime updates the moving average of the execution time of the function with the passed sample in
+// seconds
+func PostFunctionExecutionTime(functionName string, executionTime float64) {
+	mutexRunningFunctions.Lock()
+
+	fn := getFunction(functionName, true)
+	if fn.ExecutionTimeAverage == 0 {
+		fn.ExecutionTimeAverage = executionTime
+	} else {
+		fn.ExecutionTimeAverage = latencyAverageWeight*executionTime + (1-latencyAverageWeight)*fn.ExecutionTimeAverage
+	}
+
+	mutexRunningFunctions.Unlock()
+}
+
+// GetFunction returns a copy of the state of the function
+func GetFunction(functionName string) (Function, error) {
+	mutexRunningFunctions.Lock()
+	defer mutexRunningFunctions.Unlock()
+
+	fn := getFunction(functionName, false)
+	if fn == nil {
+		return Function{}, ErrorFunctionNotFound{}
+	}
+	return *fn, nil
+}
+
+// SetFunctionTimedOut counts an execution of the function which exceeded its timeout
+func SetFunctionTimedOut(functionName string) {
+	mutexRunningFunctions.Lock()
+
+	fn := getFunction(functionName, true)
+	fn.Timeouts += 1
+	totalTimeouts += 1
+
+	// metrics
+	metrics.PostJobTimedOut(functionName)
+
+	mutexRunningFunctions.Unlock()
+}
+
+func GetTotalTimeouts() uint64 {
+	mutexRunningFunctions.Lock()
+	defer mutexRunningFunctions.Unlock()
+
+	return totalTimeouts
+}
+
+// GetTimeoutsOfFunctions returns the number of timed out executions for every function
+func GetTimeoutsOfFunctions() map[string]uint64 {
+	out := make(map[string]uint64)
+
+	mutexRunningFunctions.Lock()
+	for _, fn := range functions {
+		out[fn.Name] = fn.Timeouts
+	}
+	mutexRunningFunctions.Unlock()
+
+	return out
+}
+
+// GetFunctions returns a copy of the state of every function which has been executed
+func GetFunctions() []Function {
+	mutexRunningFunctions.Lock()
+	defer mutexRunningFunctions.Unlock()
+
+	out := make([]Function, len(functions))
+	for i, fn := range functions {
+		out[i] = *fn
+	}
+	return out
+}
+
 func GetTotalRunningFunctions() uint {
 	return totalRunningFunctions
 }
@@ -34,6 +34,12 @@ func PostJobInvocations(fnName string, code int) {
 	}
 }
 
+func PostJobTimedOut(fnName string) {
+	if enableMetrics {
+		// jobTimeoutsTotal.WithLabelValues(fnName).Inc()
+	}
+}
+
 func PostQueueFreedSlot() {
 	if enableMetrics {
 		// queueFree.Inc()
@@ -0,0 +1,210 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package peer_health tracks the health of the peers from the probes and the forwards sent to them. A peer is ejected
+// from the selection when it keeps failing or when its probe latency is an outlier among the peers, and it comes back
+// after an ejection time which doubles at every following ejection.
+package peer_health
+
+import (
+	"fmt"
+	"scheduler/config"
+	"scheduler/log"
+	"sort"
+	"sync"
+	"time"
+)
+
+var peers = map[string]*peer{}
+var peersMutex sync.Mutex
+
+// RecordSuccess records a probe or a forward to the peer which succeeded, the latency is the one of the probe and it is
+// 0 for the forwards, whose duration depends on the function
+func RecordSuccess(ip string, latency time.Duration) {
+	policy, enabled := config.GetOutlierEjectionPolicy()
+	if !enabled {
+		return
+	}
+
+	peersMutex.Lock()
+	defer peersMutex.Unlock()
+
+	p := getPeer(ip)
+	now := time.Now()
+	if p.isEjected(now) {
+		// the request was sent before the ejection
+		return
+	}
+	p.refresh(policy, now)
+	p.consecutiveFailures = 0
+
+	if latency <= 0 {
+		return
+	}
+	p.recordLatency(latency)
+
+	if policy.LatencyFactor <= 0 || p.latencyEwma < float64(policy.LatencyMin) {
+		return
+	}
+	median, samples := medianLatency(now)
+	if samples < policy.LatencyMinPeers || samples < 2 {
+		return
+	}
+	if p.latencyEwma > policy.LatencyFactor*median {
+		eject(ip, p, policy, now, fmt.Sprintf("probe latency %.1fms is above %.1f times the median %.1fms", p.latencyEwma, policy.LatencyFactor, median))
+	}
+}
+
+// RecordFailure records a probe or a forward to the peer which failed without a response
+func RecordFailure(ip string) {
+	policy, enabled := config.GetOutlierEjectionPolicy()
+	if !enabled {
+		return
+	}
+
+	peersMutex.Lock()
+	defer peersMutex.Unlock()
+
+	p := getPeer(ip)
+	now := time.Now()
+	if p.isEjected(now) {
+		return
+	}
+	p.refresh(policy, now)
+	p.consecutiveFailures++
+
+	if policy.ConsecutiveFailures > 0 && p.consecutiveFailures >= policy.ConsecutiveFailures {
+		eject(ip, p, policy, now, fmt.Sprintf("%d consecutive failures", p.consecutiveFailures))
+	}
+}
+
+// FilterEjected returns the peers of the list which are not ejected
+func FilterEjected(ips []string) []string {
+	if _, enabled := config.GetOutlierEjectionPolicy(); !enabled {
+		return ips
+	}
+
+	peersMutex.Lock()
+	defer peersMutex.Unlock()
+
+	now := time.Now()
+	out := make([]string, 0, len(ips))
+	for _, ip := range ips {
+		if p, exists := peers[ip]; exists && p.isEjected(now) {
+			continue
+		}
+		out = append(out, ip)
+	}
+	return out
+}
+
+// Retain forgets the peers not in the list
+func Retain(ips []string) {
+	alive := make(map[string]bool, len(ips))
+	for _, ip := range ips {
+		alive[ip] = true
+	}
+
+	peersMutex.Lock()
+	defer peersMutex.Unlock()
+
+	for ip := range peers {
+		if !alive[ip] {
+			delete(peers, ip)
+		}
+	}
+}
+
+// GetPeersStatus returns the health of every tracked peer
+func GetPeersStatus() []PeerStatus {
+	peersMutex.Lock()
+	defer peersMutex.Unlock()
+
+	now := time.Now()
+	out := []PeerStatus{}
+	for ip, p := range peers {
+		status := PeerStatus{
+			Ip:                  ip,
+			Ejected:             p.isEjected(now),
+			Ejections:           p.ejections,
+			ConsecutiveFailures: p.consecutiveFailures,
+			LatencyEwma:         p.latencyEwma,
+		}
+		if status.Ejected {
+			ejectedUntil := p.ejectedUntil
+			status.EjectedUntil = &ejectedUntil
+			status.Reason = p.reason
+		}
+		out = append(out, status)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Ip < out[j].Ip })
+	return out
+}
+
+func getPeer(ip string) *peer {
+	p, exists := peers[ip]
+	if !exists {
+		p = &peer{}
+		peers[ip] = p
+	}
+	return p
+}
+
+// eject excludes the peer from the selection, unless too many peers are already ejected
+func eject(ip string, p *peer, policy config.OutlierEjectionPolicy, now time.Time, reason string) {
+	ejected := 0
+	for _, other := range peers {
+		if other.isEjected(now) {
+			ejected++
+		}
+	}
+	if (ejected+1)*100 > int(policy.MaxEjectedPercent)*len(peers) {
+		log.Log.Debugf("Peer %s is not ejected since %d of %d peers are already ejected: %s", ip, ejected, len(peers), reason)
+		return
+	}
+
+	duration := p.ejectionDuration(policy)
+	p.ejections++
+	p.ejectedUntil = now.Add(duration)
+	p.reason = reason
+	p.consecutiveFailures = 0
+	// the latency is measured again when the peer comes back
+	p.latencyEwma = 0
+
+	log.Log.Warningf("Ejected peer %s for %s: %s", ip, duration, reason)
+}
+
+// medianLatency returns the median probe latency of the peers which are not ejected and the number of such peers
+func medianLatency(now time.Time) (float64, uint) {
+	var latencies []float64
+	for _, p := range peers {
+		if p.latencyEwma > 0 && !p.isEjected(now) {
+			latencies = append(latencies, p.latencyEwma)
+		}
+	}
+	if len(latencies) == 0 {
+		return 0, 0
+	}
+
+	sort.Float64s(latencies)
+	middle := len(latencies) / 2
+	if len(latencies)%2 == 0 {
+		return (latencies[middle-1] + latencies[middle]) / 2, uint(len(latencies))
+	}
+	return latencies[middle], uint(len(latencies))
+}
@@ -0,0 +1,80 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package peer_health
+
+import (
+	"scheduler/config"
+	"time"
+)
+
+// latencyEwmaAlpha is the weight of the last probe in the average latency of a peer
+const latencyEwmaAlpha = 0.3
+
+// PeerStatus is the health of a peer
+type PeerStatus struct {
+	Ip                  string     `json:"ip"`
+	Ejected             bool       `json:"ejected"`
+	EjectedUntil        *time.Time `json:"ejected_until,omitempty"`
+	Reason              string     `json:"reason,omitempty"`
+	Ejections           uint       `json:"ejections"`            // the ejections which doubled the ejection time
+	ConsecutiveFailures uint       `json:"consecutive_failures"` // the failures since the last success
+	LatencyEwma         float64    `json:"latency_ewma"`         // the average probe latency in ms
+}
+
+type peer struct {
+	consecutiveFailures uint
+	latencyEwma         float64
+
+	ejections    uint
+	ejectedUntil time.Time
+	reason       string
+}
+
+func (p *peer) isEjected(now time.Time) bool {
+	return now.Before(p.ejectedUntil)
+}
+
+// refresh resets the ejection time of a peer which has not been ejected for the maximum ejection time
+func (p *peer) refresh(policy config.OutlierEjectionPolicy, now time.Time) {
+	if p.ejections > 0 && now.Sub(p.ejectedUntil) > time.Duration(policy.EjectionTimeMax)*time.Millisecond {
+		p.ejections = 0
+	}
+}
+
+func (p *peer) recordLatency(latency time.Duration) {
+	latencyMs := float64(latency) / float64(time.Millisecond)
+	if p.latencyEwma == 0 {
+		p.latencyEwma = latencyMs
+		return
+	}
+	p.latencyEwma = latencyEwmaAlpha*latencyMs + (1-latencyEwmaAlpha)*p.latencyEwma
+}
+
+// ejectionDuration returns the duration of the next ejection of the peer
+func (p *peer) ejectionDuration(policy config.OutlierEjectionPolicy) time.Duration {
+	duration := time.Duration(policy.EjectionTime) * time.Millisecond
+	maxDuration := time.Duration(policy.EjectionTimeMax) * time.Millisecond
+	for i := uint(0); i < p.ejections && (maxDuration <= 0 || duration < maxDuration); i++ {
+		duration *= 2
+	}
+	if maxDuration > 0 && duration > maxDuration {
+		duration = maxDuration
+	}
+	return duration
+}
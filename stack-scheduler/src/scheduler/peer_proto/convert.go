@@ -0,0 +1,125 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package peer_proto
+
+import "scheduler/types"
+
+// NewJobRequest converts the job request to its message
+func NewJobRequest(req *types.PeerJobRequest) *JobRequest {
+	return &JobRequest{
+		ServiceIdRequest: req.ServiceIdRequest,
+		ServiceIdTracing: req.ServiceIdTracing,
+		FunctionName:     req.FunctionName,
+		Hops:             int32(req.Hops),
+		PeersList:        newPeersList(req.PeersList),
+		ContentType:      req.ContentType,
+		Headers:          req.Headers,
+	}
+}
+
+// ToPeerJobRequest converts the message to the job request
+func (x *JobRequest) ToPeerJobRequest() *types.PeerJobRequest {
+	return &types.PeerJobRequest{
+		ServiceIdRequest: x.GetServiceIdRequest(),
+		ServiceIdTracing: x.GetServiceIdTracing(),
+		FunctionName:     x.GetFunctionName(),
+		Hops:             int(x.GetHops()),
+		PeersList:        toPeersList(x.GetPeersList()),
+		ContentType:      x.GetContentType(),
+		Headers:          x.GetHeaders(),
+	}
+}
+
+// NewJobResponse converts the job response to its message, the body is sent in chunks
+func NewJobResponse(res *types.PeerJobResponse) *JobResponse {
+	attempts := make([]*ExecutionAttempt, 0, len(res.Attempts))
+	for _, attempt := range res.Attempts {
+		attempts = append(attempts, &ExecutionAttempt{
+			MachineIp:  attempt.MachineIp,
+			StatusCode: int32(attempt.StatusCode),
+			Error:      attempt.Error,
+			Time:       attempt.Time,
+		})
+	}
+
+	return &JobResponse{
+		StatusCode: int32(res.StatusCode),
+		PeersList:  newPeersList(res.PeersList),
+		Attempts:   attempts,
+	}
+}
+
+// ToPeerJobResponse converts the message to the job response, without the body
+func (x *JobResponse) ToPeerJobResponse() *types.PeerJobResponse {
+	var attempts []types.ExecutionAttempt
+	for _, attempt := range x.GetAttempts() {
+		attempts = append(attempts, types.ExecutionAttempt{
+			MachineIp:  attempt.GetMachineIp(),
+			StatusCode: int(attempt.GetStatusCode()),
+			Error:      attempt.GetError(),
+			Time:       attempt.GetTime(),
+		})
+	}
+
+	return &types.PeerJobResponse{
+		StatusCode: int(x.GetStatusCode()),
+		PeersList:  toPeersList(x.GetPeersList()),
+		Attempts:   attempts,
+	}
+}
+
+func newPeersList(peersList []types.PeersListMember) []*PeersListMember {
+	out := make([]*PeersListMember, 0, len(peersList))
+	for _, peer := range peersList {
+		out = append(out, &PeersListMember{
+			MachineId: peer.MachineId,
+			MachineIp: peer.MachineIp,
+			Timings: &Timings{
+				ExecutionTime:  peer.Timings.ExecutionTime,
+				TotalTime:      peer.Timings.TotalTime,
+				SchedulingTime: peer.Timings.SchedulingTime,
+				ProbingTime:    peer.Timings.ProbingTime,
+				ColdStart:      peer.Timings.ColdStart,
+			},
+		})
+	}
+	return out
+}
+
+func toPeersList(peersList []*PeersListMember) []types.PeersListMember {
+	out := make([]types.PeersListMember, 0, len(peersList))
+	for _, peer := range peersList {
+		timings := peer.GetTimings()
+		if timings == nil {
+			timings = &Timings{}
+		}
+		out = append(out, types.PeersListMember{
+			MachineId: peer.GetMachineId(),
+			MachineIp: peer.GetMachineIp(),
+			Timings: types.Timings{
+				ExecutionTime:  timings.ExecutionTime,
+				TotalTime:      timings.TotalTime,
+				SchedulingTime: timings.SchedulingTime,
+				ProbingTime:    timings.ProbingTime,
+				ColdStart:      timings.ColdStart,
+			},
+		})
+	}
+	return out
+}
@@ -0,0 +1,252 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             (unknown)
+// source: peer.proto
+
+package peer_proto
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// PeerClient is the client API for Peer service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type PeerClient interface {
+	// GetLoad returns the current load of the node
+	GetLoad(ctx context.Context, in *LoadRequest, opts ...grpc.CallOption) (*Load, error)
+	// ExecuteFunction executes a job. The first request carries the job and the next ones the chunks of the payload, the
+	// first response carries the job response and the next ones the chunks of the body.
+	ExecuteFunction(ctx context.Context, opts ...grpc.CallOption) (Peer_ExecuteFunctionClient, error)
+	// SubscribeLoad sends the load of the node at the interval requested by the subscriber, which can change it at any
+	// time by sending a new subscription
+	SubscribeLoad(ctx context.Context, opts ...grpc.CallOption) (Peer_SubscribeLoadClient, error)
+}
+
+type peerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPeerClient(cc grpc.ClientConnInterface) PeerClient {
+	return &peerClient{cc}
+}
+
+func (c *peerClient) GetLoad(ctx context.Context, in *LoadRequest, opts ...grpc.CallOption) (*Load, error) {
+	out := new(Load)
+	err := c.cc.Invoke(ctx, "/p2pfaas.peer.Peer/GetLoad", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *peerClient) ExecuteFunction(ctx context.Context, opts ...grpc.CallOption) (Peer_ExecuteFunctionClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Peer_ServiceDesc.Streams[0], "/p2pfaas.peer.Peer/ExecuteFunction", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &peerExecuteFunctionClient{stream}
+	return x, nil
+}
+
+type Peer_ExecuteFunctionClient interface {
+	Send(*ExecuteFunctionRequest) error
+	Recv() (*ExecuteFunctionResponse, error)
+	grpc.ClientStream
+}
+
+type peerExecuteFunctionClient struct {
+	grpc.ClientStream
+}
+
+func (x *peerExecuteFunctionClient) Send(m *ExecuteFunctionRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *peerExecuteFunctionClient) Recv() (*ExecuteFunctionResponse, error) {
+	m := new(ExecuteFunctionResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *peerClient) SubscribeLoad(ctx context.Context, opts ...grpc.CallOption) (Peer_SubscribeLoadClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Peer_ServiceDesc.Streams[1], "/p2pfaas.peer.Peer/SubscribeLoad", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &peerSubscribeLoadClient{stream}
+	return x, nil
+}
+
+type Peer_SubscribeLoadClient interface {
+	Send(*LoadSubscription) error
+	Recv() (*Load, error)
+	grpc.ClientStream
+}
+
+type peerSubscribeLoadClient struct {
+	grpc.ClientStream
+}
+
+func (x *peerSubscribeLoadClient) Send(m *LoadSubscription) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *peerSubscribeLoadClient) Recv() (*Load, error) {
+	m := new(Load)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// PeerServer is the server API for Peer service.
+// All implementations must embed UnimplementedPeerServer
+// for forward compatibility
+type PeerServer interface {
+	// GetLoad returns the current load of the node
+	GetLoad(context.Context, *LoadRequest) (*Load, error)
+	// ExecuteFunction executes a job. The first request carries the job and the next ones the chunks of the payload, the
+	// first response carries the job response and the next ones the chunks of the body.
+	ExecuteFunction(Peer_ExecuteFunctionServer) error
+	// SubscribeLoad sends the load of the node at the interval requested by the subscriber, which can change it at any
+	// time by sending a new subscription
+	SubscribeLoad(Peer_SubscribeLoadServer) error
+	mustEmbedUnimplementedPeerServer()
+}
+
+// UnimplementedPeerServer must be embedded to have forward compatible implementations.
+type UnimplementedPeerServer struct {
+}
+
+func (UnimplementedPeerServer) GetLoad(context.Context, *LoadRequest) (*Load, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLoad not implemented")
+}
+func (UnimplementedPeerServer) ExecuteFunction(Peer_ExecuteFunctionServer) error {
+	return status.Errorf(codes.Unimplemented, "method ExecuteFunction not implemented")
+}
+func (UnimplementedPeerServer) SubscribeLoad(Peer_SubscribeLoadServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeLoad not implemented")
+}
+func (UnimplementedPeerServer) mustEmbedUnimplementedPeerServer() {}
+
+// UnsafePeerServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PeerServer will
+// result in compilation errors.
+type UnsafePeerServer interface {
+	mustEmbedUnimplementedPeerServer()
+}
+
+func RegisterPeerServer(s grpc.ServiceRegistrar, srv PeerServer) {
+	s.RegisterService(&Peer_ServiceDesc, srv)
+}
+
+func _Peer_GetLoad_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LoadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PeerServer).GetLoad(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/p2pfaas.peer.Peer/GetLoad",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PeerServer).GetLoad(ctx, req.(*LoadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Peer_ExecuteFunction_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(PeerServer).ExecuteFunction(&peerExecuteFunctionServer{stream})
+}
+
+type Peer_ExecuteFunctionServer interface {
+	Send(*ExecuteFunctionResponse) error
+	Recv() (*ExecuteFunctionRequest, error)
+	grpc.ServerStream
+}
+
+type peerExecuteFunctionServer struct {
+	grpc.ServerStream
+}
+
+func (x *peerExecuteFunctionServer) Send(m *ExecuteFunctionResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *peerExecuteFunctionServer) Recv() (*ExecuteFunctionRequest, error) {
+	m := new(ExecuteFunctionRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Peer_SubscribeLoad_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(PeerServer).SubscribeLoad(&peerSubscribeLoadServer{stream})
+}
+
+type Peer_SubscribeLoadServer interface {
+	Send(*Load) error
+	Recv() (*LoadSubscription, error)
+	grpc.ServerStream
+}
+
+type peerSubscribeLoadServer struct {
+	grpc.ServerStream
+}
+
+func (x *peerSubscribeLoadServer) Send(m *Load) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *peerSubscribeLoadServer) Recv() (*LoadSubscription, error) {
+	m := new(LoadSubscription)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Peer_ServiceDesc is the grpc.ServiceDesc for Peer service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Peer_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "p2pfaas.peer.Peer",
+	HandlerType: (*PeerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetLoad",
+			Handler:    _Peer_GetLoad_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ExecuteFunction",
+			Handler:       _Peer_ExecuteFunction_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "SubscribeLoad",
+			Handler:       _Peer_SubscribeLoad_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "peer.proto",
+}
@@ -19,25 +19,44 @@
 package queue
 
 import (
+	"bytes"
+	"context"
+	"io"
+	"scheduler/circuit_breaker"
 	"scheduler/config"
+	"scheduler/errors"
 	"scheduler/faas"
-	"scheduler/faas_openfaas"
+	"scheduler/joblog"
 	"scheduler/log"
 	"scheduler/memdb"
+	"scheduler/retry"
+	"scheduler/types"
+	"scheduler/utils"
 	"time"
 )
 
-// executeNow executes the passed job setting the memdb and unlocking both the job and the consumer semaphores
+// executeNow executes the passed job setting the memdb and unlocking both the job and the consumer semaphores. When the
+// response is streamed the function is considered running until its body is closed.
 func executeNow(job *QueuedJob) {
-	log.Log.Debugf("%s starting execution, with payload %t and type %s", job.Request.ServiceName, job.Request.Payload != nil, job.Request.PayloadContentType)
+	log.Log.Debugf("%s starting execution, with payload %t and type %s", job.Request.ServiceName, job.Request.Payload != nil || job.Request.PayloadStream != nil, job.Request.PayloadContentType)
 
 	_ = memdb.SetFunctionRunning(job.Request.ServiceName, job.Request.ServiceType)
 
 	startExecutionTime := time.Now()
 
-	res, err := faas.FunctionExecute(job.Request.ServiceName, job.Request.Payload, job.Request.PayloadContentType)
+	res, err := functionExecuteWithRetries(job)
 
-	if err != nil {
+	if _, ok := err.(errors.ErrorFaasExecutionTimeout); ok {
+		log.Log.Errorf("Cannot execute service %s: %s", job.Request.ServiceName, err.Error())
+		job.ErrorExecution = true
+		job.ErrorTimeout = true
+		job.Timings.ExecutionTime = time.Since(startExecutionTime).Seconds()
+		memdb.SetFunctionTimedOut(job.Request.ServiceName)
+	} else if _, ok := err.(errors.ErrorFaasCircuitOpen); ok {
+		log.Log.Debugf("Cannot execute service %s: %s", job.Request.ServiceName, err.Error())
+		job.ErrorExecution = true
+		job.ErrorCircuitOpen = true
+	} else if err != nil {
 		log.Log.Errorf("Cannot execute service %s: %s", job.Request.ServiceName, err.Error())
 		job.ErrorExecution = true
 	} else {
@@ -46,16 +65,125 @@ func executeNow(job *QueuedJob) {
 		// save the res
 		job.Response = res
 		job.Timings.ExecutionTime = time.Since(startExecutionTime).Seconds()
-		if config.GetOpenFaasEnabled() {
-			job.Timings.FaasExecutionTime = faas_openfaas.GetDurationFromExecuteApiCallResponse(res)
+		if res.ExecutionTime != nil {
+			job.Timings.FaasExecutionTime = *res.ExecutionTime
 		}
+		job.Timings.ColdStart = res.ColdStart
 	}
 
-	_ = memdb.SetFunctionStopped(job.Request.ServiceName, job.Request.ServiceType)
+	if job.Response != nil && job.Response.BodyStream != nil {
+		body := &streamTimeoutReadCloser{ReadCloser: job.Response.BodyStream, functionName: job.Request.ServiceName}
+		job.Response.BodyStream = utils.NewReadCloserWithCallback(body, func() {
+			job.Timings.ExecutionTime = time.Since(startExecutionTime).Seconds()
+			completeExecution(job)
+		})
+
+		// unlock the http request, which will stream the body
+		job.Semaphore.Signal()
+		return
+	}
+
+	completeExecution(job)
 
 	// unlock the http request
 	job.Semaphore.Signal()
+}
+
+// streamTimeoutReadCloser counts the execution as timed out when the streamed body cannot be read since the execution
+// timeout of the function expired
+type streamTimeoutReadCloser struct {
+	io.ReadCloser
+	functionName string
+	timedOut     bool
+}
+
+func (r *streamTimeoutReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if _, ok := err.(errors.ErrorFaasExecutionTimeout); ok && !r.timedOut {
+		log.Log.Errorf("Cannot stream the body of %s: %s", r.functionName, err.Error())
+		r.timedOut = true
+		memdb.SetFunctionTimedOut(r.functionName)
+	}
+	return n, err
+}
+
+// functionExecuteWithRetries executes the function of the job and retries it in the same slot while the retry policy
+// of the function allows it, timeouts excluded. Every execution is recorded in the attempts of the job and in the
+// circuit breaker of the function. No execution is started while the breaker is open.
+func functionExecuteWithRetries(job *QueuedJob) (*types.FaasApiResponse, error) {
+	policy, retryEnabled := config.GetRetryPolicy(job.Request.ServiceName)
+	maxAttempts := 1
+	// a streamed payload cannot be sent again and peer retries are decided by the node which received the job
+	if retryEnabled && policy.Target == config.RetryTargetLocal && job.Request.PayloadStream == nil {
+		maxAttempts = int(policy.MaxAttempts)
+	}
+
+	for {
+		if !circuit_breaker.Allow(job.Request.ServiceName) {
+			job.Attempts = append(job.Attempts, types.ExecutionAttempt{Error: config.RetryErrorCircuitOpen})
+			return nil, errors.ErrorFaasCircuitOpen{Function: job.Request.ServiceName}
+		}
+
+		startAttemptTime := time.Now()
+		res, err := functionExecute(job.Request)
+		attemptTime := time.Since(startAttemptTime)
+
+		attempt := types.ExecutionAttempt{Time: attemptTime.Seconds()}
+		if res != nil {
+			attempt.StatusCode = res.StatusCode
+		} else if _, ok := err.(errors.ErrorFaasExecutionTimeout); ok {
+			attempt.Error = config.RetryErrorTimeout
+		} else if err != nil {
+			attempt.Error = config.RetryErrorConnection
+		}
+		job.Attempts = append(job.Attempts, attempt)
+
+		// client errors of the function do not tell that the backend is unhealthy
+		circuit_breaker.Record(job.Request.ServiceName, attempt.Error != "" || attempt.StatusCode >= 500, attemptTime)
+
+		if err == nil || len(job.Attempts) >= maxAttempts || !retry.IsRetryable(policy, attempt) {
+			return res, err
+		}
+		// a timed out execution already held the slot for the whole timeout, so it is retried only on peers
+		if attempt.Error == config.RetryErrorTimeout {
+			return res, err
+		}
+
+		backoff := retry.Backoff(policy, len(job.Attempts))
+		log.Log.Debugf("%s attempt %d failed, retrying in %s: %s", job.Request.ServiceName, len(job.Attempts), backoff, err.Error())
+		time.Sleep(backoff)
+	}
+}
+
+// completeExecution marks the job as completed and frees its consumer slot
+func completeExecution(job *QueuedJob) {
+	_ = memdb.SetFunctionStopped(job.Request.ServiceName, job.Request.ServiceType)
+
+	// async jobs are completed when their result is delivered
+	if !job.Request.Async {
+		_ = joblog.Completed(job.Request.JobLogId)
+	}
 
 	// unlock consumers
 	consumersSem.Signal()
 }
+
+// functionExecute executes the function of the request, the backend aborts the call when the execution timeout of the
+// function is exceeded and returns errors.ErrorFaasExecutionTimeout
+func functionExecute(req *types.ServiceRequest) (*types.FaasApiResponse, error) {
+	ctx := context.Background()
+
+	if req.Stream {
+		payload := req.PayloadStream
+		if payload == nil {
+			payload = bytes.NewReader(req.Payload)
+		}
+		return faas.FunctionExecuteStream(ctx, req.ServiceName, payload, req.PayloadContentType)
+	}
+
+	// the backend is called with the whole payload when the response is not streamed
+	if err := req.ReadPayload(); err != nil {
+		return nil, err
+	}
+	return faas.FunctionExecute(ctx, req.ServiceName, req.Payload, req.PayloadContentType)
+}
@@ -21,6 +21,7 @@ package queue
 
 import (
 	"scheduler/config"
+	"scheduler/joblog"
 	"scheduler/log"
 	"scheduler/memdb"
 	"scheduler/metrics"
@@ -33,6 +34,7 @@ import (
 var jobsQueue []*QueuedJob
 var jobsQueueLength = 0
 var jobsQueueLengthOfTypes = make(map[int64]int64)
+var jobsQueueLengthOfFunctions = make(map[string]int)
 
 // implementing N producers fixed N consumers
 
@@ -47,13 +49,47 @@ func init() {
 	metrics.PostQueueSize(int(config.GetQueueLengthMax()))
 }
 
+// Start replays the queued jobs which were accepted but not completed before the last shutdown
+func Start() {
+	for _, entry := range joblog.GetPending(joblog.KindQueued) {
+		req := entry.ServiceRequest()
+		req.Id = memdb.GetNextRequestNumber()
+
+		log.Log.Infof("[R#%d,T%s] Replaying job %s from job log", req.Id, req.IdTracing, req.ServiceName)
+
+		go func() {
+			_, err := enqueueJob(req, true)
+			if err != nil {
+				log.Log.Errorf("[R#%d,T%s] Cannot replay job: %s", req.Id, req.IdTracing, err)
+			}
+		}()
+	}
+}
+
 // EnqueueJob enqueues the passed job in the queue and it blocks the caller until the job has been executed
 func EnqueueJob(request *types.ServiceRequest) (*QueuedJob, error) {
+	return enqueueJob(request, false)
+}
+
+// enqueueJob enqueues the passed job, if force is true the job is enqueued even if the queue is full
+func enqueueJob(request *types.ServiceRequest, force bool) (*QueuedJob, error) {
+	// record the job before it can be executed, outside the critical section since the record may be synced to disk.
+	// Async jobs are recorded by the async module.
+	recorded := false
+	if !request.Async && request.JobLogId == "" {
+		id, _ := joblog.Accepted(joblog.KindQueued, request, "")
+		recorded = id != ""
+	}
+
 	mutex.Lock()
 
-	if jobsQueueLength > 0 && jobsQueueLength >= int(config.GetQueueLengthMax()) {
+	if !force && jobsQueueLength > 0 && jobsQueueLength >= int(config.GetQueueLengthMax()) {
 		log.Log.Debugf("[R#%d] Cannot enqueue job %s, queue is full", request.Id, request.ServiceName)
 		mutex.Unlock()
+		// the rejected job must not be replayed
+		if recorded {
+			_ = joblog.Completed(request.JobLogId)
+		}
 		return nil, ErrorFull{}
 	}
 
@@ -72,6 +108,7 @@ func EnqueueJob(request *types.ServiceRequest) (*QueuedJob, error) {
 	jobsQueue = append(jobsQueue, job)
 	jobsQueueLength += 1
 	lengthIncreaseOfType(request.ServiceType)
+	jobsQueueLengthOfFunctions[request.ServiceName] += 1
 
 	log.Log.Debugf("[R#%d] Enqueued job %s", job.Request.Id, job.Request.ServiceName)
 
@@ -92,6 +129,10 @@ func EnqueueJob(request *types.ServiceRequest) (*QueuedJob, error) {
 
 	// stop time
 	job.Timings.QueueTime = time.Since(startQueueTime).Seconds()
+	if !job.ErrorExecution {
+		memdb.PostFunctionLatency(request.ServiceName, job.Timings.QueueTime)
+		memdb.PostFunctionExecutionTime(request.ServiceName, job.Timings.ExecutionTime)
+	}
 
 	return job, nil
 }
@@ -110,6 +151,7 @@ func dequeueJob() *QueuedJob {
 
 	jobsQueueLength -= 1
 	lengthDecreaseOfType(job.Request.ServiceType)
+	lengthDecreaseOfFunction(job.Request.ServiceName)
 
 	// metrics
 	metrics.PostQueueFreedSlot()
@@ -138,10 +180,36 @@ func GetLengthOfTypes() map[int64]int64 {
 	return out
 }
 
+// GetLengthOfFunctions returns the number of queued jobs of every function
+func GetLengthOfFunctions() map[string]int {
+	out := make(map[string]int)
+
+	mutex.Lock()
+	for name, length := range jobsQueueLengthOfFunctions {
+		out[name] = length
+	}
+	mutex.Unlock()
+
+	return out
+}
+
 /*
  * Internal
  */
 
+func lengthDecreaseOfFunction(functionName string) {
+	num, exists := jobsQueueLengthOfFunctions[functionName]
+	if !exists {
+		return
+	}
+
+	if num <= 1 {
+		delete(jobsQueueLengthOfFunctions, functionName)
+		return
+	}
+	jobsQueueLengthOfFunctions[functionName] = num - 1
+}
+
 func lengthIncreaseOfType(jobType int64) {
 	num, exists := jobsQueueLengthOfTypes[jobType]
 	if !exists {
@@ -28,13 +28,20 @@ type QueuedJob struct {
 	Semaphore      *utils.Semaphore
 	Response       *types.FaasApiResponse
 	ErrorExecution bool
-	Timings        *Timings
+	// ErrorTimeout is true when the execution exceeded the function timeout
+	ErrorTimeout bool
+	// ErrorCircuitOpen is true when the execution was not started since the circuit breaker of the function was open
+	ErrorCircuitOpen bool
+	Timings          *Timings
+	// Attempts are the executions of the job, more than one if it has been retried
+	Attempts []types.ExecutionAttempt
 }
 
 type Timings struct {
 	ExecutionTime     float64 `json:"execution_time"`      // the time of executing the job comprising the GET to openfaas
-	FaasExecutionTime float64 `json:"faas_execution_time"` // the execution time as it is told by openfaas
+	FaasExecutionTime float64 `json:"faas_execution_time"` // the execution time as it is told by the faas backend
 	QueueTime         float64 `json:"queue_time"`          // the time in which the job remains in the local queue (comprises the execution time)
+	ColdStart         *bool   `json:"cold_start"`          // if the execution paid a cold start of the function, nil if unknown
 	// ForwardingTime    float64 `json:"forwarding_time"`     // total time for forwarding the job to another machine
 	// ProbingTime       float64 `json:"probing_time"`        // average of time for probing all machines in the fanout (if applicable)
 }
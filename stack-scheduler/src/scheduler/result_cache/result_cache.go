@@ -0,0 +1,121 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package result_cache memoizes the results of deterministic functions, in such a way that the same payload sent again
+// to a function is replied without executing it. Entries expire after the ttl of the function and the least recently
+// used ones are evicted when the memory bound is reached.
+package result_cache
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"scheduler/config"
+	"scheduler/log"
+	"sync"
+	"time"
+)
+
+var entries = map[string]*list.Element{}
+var entriesLru = list.New()
+var entriesSize uint64 = 0
+var entriesMutex sync.Mutex
+
+// Key returns the key of the result of the passed function with the passed payload
+func Key(functionName string, payload []byte) string {
+	hash := sha256.Sum256(payload)
+	return functionName + "/" + hex.EncodeToString(hash[:])
+}
+
+// Get returns the cached result of the passed function with the passed payload, false if there is no valid one
+func Get(functionName string, payload []byte) (*Entry, bool) {
+	key := Key(functionName, payload)
+
+	entriesMutex.Lock()
+	defer entriesMutex.Unlock()
+
+	element, exists := entries[key]
+	if !exists {
+		return nil, false
+	}
+	entry := element.Value.(*Entry)
+	if time.Now().After(entry.ExpiresAt) {
+		remove(element)
+		return nil, false
+	}
+
+	entriesLru.MoveToFront(element)
+	return entry, true
+}
+
+// Put caches the result of the passed function with the passed payload for ttl, only successful results are cached
+func Put(functionName string, payload []byte, statusCode int, headers http.Header, body []byte, ttl time.Duration) {
+	if statusCode < 200 || statusCode > 299 {
+		return
+	}
+
+	entry := &Entry{
+		key:          Key(functionName, payload),
+		FunctionName: functionName,
+		StatusCode:   statusCode,
+		Headers:      headers.Clone(),
+		Body:         body,
+		ExpiresAt:    time.Now().Add(ttl),
+	}
+	entry.size = entry.computeSize()
+
+	maxSize := uint64(config.GetCacheMaxSize())
+	if entry.size > maxSize {
+		log.Log.Debugf("Result of %s not cached since its size %d exceeds the cache size", functionName, entry.size)
+		return
+	}
+
+	entriesMutex.Lock()
+	defer entriesMutex.Unlock()
+
+	if element, exists := entries[entry.key]; exists {
+		remove(element)
+	}
+	for entriesSize+entry.size > maxSize {
+		remove(entriesLru.Back())
+	}
+
+	entries[entry.key] = entriesLru.PushFront(entry)
+	entriesSize += entry.size
+}
+
+// RemoveFunction drops all the cached results of the passed function, for example because it has been updated
+func RemoveFunction(functionName string) {
+	entriesMutex.Lock()
+	defer entriesMutex.Unlock()
+
+	for element := entriesLru.Front(); element != nil; {
+		next := element.Next()
+		if element.Value.(*Entry).FunctionName == functionName {
+			remove(element)
+		}
+		element = next
+	}
+}
+
+func remove(element *list.Element) {
+	entry := entriesLru.Remove(element).(*Entry)
+	delete(entries, entry.key)
+	entriesSize -= entry.size
+}
@@ -0,0 +1,168 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package result_cache
+
+import (
+	"container/list"
+	"fmt"
+	"net/http"
+	"scheduler/config"
+	"testing"
+	"time"
+)
+
+// testEntrySize is the size of an entry of function fn with a body of 10 bytes and no headers
+const testEntrySize = 2 + 1 + 64 + 2 + 10
+
+// startTestCache empties the cache and bounds it to the passed number of test entries
+func startTestCache(t *testing.T, maxEntries int) {
+	t.Setenv(config.EnvCacheMaxSize, fmt.Sprintf("%d", maxEntries*testEntrySize))
+	config.InitConfigurationStatic()
+
+	entriesMutex.Lock()
+	entries = map[string]*list.Element{}
+	entriesLru = list.New()
+	entriesSize = 0
+	entriesMutex.Unlock()
+}
+
+func putTestEntry(payload string, ttl time.Duration) {
+	Put("fn", []byte(payload), 200, http.Header{}, []byte("0123456789"), ttl)
+}
+
+func isCached(payload string) bool {
+	_, hit := Get("fn", []byte(payload))
+	return hit
+}
+
+func TestGetCachedResult(t *testing.T) {
+	startTestCache(t, 4)
+
+	Put("fn", []byte("a"), 200, http.Header{"X-Test": []string{"1"}}, []byte("result"), time.Minute)
+	entry, hit := Get("fn", []byte("a"))
+	if !hit {
+		t.Fatalf("expected a hit")
+	}
+	if entry.StatusCode != 200 || string(entry.Body) != "result" || entry.Headers.Get("X-Test") != "1" {
+		t.Fatalf("unexpected entry %+v", entry)
+	}
+	if _, hit := Get("fn", []byte("b")); hit {
+		t.Fatalf("expected a miss for another payload")
+	}
+	if _, hit := Get("other", []byte("a")); hit {
+		t.Fatalf("expected a miss for another function")
+	}
+}
+
+func TestFailedResultsAreNotCached(t *testing.T) {
+	startTestCache(t, 4)
+
+	Put("fn", []byte("a"), 500, http.Header{}, []byte("0123456789"), time.Minute)
+	if isCached("a") {
+		t.Fatalf("expected a failed result not to be cached")
+	}
+}
+
+func TestEntriesExpireAfterTtl(t *testing.T) {
+	startTestCache(t, 4)
+
+	putTestEntry("a", 20*time.Millisecond)
+	putTestEntry("b", time.Minute)
+	if !isCached("a") {
+		t.Fatalf("expected a hit before the ttl")
+	}
+
+	time.Sleep(40 * time.Millisecond)
+	if isCached("a") {
+		t.Fatalf("expected a miss after the ttl")
+	}
+	if !isCached("b") {
+		t.Fatalf("expected b not to be expired")
+	}
+	if entriesSize != testEntrySize {
+		t.Fatalf("expected the expired entry to be removed, the cache size is %d", entriesSize)
+	}
+}
+
+func TestLeastRecentlyUsedIsEvicted(t *testing.T) {
+	startTestCache(t, 3)
+
+	putTestEntry("a", time.Minute)
+	putTestEntry("b", time.Minute)
+	putTestEntry("c", time.Minute)
+	// a becomes the most recently used, b the least
+	isCached("a")
+	putTestEntry("d", time.Minute)
+
+	if isCached("b") {
+		t.Fatalf("expected b to be evicted")
+	}
+	for _, payload := range []string{"a", "c", "d"} {
+		if !isCached(payload) {
+			t.Fatalf("expected %s to be cached", payload)
+		}
+	}
+	if entriesSize != 3*testEntrySize {
+		t.Fatalf("expected the cache size to be %d, got %d", 3*testEntrySize, entriesSize)
+	}
+}
+
+func TestPutReplacesEntry(t *testing.T) {
+	startTestCache(t, 2)
+
+	putTestEntry("a", time.Minute)
+	putTestEntry("a", time.Minute)
+	putTestEntry("b", time.Minute)
+
+	if !isCached("a") || !isCached("b") {
+		t.Fatalf("expected both entries to be cached")
+	}
+	if entriesLru.Len() != 2 || entriesSize != 2*testEntrySize {
+		t.Fatalf("expected 2 entries, got %d of size %d", entriesLru.Len(), entriesSize)
+	}
+}
+
+func TestOversizedResultIsNotCached(t *testing.T) {
+	startTestCache(t, 1)
+
+	putTestEntry("a", time.Minute)
+	Put("fn", []byte("b"), 200, http.Header{}, make([]byte, 2*testEntrySize), time.Minute)
+
+	if isCached("b") {
+		t.Fatalf("expected the oversized result not to be cached")
+	}
+	if !isCached("a") {
+		t.Fatalf("expected the oversized result not to evict other entries")
+	}
+}
+
+func TestRemoveFunction(t *testing.T) {
+	startTestCache(t, 4)
+
+	putTestEntry("a", time.Minute)
+	Put("other", []byte("a"), 200, http.Header{}, []byte("0123456789"), time.Minute)
+	RemoveFunction("fn")
+
+	if isCached("a") {
+		t.Fatalf("expected the results of fn to be removed")
+	}
+	if _, hit := Get("other", []byte("a")); !hit {
+		t.Fatalf("expected the results of other to be kept")
+	}
+}
@@ -0,0 +1,47 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package result_cache
+
+import (
+	"net/http"
+	"time"
+)
+
+// Entry is a cached result of a function
+type Entry struct {
+	key          string
+	size         uint64
+	FunctionName string
+	StatusCode   int
+	Headers      http.Header
+	Body         []byte
+	ExpiresAt    time.Time
+}
+
+// computeSize returns the approximate memory used by the entry
+func (e *Entry) computeSize() uint64 {
+	size := len(e.key) + len(e.FunctionName) + len(e.Body)
+	for key, values := range e.Headers {
+		size += len(key)
+		for _, value := range values {
+			size += len(value)
+		}
+	}
+	return uint64(size)
+}
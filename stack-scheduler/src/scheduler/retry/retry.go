@@ -0,0 +1,61 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// Package retry implements the decisions about retrying the failed executions of functions according to their retry
+// policy.
+package retry
+
+import (
+	"math"
+	"scheduler/config"
+	"scheduler/types"
+	"time"
+)
+
+// IsRetryable tells if the passed failed attempt must be retried according to the policy
+func IsRetryable(policy config.RetryPolicy, attempt types.ExecutionAttempt) bool {
+	if attempt.StatusCode != 0 {
+		for _, statusCode := range policy.RetryableStatusCodes {
+			if statusCode == attempt.StatusCode {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, errorClass := range policy.RetryableErrors {
+		if errorClass == attempt.Error {
+			return true
+		}
+	}
+	return false
+}
+
+// Backoff returns the time to wait before the passed retry, the first retry is 1
+func Backoff(policy config.RetryPolicy, retry int) time.Duration {
+	multiplier := policy.BackoffMultiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	backoff := float64(policy.Backoff) * math.Pow(multiplier, float64(retry-1))
+	if policy.BackoffMax > 0 && backoff > float64(policy.BackoffMax) {
+		backoff = float64(policy.BackoffMax)
+	}
+	return time.Duration(backoff) * time.Millisecond
+}
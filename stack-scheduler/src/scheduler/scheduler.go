@@ -20,20 +20,34 @@
 package main
 
 import (
+	"crypto/tls"
 	"fmt"
 	"github.com/gorilla/mux"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"net"
 	"net/http"
 	"os"
 	"scheduler/api"
+	"scheduler/api/api_grpc"
 	"scheduler/api/api_monitoring"
 	"scheduler/api/api_peer"
+	"scheduler/async"
+	"scheduler/autoscaler"
+	"scheduler/cluster"
 	"scheduler/config"
+	"scheduler/gossip"
+	"scheduler/joblog"
 	"scheduler/log"
+	"scheduler/mtls"
 	"scheduler/queue"
 	"scheduler/scheduler"
+	"scheduler/scheduler_service"
 	"scheduler/service_discovery"
+	"scheduler/utils"
 	"strings"
 	"sync"
+	"time"
 )
 
 import _ "net/http/pprof"
@@ -45,12 +59,24 @@ func main() {
 
 	// init modules
 	config.Start()
+	joblog.Start()
 	scheduler.Start()
 	service_discovery.Start()
 	// metrics.Start()
 
 	go worker()
+
+	// replay the jobs not completed before the last shutdown
+	queue.Start()
+	async.Start()
+	autoscaler.Start()
+	cluster.Start()
+	scheduler_service.StartFunctionsAdvertisementRefresher()
+	gossip.Start()
 	go server()
+	if config.GetPeerTransport() == config.PeerTransportGrpc {
+		go grpcServer()
+	}
 
 	// Check if profiling should be enabled
 	if strings.ToLower(os.Getenv(config.EnvProfiling)) == "true" {
@@ -74,12 +100,22 @@ func server() {
 	router.HandleFunc("/system/functions", api.SystemFunctionsPut).Methods("PUT")
 	router.HandleFunc("/system/functions", api.SystemFunctionsDelete).Methods("DELETE")
 	router.HandleFunc("/system/function/{function}", api.SystemFunctionGet).Methods("GET")
+	router.HandleFunc("/system/cluster-deployments", api.SystemClusterDeploymentsGet).Methods("GET")
+	router.HandleFunc("/system/cluster-deployments/{id}", api.SystemClusterDeploymentGet).Methods("GET")
 	router.HandleFunc("/system/scale-function/{function}", api.SystemScaleFunctionPost).Methods("POST")
 	router.HandleFunc("/function/{function}", api.FunctionPost).Methods("POST")
 	router.HandleFunc("/function/{function}", api.FunctionGet).Methods("GET")
+	router.HandleFunc("/async-function/{function}", api.FunctionAsyncPost).Methods("POST")
 	// new APIs
 	router.HandleFunc("/monitoring/load", api_monitoring.LoadGetLoad).Methods("GET")
 	router.HandleFunc("/monitoring/scale-delay/{function}", api_monitoring.ScaleDelay).Methods("GET")
+	router.HandleFunc("/monitoring/timeouts", api_monitoring.TimeoutsGet).Methods("GET")
+	router.HandleFunc("/monitoring/autoscaler", api_monitoring.AutoscalerGet).Methods("GET")
+	router.HandleFunc("/monitoring/peers-functions", api_monitoring.PeersFunctionsGet).Methods("GET")
+	router.HandleFunc("/monitoring/circuit-breakers", api_monitoring.CircuitBreakersGet).Methods("GET")
+	router.HandleFunc("/monitoring/peers-health", api_monitoring.PeersHealthGet).Methods("GET")
+	router.HandleFunc("/monitoring/links", api_monitoring.LinksGet).Methods("GET")
+	router.HandleFunc("/monitoring/cluster-load", api_monitoring.ClusterLoadGet).Methods("GET")
 	router.HandleFunc("/peer/function/{function}", api_peer.FunctionExecute).Methods("POST")
 	// prometheus
 	// router.Handle("/metrics", promhttp.Handler())
@@ -104,19 +140,53 @@ func server() {
 		router.HandleFunc("/dev/test/parallel", api.TestDevParallelRequests).Methods("GET")
 	}
 
-	server := &http.Server{
-		Addr:    fmt.Sprintf("0.0.0.0:%d", config.GetListeningPort()),
-		Handler: router,
+	// peers which enabled http/2 send their requests over h2c, the other requests are served as usual
+	idleTimeout := time.Duration(config.GetPeerIdleConnTimeout()) * time.Millisecond
+	var handler http.Handler = router
+	if utils.IsMachineHttp2Enabled() && !mtls.Enabled() {
+		handler = h2c.NewHandler(router, &http2.Server{IdleTimeout: idleTimeout})
 	}
-	server.SetKeepAlivesEnabled(false)
 
-	log.Log.Infof("Started listening on %d", config.GetListeningPort())
-	err := server.ListenAndServe()
+	server := &http.Server{
+		Addr:        fmt.Sprintf("0.0.0.0:%d", config.GetListeningPort()),
+		Handler:     handler,
+		IdleTimeout: idleTimeout,
+	}
+	server.SetKeepAlivesEnabled(config.GetPeerKeepAlive())
+
+	var err error
+	if mtls.Enabled() {
+		// clients are not required to present a certificate, the peer apis check it
+		nextProtos := []string{"http/1.1"}
+		if utils.IsMachineHttp2Enabled() {
+			nextProtos = []string{"h2", "http/1.1"}
+		}
+		server.TLSConfig = mtls.ServerConfig(tls.VerifyClientCertIfGiven, nextProtos)
+
+		log.Log.Infof("Started listening with mutual tls on %d", config.GetListeningPort())
+		err = server.ListenAndServeTLS("", "")
+	} else {
+		log.Log.Infof("Started listening on %d", config.GetListeningPort())
+		err = server.ListenAndServe()
+	}
 
 	log.Log.Fatalf("Error while starting server: %s", err)
 	wg.Done()
 }
 
+// grpcServer serves the grpc peer service, used by the other nodes when the cluster uses the grpc peer transport
+func grpcServer() {
+	listener, err := net.Listen("tcp", fmt.Sprintf("0.0.0.0:%d", config.GetGrpcListeningPort()))
+	if err != nil {
+		log.Log.Fatalf("Cannot listen for the grpc peer service: %s", err)
+	}
+
+	log.Log.Infof("Started grpc peer service on %d", config.GetGrpcListeningPort())
+	err = api_grpc.NewServer().Serve(listener)
+
+	log.Log.Fatalf("Error while serving the grpc peer service: %s", err)
+}
+
 func worker() {
 	log.Log.Debugf("Starting queue worker thread")
 	queue.Looper()
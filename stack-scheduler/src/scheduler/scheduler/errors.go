@@ -45,6 +45,22 @@ func (e PeerResponseNil) Error() string {
 	return fmt.Sprintf("Peer %s response is nil", e.neighborHost)
 }
 
+type JobExecutionTimeout struct {
+	reason string
+}
+
+func (e JobExecutionTimeout) Error() string {
+	return fmt.Sprintf("Job execution timed out: %s", e.reason)
+}
+
+type JobCircuitOpen struct {
+	function string
+}
+
+func (e JobCircuitOpen) Error() string {
+	return fmt.Sprintf("Job cannot be executed: the circuit breaker of %s is open", e.function)
+}
+
 type JobDeliberatelyRejected struct {
 }
 
@@ -28,7 +28,8 @@ import (
 
 const ForwardSchedulerName = "ForwardScheduler"
 
-// ForwardScheduler scheduler forwards all the requests to a random node, this is used for testing purposes
+// ForwardScheduler scheduler forwards all the requests to a random node hosting the function, this is used for testing
+// purposes
 type ForwardScheduler struct {
 	// MaxHops is the maximum number of hops that a request can be subjected to before being executed
 	MaxHops uint
@@ -60,8 +61,8 @@ func (s ForwardScheduler) Schedule(req *types.ServiceRequest) (*JobResult, error
 		// save time
 		startedProbingTime := time.Now()
 		timingsStart.ProbingStartedAt = &startedProbingTime
-		// get N Random machines and ask them for load and pick the least loaded
-		randomMachine, err := service_discovery.GetNRandomMachines(1, true)
+		// get a random machine which hosts the function
+		randomMachine, err := service_discovery.GetNRandomMachinesHostingFunction(1, true, req.ServiceName)
 		// save time
 		endProbingTime := time.Now()
 		timingsStart.ProbingEndedAt = &endProbingTime
@@ -164,7 +164,7 @@ func (s LearningScheduler) Schedule(req *types.ServiceRequest) (*JobResult, erro
 		startedProbingTime := time.Now()
 		timingsStart.ProbingStartedAt = &startedProbingTime
 		// get N Random machines and ask them for mapRunningFunctionsOfType and pick the least loaded
-		leastLoaded, _, err := scheduler_service.GetLeastLoadedMachineOfNRandom(1, uint(totalLoad), true, true)
+		leastLoaded, _, err := scheduler_service.GetLeastLoadedMachineOfNRandom(1, uint(totalLoad), true, true, req.ServiceName)
 		// save time
 		endProbingTime := time.Now()
 		timingsStart.ProbingEndedAt = &endProbingTime
@@ -192,6 +192,15 @@ func (s LearningScheduler) Schedule(req *types.ServiceRequest) (*JobResult, erro
 		log.Log.Errorf("Cannot schedule job to machine i=%d of %d: %s", targetMachineI, service_discovery.GetCachedMachineNumber(), err)
 		return nil, CannotRetrieveRecipientNode{err}
 	}
+	// the action cannot be remapped to another machine, so if the target does not host the function the job is
+	// executed here as when probing fails
+	if !service_discovery.MachineHostsFunction(targetMachineIp, req.ServiceName) {
+		log.Log.Debugf("Machine %s does not host function %s, executing locally", targetMachineIp, req.ServiceName)
+		jobResult, err = executeJobLocally(req, &timingsStart, s.GetFullName())
+		s.addHeadersToResult(jobResult, req.Id, state, actionRes.Action, eps)
+
+		return jobResult, err
+	}
 	log.Log.Debugf("Forwarding to machine %s", targetMachineIp)
 
 	jobResult, err = executeJobExternally(req, targetMachineIp, &timingsStart, s.GetFullName())
@@ -80,7 +80,7 @@ func (s PowerOfNScheduler) Schedule(req *types.ServiceRequest) (*JobResult, erro
 		startedProbingTime := time.Now()
 		timingsStart.ProbingStartedAt = &startedProbingTime
 		// get N Random machines and ask them for load and pick the least loaded
-		leastLoaded, _, err := scheduler_service.GetLeastLoadedMachineOfNRandom(s.F, currentLoad, !s.Loss, true)
+		leastLoaded, _, err := scheduler_service.GetLeastLoadedMachineOfNRandom(s.F, currentLoad, !s.Loss, true, req.ServiceName)
 		// save time
 		endProbingTime := time.Now()
 		timingsStart.ProbingEndedAt = &endProbingTime
@@ -0,0 +1,103 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package scheduler
+
+import (
+	"fmt"
+	"scheduler/config"
+	"scheduler/log"
+	"scheduler/memdb"
+	"scheduler/queue"
+	"scheduler/scheduler_service"
+	"scheduler/types"
+	"time"
+)
+
+const PowerOfNTransferSchedulerName = "PowerOfNTransferScheduler"
+
+// PowerOfNTransferScheduler implements the power-of-n choices scheduler in which the probed machines are compared by
+// the expected completion of the job, comprising the transfer of the payload on the link to the machine, and the job
+// is forwarded only if it completes there before than in this node
+type PowerOfNTransferScheduler struct {
+	// F is the fan-out, that is the number of probed nodes
+	F uint
+	// T is threshold, that from which number of currently executing tasks the probing to others is started
+	T uint
+	// MaxHops is the maximum number of hops that a request can be subjected to before being executed
+	MaxHops uint
+}
+
+func (s PowerOfNTransferScheduler) GetFullName() string {
+	return fmt.Sprintf("%s(%d, %d, %d)", PowerOfNTransferSchedulerName, s.F, s.T, s.MaxHops)
+}
+
+func (s PowerOfNTransferScheduler) GetScheduler() *types.SchedulerDescriptor {
+	return &types.SchedulerDescriptor{
+		Name: PowerOfNTransferSchedulerName,
+		Parameters: []string{
+			fmt.Sprintf("%d", s.F),
+			fmt.Sprintf("%d", s.T),
+			fmt.Sprintf("%d", s.MaxHops),
+		},
+	}
+}
+
+// Schedule a service request. This call is blocking until the job has been executed locally or externally.
+func (s PowerOfNTransferScheduler) Schedule(req *types.ServiceRequest) (*JobResult, error) {
+	log.Log.Debugf("[R#%d] Scheduling job %s", req.Id, req.ServiceName)
+	currentLoad := memdb.GetTotalRunningFunctions() + uint(queue.GetLength())
+	startedScheduling := time.Now()
+	timingsStart := types.TimingsStart{ArrivedAt: &startedScheduling}
+
+	balancingHit := currentLoad >= s.T
+	jobMustExecutedHere := req.External && req.ExternalJobRequest.Hops >= int(s.MaxHops)
+
+	// the completion cannot be estimated until the function has been executed in this node
+	executionTime := 0.0
+	if function, err := memdb.GetFunction(req.ServiceName); err == nil {
+		executionTime = function.ExecutionTimeAverage
+	}
+
+	log.Log.Debugf("[R#%d] balancingHit %t - jobMustExecutedHere %t - executionTime %.3fs", req.Id, balancingHit, jobMustExecutedHere, executionTime)
+
+	if balancingHit && !jobMustExecutedHere && executionTime > 0 {
+		startedProbingTime := time.Now()
+		timingsStart.ProbingStartedAt = &startedProbingTime
+
+		// a streamed payload whose length is not declared is not counted in the transfer
+		payloadLength := req.GetPayloadLength()
+		if payloadLength < 0 {
+			payloadLength = 0
+		}
+		localCompletion := scheduler_service.EstimateCompletion(currentLoad, config.GetRunningFunctionMax(), executionTime)
+		fastest, _, err := scheduler_service.GetFastestMachineOfNRandom(s.F, localCompletion, executionTime, int(payloadLength), true, req.ServiceName)
+
+		endProbingTime := time.Now()
+		timingsStart.ProbingEndedAt = &endProbingTime
+
+		if err != nil {
+			log.Log.Debugf("[R#%d] No machine completes the job before this one: %s", req.Id, err.Error())
+			return executeJobLocally(req, &timingsStart, s.GetFullName())
+		}
+
+		return executeJobExternally(req, fastest, &timingsStart, s.GetFullName())
+	}
+
+	return executeJobLocally(req, &timingsStart, s.GetFullName())
+}
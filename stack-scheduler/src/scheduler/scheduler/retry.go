@@ -0,0 +1,106 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package scheduler
+
+import (
+	"math"
+	"math/rand"
+	"scheduler/config"
+	"scheduler/log"
+	"scheduler/retry"
+	"scheduler/service_discovery"
+	"scheduler/types"
+	"time"
+)
+
+// scheduleWithRetries schedules the request with the passed scheduler and retries it on other peers when it fails and
+// the retry policy of the function has the peer target. Only the node which received the job from the client retries
+// it on peers, in this way a job is not retried at every hop. Local retries are done by the queue.
+func scheduleWithRetries(s scheduler, req *types.ServiceRequest) (*JobResult, error) {
+	result, err := s.Schedule(req)
+
+	policy, retryEnabled := config.GetRetryPolicy(req.ServiceName)
+	if !retryEnabled || policy.Target != config.RetryTargetPeer || req.External || result == nil {
+		return result, err
+	}
+
+	attempts := result.Attempts
+	var triedMachines []string
+	for _, attempt := range attempts {
+		triedMachines = append(triedMachines, attempt.MachineIp)
+	}
+
+	for len(attempts) > 0 && len(attempts) < int(policy.MaxAttempts) && retry.IsRetryable(policy, attempts[len(attempts)-1]) {
+		// a payload streamed to the function cannot be sent again
+		if req.PayloadStream != nil {
+			break
+		}
+
+		machine, found := pickRetryMachine(req.ServiceName, triedMachines)
+		if !found {
+			log.Log.Debugf("[R#%d,T%s] No other peer hosts %s, job is not retried", req.Id, req.IdTracing, req.ServiceName)
+			break
+		}
+		triedMachines = append(triedMachines, machine)
+
+		if result.Response != nil && result.Response.BodyStream != nil {
+			_ = result.Response.BodyStream.Close()
+		}
+
+		backoff := retry.Backoff(policy, len(attempts))
+		log.Log.Debugf("[R#%d,T%s] Attempt %d failed, retrying at %s in %s", req.Id, req.IdTracing, len(attempts), machine, backoff)
+		time.Sleep(backoff)
+
+		result, err = executeJobExternally(req, machine, result.TimingsStart, result.Scheduler)
+		if result == nil {
+			break
+		}
+		attempts = append(attempts, result.Attempts...)
+		result.Attempts = attempts
+	}
+
+	return result, err
+}
+
+// pickRetryMachine returns a random peer which hosts the function and has not been already tried
+func pickRetryMachine(functionName string, triedMachines []string) (string, bool) {
+	machines, err := service_discovery.GetNRandomMachinesHostingFunction(math.MaxUint32, true, functionName)
+	if err != nil {
+		return "", false
+	}
+
+	var candidates []string
+	for _, machine := range machines {
+		tried := false
+		for _, triedMachine := range triedMachines {
+			if machine == triedMachine {
+				tried = true
+				break
+			}
+		}
+		if !tried {
+			candidates = append(candidates, machine)
+		}
+	}
+	if len(candidates) == 0 {
+		return "", false
+	}
+
+	return candidates[rand.Intn(len(candidates))], true
+}
@@ -68,7 +68,7 @@ func (s *RoundRobinWithMasterScheduler) Schedule(req *types.ServiceRequest) (*Jo
 		if !req.External {
 			return nil, JobCannotBeScheduled{}
 		}
-		// Obtain the list of all machines and select one with a round robin fashion
+		// Obtain the list of all machines hosting the function and select one with a round robin fashion
 		machinesIp, err := service_discovery.GetMachinesIpsList()
 		if err != nil {
 			return nil, JobCannotBeScheduled{err.Error()}
@@ -76,6 +76,10 @@ func (s *RoundRobinWithMasterScheduler) Schedule(req *types.ServiceRequest) (*Jo
 		if len(machinesIp) == 0 {
 			return nil, JobCannotBeScheduled{"no machine known"}
 		}
+		machinesIp = service_discovery.FilterMachinesHostingFunction(machinesIp, req.ServiceName)
+		if len(machinesIp) == 0 {
+			return nil, JobCannotBeScheduled{"no machine hosts the function"}
+		}
 
 		// Update the id of next machine
 		s.currentIndexMutex.Lock()
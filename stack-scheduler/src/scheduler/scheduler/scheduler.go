@@ -104,9 +104,10 @@ func init() {
  * Actions
  */
 
-// Schedule schedules a service request with the current set scheduler
+// Schedule schedules a service request with the current set scheduler, the request is retried on other peers if its
+// retry policy requires it
 func Schedule(req *types.ServiceRequest) (*JobResult, error) {
-	return schedulerCurrent.Schedule(req)
+	return scheduleWithRetries(schedulerCurrent, req)
 }
 
 // ScheduleBypassAlgorithm schedules a service request with the NoScheduler algorithm which always execute locally the function
@@ -116,7 +117,7 @@ func ScheduleBypassAlgorithm(req *types.ServiceRequest) (*JobResult, error) {
 
 // ScheduleForward schedules a service request with the ForwardScheduler algorithm which always forward the request to a random node
 func ScheduleForward(req *types.ServiceRequest) (*JobResult, error) {
-	return schedulerForward.Schedule(req)
+	return scheduleWithRetries(schedulerForward, req)
 }
 
 // ScheduleReject schedules a service request with the RejectScheduler algorithm which always reject the request
@@ -212,6 +213,23 @@ func SetScheduler(sched *types.SchedulerDescriptor) error {
 		}
 		break
 
+	case PowerOfNTransferSchedulerName:
+		if len(sched.Parameters) < 3 {
+			return BadSchedulerParameters{}
+		}
+		f, err1 := strconv.ParseUint(sched.Parameters[0], 10, 32)
+		t, err2 := strconv.ParseUint(sched.Parameters[1], 10, 32)
+		m, err3 := strconv.ParseUint(sched.Parameters[2], 10, 32)
+		if err1 != nil || err2 != nil || err3 != nil {
+			return BadSchedulerParameters{}
+		}
+		schedulerCurrent = &PowerOfNTransferScheduler{
+			F:       uint(f),
+			T:       uint(t),
+			MaxHops: uint(m),
+		}
+		break
+
 	case RoundRobinWithMasterSchedulerName:
 		if len(sched.Parameters) < 3 {
 			return BadSchedulerParameters{}
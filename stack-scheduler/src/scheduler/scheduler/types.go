@@ -33,6 +33,8 @@ type JobResult struct {
 	Timings               *types.Timings         `json:"timings"`
 	ResponseHeaders       *map[string]string     `json:"response_headers"` // custom headers to be returned to clients
 	Scheduler             string                 `json:"scheduler"`        // the scheduler that executed the job
+	// Attempts are the executions of the job, more than one if it has been retried
+	Attempts []types.ExecutionAttempt `json:"attempts"`
 }
 
 // ExternalExecutionInfo holds information about the external execution of the task
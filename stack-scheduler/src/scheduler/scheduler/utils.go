@@ -19,15 +19,19 @@
 package scheduler
 
 import (
-	"encoding/base64"
 	"encoding/json"
+	"fmt"
+	"scheduler/circuit_breaker"
 	"scheduler/config"
+	"scheduler/errors"
 	"scheduler/log"
 	"scheduler/memdb"
 	"scheduler/queue"
 	"scheduler/scheduler_service"
+	"scheduler/service_discovery"
 	"scheduler/types"
 	"scheduler/utils"
+	"time"
 )
 
 /*
@@ -50,11 +54,29 @@ func executeJobExternally(serviceRequest *types.ServiceRequest, remoteNodeIP str
 	// metrics
 	// metrics.PostJobIsForwarded(serviceRequest.ServiceName)
 
-	res, err := scheduler_service.ExecuteFunction(remoteNodeIP, peerRequest)
+	startTime := time.Now()
+	res, err := scheduler_service.ExecuteFunction(remoteNodeIP, peerRequest, serviceRequest.Payload)
+
+	// a draining peer does not accept the job, so it is sent to another peer which hosts the function
+	triedMachines := []string{remoteNodeIP}
+	for _, draining := err.(scheduler_service.ErrorMachineDraining); draining; _, draining = err.(scheduler_service.ErrorMachineDraining) {
+		nextNodeIP, found := pickRetryMachine(serviceRequest.ServiceName, triedMachines)
+		if !found {
+			break
+		}
+		log.Log.Debugf("[R#%d,T%s] %s is draining, forwarding to %s", serviceRequest.Id, serviceRequest.IdTracing, remoteNodeIP, nextNodeIP)
+
+		remoteNodeIP = nextNodeIP
+		triedMachines = append(triedMachines, remoteNodeIP)
+		startTime = time.Now()
+		res, err = scheduler_service.ExecuteFunction(remoteNodeIP, peerRequest, serviceRequest.Payload)
+	}
 
 	/* This is blocking */
 
-	return prepareJobResultFromExternalExecution(serviceRequest, res, err, timingsStart, scheduler, remoteNodeIP)
+	result, err := prepareJobResultFromExternalExecution(serviceRequest, res, err, timingsStart, scheduler, remoteNodeIP)
+	result.Attempts = peerExecutionAttempts(remoteNodeIP, res, time.Since(startTime).Seconds())
+	return result, err
 }
 
 func executeJobLocally(req *types.ServiceRequest, timingsStart *types.TimingsStart, scheduler string) (*JobResult, error) {
@@ -64,6 +86,22 @@ func executeJobLocally(req *types.ServiceRequest, timingsStart *types.TimingsSta
 		timingsStart.ScheduledAt = utils.GetTimeNow()
 	}
 
+	// the job is not queued when the function is failing, so that it can be moved elsewhere at once
+	if circuit_breaker.IsOpen(req.ServiceName) {
+		log.Log.Debugf("[R#%d,T%s] %s cannot be run locally: circuit breaker is open", req.Id, req.IdTracing, req.ServiceName)
+		return &JobResult{
+			Response:          nil,
+			Timings:           &types.Timings{},
+			TimingsStart:      timingsStart,
+			ExternalExecution: false,
+			ErrorExecution:    true,
+			Scheduler:         scheduler,
+			Attempts: []types.ExecutionAttempt{
+				{MachineIp: service_discovery.Configuration.MachineIp, Error: config.RetryErrorCircuitOpen},
+			},
+		}, JobCircuitOpen{function: req.ServiceName}
+	}
+
 	freeSlots := memdb.GetFreeRunningSlots()
 	if !config.GetQueueEnabled() && freeSlots <= 0 {
 		log.Log.Debugf("[R#%d,T%s] %s cannot be scheduled to be run locally: freeSlots=%d", req.Id, req.IdTracing, req.ServiceName, freeSlots)
@@ -76,12 +114,6 @@ func executeJobLocally(req *types.ServiceRequest, timingsStart *types.TimingsSta
 		}, JobCannotBeScheduled{}
 	}
 
-	// If we execute the job locally and request is external, payload is base64encoded and we decode it
-	if req.External {
-		decodedPayload, _ := base64.StdEncoding.DecodeString(string(req.Payload))
-		req.Payload = decodedPayload
-	}
-
 	job, err := queue.EnqueueJob(req)
 
 	/* This is blocking */
@@ -99,8 +131,19 @@ func executeJobLocally(req *types.ServiceRequest, timingsStart *types.TimingsSta
 
 	// Fill the execution time since it is derived from the internal execution
 	timings := types.Timings{ExecutionTime: &job.Timings.ExecutionTime}
+	if job.Response != nil {
+		timings.ColdStart = job.Timings.ColdStart
+	}
 
-	return prepareJobResultFromInternalExecution(job, req, timingsStart, &timings, scheduler), nil
+	result := prepareJobResultFromInternalExecution(job, req, timingsStart, &timings, scheduler)
+	if job.ErrorTimeout {
+		return result, JobExecutionTimeout{reason: fmt.Sprintf("%s exceeded its timeout", req.ServiceName)}
+	}
+	if job.ErrorCircuitOpen {
+		return result, JobCircuitOpen{function: req.ServiceName}
+	}
+
+	return result, nil
 }
 
 // prepareJobResultFromInternalExecution prepare the result when the job is executed internally
@@ -114,6 +157,7 @@ func prepareJobResultFromInternalExecution(job *queue.QueuedJob, req *types.Serv
 			Headers:    job.Response.Headers,
 			StatusCode: job.Response.StatusCode,
 			Body:       job.Response.Body,
+			BodyStream: job.Response.BodyStream,
 		}
 	}
 
@@ -126,10 +170,15 @@ func prepareJobResultFromInternalExecution(job *queue.QueuedJob, req *types.Serv
 		Scheduler:         scheduler,
 	}
 
+	for _, attempt := range job.Attempts {
+		attempt.MachineIp = service_discovery.Configuration.MachineIp
+		result.Attempts = append(result.Attempts, attempt)
+	}
+
 	return &result
 }
 
-func prepareJobResultFromExternalExecution(req *types.ServiceRequest, res *scheduler_service.APIResponse, reqErr error, timingsStart *types.TimingsStart, scheduler string, remoteNodeIP string) (*JobResult, error) {
+func prepareJobResultFromExternalExecution(req *types.ServiceRequest, res *scheduler_service.PeerResponse, reqErr error, timingsStart *types.TimingsStart, scheduler string, remoteNodeIP string) (*JobResult, error) {
 	var response types.APIResponse
 	var result JobResult
 
@@ -152,26 +201,25 @@ func prepareJobResultFromExternalExecution(req *types.ServiceRequest, res *sched
 	// Response should be never nil but we check here in case
 	if res != nil {
 		log.Log.Debugf("[R#%d,T%s] Response from external execution is %d", req.Id, req.IdTracing, res.StatusCode)
+		// the body is already the output of the function, decoded from the peer protocol
 		response = types.APIResponse{
 			Headers:    res.Headers,
 			StatusCode: res.StatusCode,
 			Body:       res.Body,
 		}
 
-		var peerJobResponse types.PeerJobResponse
-		err := json.Unmarshal(res.Body, &peerJobResponse)
-		if err != nil {
-			log.Log.Debugf("[R#%d,T%s] Cannot decode the job response", req.Id, req.IdTracing)
-		}
-
-		// Change the body of the response leaving only the output of the function, this because when executing functions
-		// between peer nodes we encapsulate the output body in a PeerJobResponse struct
-		response.Body = []byte(peerJobResponse.Body)
-
 		// Prepare the result
 		result.Response = &response
 		result.ExternalExecutionInfo = &ExternalExecutionInfo{
-			PeersList: peerJobResponse.PeersList,
+			PeersList: res.PeersList,
+		}
+
+		// The timeout is propagated as such, in this way every node in the chain replies with the timeout error
+		if isPeerResponseTimeout(res.StatusCode, res.Body) {
+			log.Log.Debugf("[R#%d,T%s] Job timed out at neighbor %s", req.Id, req.IdTracing, remoteNodeIP)
+
+			result.ErrorExecution = true
+			return &result, JobExecutionTimeout{reason: fmt.Sprintf("%s exceeded its timeout at neighbor %s", req.ServiceName, remoteNodeIP)}
 		}
 	} else {
 		log.Log.Errorf("[R#%d,T%s] Response from peer is nil", req.Id, req.IdTracing)
@@ -189,23 +237,59 @@ func prepareJobResultFromExternalExecution(req *types.ServiceRequest, res *sched
  * Utils
  */
 
+// peerExecutionAttempts returns the attempts of a job executed by a peer, when the peer does not send them a single
+// attempt is derived from its response
+func peerExecutionAttempts(remoteNodeIP string, res *scheduler_service.PeerResponse, elapsed float64) []types.ExecutionAttempt {
+	if res != nil && len(res.Attempts) > 0 {
+		return res.Attempts
+	}
+
+	attempt := types.ExecutionAttempt{MachineIp: remoteNodeIP, Time: elapsed}
+	if res == nil {
+		attempt.Error = config.RetryErrorConnection
+	} else if isPeerResponseTimeout(res.StatusCode, res.Body) {
+		attempt.Error = config.RetryErrorTimeout
+	} else {
+		attempt.StatusCode = res.StatusCode
+	}
+	return []types.ExecutionAttempt{attempt}
+}
+
+// isPeerResponseTimeout checks if the peer replied with the timeout error
+func isPeerResponseTimeout(statusCode int, body []byte) bool {
+	timeoutStatusCode, _, _ := errors.GetErrorJson(errors.JobExecutionTimeout)
+	if statusCode != timeoutStatusCode {
+		return false
+	}
+
+	var errorReply errors.ErrorReply
+	err := json.Unmarshal(body, &errorReply)
+	if err != nil {
+		return false
+	}
+
+	return errorReply.Code == errors.JobExecutionTimeout
+}
+
 // prepareForwardToPeerRequest prepare the request to execute the job to another peer
 func prepareForwardToPeerRequest(serviceRequest *types.ServiceRequest) (*types.PeerJobRequest, error) {
+	// the payload is sent whole to peers
+	err := serviceRequest.ReadPayload()
+	if err != nil {
+		return nil, err
+	}
+
+	// the payload is encoded by the peer protocol
 	peerRequest := types.PeerJobRequest{
 		ServiceIdRequest: serviceRequest.Id,
 		ServiceIdTracing: serviceRequest.IdTracing,
 		FunctionName:     serviceRequest.ServiceName,
 		ContentType:      serviceRequest.PayloadContentType,
+		Hops:             1,
 	}
 
-	// If request is external the payload is already in base64
-	if !serviceRequest.External {
-		// encode payload in base64
-		peerRequest.Payload = base64.StdEncoding.EncodeToString(serviceRequest.Payload)
-		peerRequest.Hops += 1
-	} else {
-		peerRequest.Payload = string(serviceRequest.Payload)
-		peerRequest.Hops = 1
+	if serviceRequest.External && serviceRequest.ExternalJobRequest != nil {
+		peerRequest.Hops = serviceRequest.ExternalJobRequest.Hops + 1
 	}
 	return &peerRequest, nil
 }
@@ -21,6 +21,7 @@ package scheduler_service
 import (
 	"fmt"
 	"scheduler/config"
+	"scheduler/mtls"
 )
 
 /*
@@ -28,13 +29,17 @@ import (
  */
 
 func GetApiUrl(host string) string {
-	return fmt.Sprintf("http://%s:%d", host, config.GetListeningPort())
+	return fmt.Sprintf("%s://%s:%d", mtls.HttpScheme(), host, config.GetListeningPort())
 }
 
 func GetMonitoringLoadUrl(host string) string {
 	return fmt.Sprintf("%s/monitoring/load", GetApiUrl(host))
 }
 
+func GetSystemFunctionsUrl(host string) string {
+	return fmt.Sprintf("%s/system/functions", GetApiUrl(host))
+}
+
 func GetPeerFunctionUrl(host string, functionName string) string {
 	return fmt.Sprintf("%s/peer/function/%s", GetApiUrl(host), functionName)
 }
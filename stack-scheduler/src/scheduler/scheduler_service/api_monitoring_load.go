@@ -19,10 +19,15 @@
 package scheduler_service
 
 import (
+	"io"
+	"io/ioutil"
 	"scheduler/log"
 	"scheduler/utils"
 )
 
+// loadMaxSize is the maximum size of the load document read from a peer
+const loadMaxSize = 1 << 20
+
 func monitoringLoadGetApiCall(host string) (*APIResponse, error) {
 	res, err := utils.HttpMachineGet(GetMonitoringLoadUrl(host))
 	if err != nil {
@@ -30,12 +35,18 @@ func monitoringLoadGetApiCall(host string) (*APIResponse, error) {
 		return nil, err
 	}
 
-	// body, _ := ioutil.ReadAll(res.Body)
+	// peers fall back to the load in the headers if the document cannot be read
+	body, readErr := ioutil.ReadAll(io.LimitReader(res.Body, loadMaxSize))
+	if readErr != nil {
+		log.Log.Debugf("Cannot read load from %s: %s", host, readErr)
+	}
+	// the rest of the body is drained for reusing the connection
+	_, _ = io.Copy(ioutil.Discard, res.Body)
 	_ = res.Body.Close()
 
 	response := APIResponse{
 		Headers:    res.Header,
-		Body:       []byte(""),
+		Body:       body,
 		StatusCode: res.StatusCode,
 	}
 
@@ -19,22 +19,99 @@
 package scheduler_service
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"io/ioutil"
+	"net/http"
 	"scheduler/log"
+	"scheduler/service_discovery"
 	"scheduler/types"
 	"scheduler/utils"
+	"strconv"
 )
 
-func peerFunctionApiCall(host string, peerRequest *types.PeerJobRequest) (*APIResponse, error) {
-	payload, err := json.Marshal(peerRequest)
+// peerFunctionApiCall sends the job to the peer with the grpc peer service if both use it, otherwise with the latest
+// http peer protocol supported by both
+func peerFunctionApiCall(host string, peerRequest *types.PeerJobRequest, payload []byte) (*PeerResponse, error) {
+	if client, ok := getGrpcClient(host); ok {
+		res, err := peerFunctionGrpcCall(client, peerRequest, payload)
+		if _, notReached := err.(ErrorGrpcNotReached); !notReached {
+			return res, err
+		}
+		fallbackFromGrpc(host, err)
+	}
+
+	log.Log.Debugf("Calling POST to %s", GetPeerFunctionUrl(host, peerRequest.FunctionName))
+
+	if service_discovery.GetMachinePeerProtocol(host) >= types.PeerProtocolVersionBinary {
+		return peerFunctionBinaryApiCall(host, peerRequest, payload)
+	}
+	return peerFunctionJsonApiCall(host, peerRequest, payload)
+}
+
+// peerFunctionBinaryApiCall sends the payload as raw bytes and the job request in a header
+func peerFunctionBinaryApiCall(host string, peerRequest *types.PeerJobRequest, payload []byte) (*PeerResponse, error) {
+	peerRequestJson, err := json.Marshal(peerRequest)
 	if err != nil {
-		log.Log.Errorf("Cannot encode to json payload")
+		log.Log.Errorf("Cannot encode to json peer request")
 		return nil, err
 	}
 
-	log.Log.Debugf("Calling POST to %s", GetPeerFunctionUrl(host, peerRequest.FunctionName))
-	// log.Log.Debugf("len(payload)=%d len(peers)=%d content_type=%s", len(payload), len(peerRequest.PeersList), peerRequest.PayloadContentType)
+	headers := []utils.HttpHeader{
+		{Key: utils.HttpHeaderP2PFaaSPeerProtocol, Value: strconv.Itoa(types.PeerProtocolVersionBinary)},
+		{Key: utils.HttpHeaderP2PFaaSPeerJob, Value: string(peerRequestJson)},
+	}
+	if peerRequest.ServiceIdTracing != "" {
+		headers = append(headers, utils.HttpHeader{Key: utils.HttpHeaderP2PFaaSSchedulerTracingId, Value: peerRequest.ServiceIdTracing})
+	}
+
+	res, err := utils.HttpMachinePostWithHeaders(GetPeerFunctionUrl(host, peerRequest.FunctionName), payload, peerRequest.ContentType, headers)
+	if err != nil {
+		log.Log.Errorf("Cannot create POST peerRequest to %s: %s", GetPeerFunctionUrl(host, peerRequest.FunctionName), err.Error())
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	body, _ := ioutil.ReadAll(res.Body)
+	response := PeerResponse{
+		APIResponse: APIResponse{
+			Headers:    res.Header,
+			Body:       body,
+			StatusCode: res.StatusCode,
+		},
+	}
+
+	// a peer which rejects the request as malformed without replying the protocol did not understand it, this happens
+	// only if it has been downgraded after advertising the binary protocol, so the job is sent again with the json
+	// protocol. Other errors, as a rejected signature or a too large payload, would be the same with the json protocol.
+	if res.Header.Get(utils.HttpHeaderP2PFaaSPeerProtocol) == "" &&
+		(res.StatusCode == http.StatusBadRequest || res.StatusCode == http.StatusUnsupportedMediaType) {
+		log.Log.Warningf("Peer %s does not support the binary peer protocol, falling back to json", host)
+		service_discovery.SetMachinePeerProtocol(host, types.PeerProtocolVersionJson)
+		return peerFunctionJsonApiCall(host, peerRequest, payload)
+	}
+
+	var peerResponse types.PeerJobResponse
+	err = json.Unmarshal([]byte(res.Header.Get(utils.HttpHeaderP2PFaaSPeerJob)), &peerResponse)
+	if err != nil {
+		log.Log.Debugf("Cannot decode the peer job response: %s", err)
+	}
+	response.PeersList = peerResponse.PeersList
+	response.Attempts = peerResponse.Attempts
+
+	return &response, nil
+}
+
+// peerFunctionJsonApiCall sends the job request as JSON with the payload base64 encoded
+func peerFunctionJsonApiCall(host string, peerRequest *types.PeerJobRequest, payload []byte) (*PeerResponse, error) {
+	jsonRequest := *peerRequest
+	jsonRequest.Payload = base64.StdEncoding.EncodeToString(payload)
+
+	peerRequestJson, err := json.Marshal(jsonRequest)
+	if err != nil {
+		log.Log.Errorf("Cannot encode to json payload")
+		return nil, err
+	}
 
 	// prepare headers
 	var headers *[]utils.HttpHeader = nil
@@ -44,7 +121,7 @@ func peerFunctionApiCall(host string, peerRequest *types.PeerJobRequest) (*APIRe
 		}
 	}
 
-	res, err := utils.HttpMachinePostJSONWithHeaders(GetPeerFunctionUrl(host, peerRequest.FunctionName), string(payload), headers)
+	res, err := utils.HttpMachinePostJSONWithHeaders(GetPeerFunctionUrl(host, peerRequest.FunctionName), string(peerRequestJson), headers)
 	if err != nil {
 		log.Log.Errorf("Cannot create POST peerRequest to %s: %s", GetPeerFunctionUrl(host, peerRequest.FunctionName), err.Error())
 		return nil, err
@@ -52,11 +129,41 @@ func peerFunctionApiCall(host string, peerRequest *types.PeerJobRequest) (*APIRe
 	defer res.Body.Close()
 
 	body, _ := ioutil.ReadAll(res.Body)
-	response := APIResponse{
-		Headers:    res.Header,
-		Body:       body,
-		StatusCode: res.StatusCode,
+	response := PeerResponse{
+		APIResponse: APIResponse{
+			Headers:    res.Header,
+			Body:       body,
+			StatusCode: res.StatusCode,
+		},
+	}
+	decodePeerJsonResponse(&response)
+
+	return &response, nil
+}
+
+// decodePeerJsonResponse replaces the body of the response with the decoded body of the job response. Bodies which are
+// not base64 encoded, as errors of older nodes, are kept as they are.
+func decodePeerJsonResponse(response *PeerResponse) {
+	// errors which are not job responses are kept as they are
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(response.Body, &fields); err != nil || fields["body"] == nil {
+		log.Log.Debugf("Peer response is not a job response")
+		return
 	}
 
-	return &response, err
+	var peerResponse types.PeerJobResponse
+	err := json.Unmarshal(response.Body, &peerResponse)
+	if err != nil {
+		log.Log.Debugf("Cannot decode the peer job response: %s", err)
+		return
+	}
+
+	response.PeersList = peerResponse.PeersList
+	response.Attempts = peerResponse.Attempts
+	response.Body = []byte(peerResponse.Body)
+
+	decodedBody, err := base64.StdEncoding.DecodeString(peerResponse.Body)
+	if err == nil {
+		response.Body = decodedBody
+	}
 }
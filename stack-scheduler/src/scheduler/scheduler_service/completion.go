@@ -0,0 +1,105 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package scheduler_service
+
+import (
+	"scheduler/log"
+	"scheduler/service_discovery"
+	"sync"
+)
+
+// EstimateCompletion returns the expected time in seconds for completing a job which finds the passed load in a machine
+// which executes slots jobs in parallel, each one lasting executionTime
+func EstimateCompletion(load uint, slots uint, executionTime float64) float64 {
+	if slots == 0 {
+		slots = 1
+	}
+	return float64(load/slots+1) * executionTime
+}
+
+// GetFastestMachineOfNRandom probes N random machines which host the function and returns the one with the earliest
+// expected completion of the job, comprising the transfer of the payload. If no machine completes the job before
+// localCompletion an error is returned. The execution time is the one advertised by every machine for the function, or
+// the passed one if the machine does not advertise it. This function returns (ip, expected_completion, errors)
+func GetFastestMachineOfNRandom(n uint, localCompletion float64, executionTime float64, payloadBytes int, cached bool, functionName string) (string, float64, error) {
+	machines, err := service_discovery.GetNRandomMachinesHostingFunction(n, cached, functionName)
+	if err != nil {
+		log.Log.Errorf("Cannot get random machines from service_discovery service: %s", err)
+		return "", 0.0, err
+	}
+
+	completions := make([]float64, len(machines))
+	probeErr := make([]bool, len(machines))
+	excluded := make([]bool, len(machines))
+
+	wg := sync.WaitGroup{}
+	for i, ip := range machines {
+		wg.Add(1)
+
+		go func(i int, ip string) {
+			defer wg.Done()
+
+			load, _, err := GetLoadDocument(ip)
+			if err != nil {
+				log.Log.Errorf("Cannot get load from machine %s", ip)
+				probeErr[i] = true
+				return
+			}
+			// the probe refreshed the functions of the machine, which can have opened the breaker of the function
+			if !service_discovery.MachineHostsFunction(ip, functionName) {
+				log.Log.Debugf("Machine %s cannot execute %s now", ip, functionName)
+				excluded[i] = true
+				return
+			}
+
+			remoteExecutionTime := executionTime
+			if functionLoad, exists := load.Functions[functionName]; exists && functionLoad.ExecutionTimeEwma > 0 {
+				remoteExecutionTime = functionLoad.ExecutionTimeEwma
+			}
+			completions[i] = EstimateTransferTime(ip, payloadBytes) +
+				EstimateCompletion(load.FunctionsRunning+load.QueueLength, load.FunctionsRunningMax, remoteExecutionTime)
+		}(i, ip)
+	}
+	wg.Wait()
+
+	fastest := -1
+	candidates := 0
+	for i := range machines {
+		if excluded[i] {
+			continue
+		}
+		candidates += 1
+		if !probeErr[i] && (fastest < 0 || completions[i] < completions[fastest]) {
+			fastest = i
+		}
+	}
+	if candidates == 0 {
+		return "", 0.0, NoLessLoadedMachine{"no machine can execute the function"}
+	}
+	if fastest < 0 {
+		return "", 0.0, NoLessLoadedMachine{"all probe errors"}
+	}
+
+	log.Log.Debugf("Fastest machine %s completes in %.3fs, locally in %.3fs", machines[fastest], completions[fastest], localCompletion)
+	if completions[fastest] >= localCompletion {
+		return "", completions[fastest], NoLessLoadedMachine{"remoteCompletion >= localCompletion"}
+	}
+
+	return machines[fastest], completions[fastest], nil
+}
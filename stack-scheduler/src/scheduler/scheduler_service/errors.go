@@ -18,6 +18,8 @@
 
 package scheduler_service
 
+import "fmt"
+
 type NoLessLoadedMachine struct {
 	Reason string
 }
@@ -25,3 +27,21 @@ type NoLessLoadedMachine struct {
 func (n NoLessLoadedMachine) Error() string {
 	return n.Reason
 }
+
+type ErrorUnexpectedStatusCode struct {
+	StatusCode int
+	Body       string
+}
+
+func (e ErrorUnexpectedStatusCode) Error() string {
+	return fmt.Sprintf("Unexpected status code %d: %s", e.StatusCode, e.Body)
+}
+
+// ErrorMachineDraining is returned when the machine advertises that it is draining, so no job must be sent to it
+type ErrorMachineDraining struct {
+	Host string
+}
+
+func (e ErrorMachineDraining) Error() string {
+	return fmt.Sprintf("Machine %s is draining", e.Host)
+}
@@ -0,0 +1,114 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package scheduler_service
+
+import (
+	"scheduler/config"
+	"scheduler/log"
+	"scheduler/peer_proto"
+	"scheduler/service_discovery"
+	"scheduler/types"
+	"scheduler/utils"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// StartFunctionsAdvertisementRefresher periodically retrieves the functions and the peer protocol advertised by all the
+// machines, so that jobs are forwarded only to machines which host the function
+func StartFunctionsAdvertisementRefresher() {
+	go func() {
+		for {
+			refreshMachinesFunctions()
+			time.Sleep(time.Duration(config.GetFunctionsAdvertisementInterval()) * time.Millisecond)
+		}
+	}()
+}
+
+func refreshMachinesFunctions() {
+	machines, err := service_discovery.GetMachinesIpsList()
+	if err != nil {
+		log.Log.Debugf("Cannot get machines from service_discovery service: %s", err)
+		return
+	}
+
+	wg := sync.WaitGroup{}
+	for _, ip := range machines {
+		wg.Add(1)
+		go func(ip string) {
+			defer wg.Done()
+			// the machines reached with the grpc peer service push their load, so they are not polled
+			if client, ok := getGrpcClient(ip); ok {
+				subscribeMachineLoad(ip, client)
+				return
+			}
+
+			res, err := monitoringLoadGetApiCall(ip)
+			if err != nil {
+				log.Log.Debugf("Cannot get advertised functions from machine %s: %s", ip, err)
+				return
+			}
+			updateMachineFunctions(ip, res)
+		}(ip)
+	}
+	wg.Wait()
+
+	service_discovery.RetainMachines(machines)
+	retainLoadSubscriptions(machines)
+	retainLinks(machines)
+}
+
+// updateMachineFromLoad records the functions and the peer protocol advertised in the load sent by the grpc peer service
+func updateMachineFromLoad(ip string, load *peer_proto.Load) {
+	service_discovery.SetMachinePeerProtocol(ip, int(load.GetPeerProtocol()))
+	if load.GetFunctionsKnown() {
+		service_discovery.SetMachineFunctions(ip, load.GetFunctions())
+	}
+	service_discovery.SetMachineUnavailableFunctions(ip, load.GetUnavailableFunctions())
+	service_discovery.SetMachineWarmFunctions(ip, load.GetWarmFunctions())
+}
+
+// updateMachineFunctions records the functions and the peer protocol advertised in the response of the load api, if any
+func updateMachineFunctions(ip string, res *APIResponse) {
+	if res == nil {
+		return
+	}
+
+	if version, err := strconv.Atoi(res.Headers.Get(utils.HttpHeaderP2PFaaSPeerProtocol)); err == nil {
+		service_discovery.SetMachinePeerProtocol(ip, version)
+	}
+	utils.SetMachineHttp2(ip, res.Headers.Get(utils.HttpHeaderP2PFaaSPeerHttp2) == "true" &&
+		service_discovery.MachineSupports(ip, types.CapabilityHttp2))
+	grpcPort, _ := strconv.Atoi(res.Headers.Get(utils.HttpHeaderP2PFaaSPeerGrpcPort))
+	service_discovery.SetMachineGrpcPort(ip, grpcPort)
+
+	// the functions are not advertised by the machines which cannot list them, but their unavailable ones still are
+	if len(res.Headers.Values(utils.HttpHeaderP2PFaaSFunctions)) > 0 {
+		functions := service_discovery.DecodeMachineFunctions(res.Headers.Get(utils.HttpHeaderP2PFaaSFunctions))
+		service_discovery.SetMachineFunctions(ip, functions)
+	}
+	if len(res.Headers.Values(utils.HttpHeaderP2PFaaSFunctionsUnavailable)) > 0 {
+		unavailable := service_discovery.DecodeFunctionsList(res.Headers.Get(utils.HttpHeaderP2PFaaSFunctionsUnavailable))
+		service_discovery.SetMachineUnavailableFunctions(ip, unavailable)
+	}
+	if len(res.Headers.Values(utils.HttpHeaderP2PFaaSFunctionsWarm)) > 0 {
+		warm := service_discovery.DecodeFunctionsList(res.Headers.Get(utils.HttpHeaderP2PFaaSFunctionsWarm))
+		service_discovery.SetMachineWarmFunctions(ip, warm)
+	}
+}
@@ -0,0 +1,220 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package scheduler_service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"io"
+	"net/http"
+	"scheduler/config"
+	"scheduler/log"
+	"scheduler/mtls"
+	"scheduler/peer_proto"
+	"scheduler/service_discovery"
+	"scheduler/signature"
+	"scheduler/types"
+	"scheduler/utils"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// GrpcCallTimeout is the timeout of the calls to the grpc peer service, as the one of the http peer api
+const GrpcCallTimeout = 30 * time.Second
+
+var grpcConnections = make(map[string]*grpc.ClientConn)
+var grpcConnectionsMutex sync.Mutex
+
+// ErrorGrpcNotReached is returned when a job could not be delivered to the grpc peer service, so it can be sent again
+// with the http peer api
+type ErrorGrpcNotReached struct {
+	err error
+}
+
+func (e ErrorGrpcNotReached) Error() string {
+	return fmt.Sprintf("grpc peer service not reached: %s", e.err)
+}
+
+// getGrpcClient returns the client of the grpc peer service of the machine, false if the cluster does not use the
+// grpc peer transport or the machine did not advertise it
+func getGrpcClient(host string) (peer_proto.PeerClient, bool) {
+	if config.GetPeerTransport() != config.PeerTransportGrpc {
+		return nil, false
+	}
+	port, exists := service_discovery.GetMachineGrpcPort(host)
+	if !exists {
+		return nil, false
+	}
+	target := fmt.Sprintf("%s:%d", host, port)
+
+	grpcConnectionsMutex.Lock()
+	defer grpcConnectionsMutex.Unlock()
+
+	if conn, exists := grpcConnections[host]; exists {
+		if conn.Target() == target {
+			return peer_proto.NewPeerClient(conn), true
+		}
+		_ = conn.Close()
+		delete(grpcConnections, host)
+	}
+
+	// the connection is established in background and shared by all the calls to the machine
+	transportCredentials := insecure.NewCredentials()
+	if mtls.Enabled() {
+		transportCredentials = credentials.NewTLS(mtls.ClientConfig())
+	}
+	conn, err := grpc.Dial(target,
+		grpc.WithTransportCredentials(transportCredentials),
+		grpc.WithUnaryInterceptor(signUnaryCall),
+		grpc.WithStreamInterceptor(signStreamCall),
+		grpc.WithUserAgent(config.UserAgentMachine),
+		grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                15 * time.Second,
+			Timeout:             5 * time.Second,
+			PermitWithoutStream: true,
+		}),
+	)
+	if err != nil {
+		log.Log.Errorf("Cannot create grpc connection to %s: %s", target, err)
+		return nil, false
+	}
+	grpcConnections[host] = conn
+
+	return peer_proto.NewPeerClient(conn), true
+}
+
+// signUnaryCall sends the protocol version of this node and signs the call when signatures are enabled
+func signUnaryCall(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+	invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	ctx = metadata.AppendToOutgoingContext(ctx, utils.GrpcMetadataP2PFaaSProtocolVersion, strconv.Itoa(types.ProtocolVersion))
+	if signature.Enabled() {
+		ctx = signature.AppendToOutgoingContext(ctx, method)
+	}
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+// signStreamCall sends the protocol version of this node and signs the stream when signatures are enabled
+func signStreamCall(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string,
+	streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	ctx = metadata.AppendToOutgoingContext(ctx, utils.GrpcMetadataP2PFaaSProtocolVersion, strconv.Itoa(types.ProtocolVersion))
+	if signature.Enabled() {
+		ctx = signature.AppendToOutgoingContext(ctx, method)
+	}
+	return streamer(ctx, desc, cc, method, opts...)
+}
+
+// fallbackFromGrpc makes the machine reached with the http peer api until it advertises the grpc peer service again
+func fallbackFromGrpc(host string, err error) {
+	log.Log.Warningf("Cannot reach grpc peer service of %s, falling back to http: %s", host, err)
+	service_discovery.SetMachineGrpcPort(host, 0)
+
+	grpcConnectionsMutex.Lock()
+	defer grpcConnectionsMutex.Unlock()
+
+	if conn, exists := grpcConnections[host]; exists {
+		_ = conn.Close()
+		delete(grpcConnections, host)
+	}
+}
+
+func grpcGetLoad(client peer_proto.PeerClient) (*peer_proto.Load, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), GrpcCallTimeout)
+	defer cancel()
+
+	return client.GetLoad(ctx, &peer_proto.LoadRequest{})
+}
+
+// peerFunctionGrpcCall sends the job and the payload in chunks, and receives the response and the body in chunks
+func peerFunctionGrpcCall(client peer_proto.PeerClient, peerRequest *types.PeerJobRequest, payload []byte) (*PeerResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), GrpcCallTimeout)
+	defer cancel()
+
+	stream, err := client.ExecuteFunction(ctx)
+	if err != nil {
+		return nil, ErrorGrpcNotReached{err}
+	}
+
+	err = stream.Send(&peer_proto.ExecuteFunctionRequest{
+		Message: &peer_proto.ExecuteFunctionRequest_Job{Job: peer_proto.NewJobRequest(peerRequest)},
+	})
+	if err != nil {
+		return nil, ErrorGrpcNotReached{err}
+	}
+
+	for len(payload) > 0 {
+		chunkSize := len(payload)
+		if chunkSize > peer_proto.ChunkSize {
+			chunkSize = peer_proto.ChunkSize
+		}
+
+		err = stream.Send(&peer_proto.ExecuteFunctionRequest{
+			Message: &peer_proto.ExecuteFunctionRequest_PayloadChunk{PayloadChunk: payload[:chunkSize]},
+		})
+		if err != nil {
+			return nil, err
+		}
+		payload = payload[chunkSize:]
+	}
+	if err = stream.CloseSend(); err != nil {
+		return nil, err
+	}
+
+	first, err := stream.Recv()
+	if err != nil {
+		if status.Code(err) == codes.Unimplemented {
+			return nil, ErrorGrpcNotReached{err}
+		}
+		return nil, err
+	}
+	if first.GetJob() == nil {
+		return nil, fmt.Errorf("the first message of the response is not the job response")
+	}
+	jobResponse := first.GetJob().ToPeerJobResponse()
+
+	var body bytes.Buffer
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		body.Write(res.GetBodyChunk())
+	}
+
+	return &PeerResponse{
+		APIResponse: APIResponse{
+			Headers:    http.Header{},
+			Body:       body.Bytes(),
+			StatusCode: jobResponse.StatusCode,
+		},
+		PeersList: jobResponse.PeersList,
+		Attempts:  jobResponse.Attempts,
+	}, nil
+}
@@ -0,0 +1,104 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package scheduler_service
+
+import (
+	"context"
+	"scheduler/config"
+	"scheduler/log"
+	"scheduler/peer_proto"
+	"sync"
+)
+
+// loadSubscriptions are the subscriptions to the load of the machines reached with the grpc peer service
+var loadSubscriptions = make(map[string]*loadSubscription)
+var loadSubscriptionsMutex sync.Mutex
+
+type loadSubscription struct {
+	cancel context.CancelFunc
+}
+
+// subscribeMachineLoad subscribes to the load of the machine, if not already subscribed. The load is received at the
+// functions advertisement interval and it updates the functions of the machine.
+func subscribeMachineLoad(ip string, client peer_proto.PeerClient) {
+	loadSubscriptionsMutex.Lock()
+	defer loadSubscriptionsMutex.Unlock()
+
+	if _, exists := loadSubscriptions[ip]; exists {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := client.SubscribeLoad(ctx)
+	if err == nil {
+		err = stream.Send(&peer_proto.LoadSubscription{IntervalMs: uint32(config.GetFunctionsAdvertisementInterval())})
+	}
+	if err != nil {
+		cancel()
+		fallbackFromGrpc(ip, err)
+		return
+	}
+	subscription := &loadSubscription{cancel: cancel}
+	loadSubscriptions[ip] = subscription
+
+	go func() {
+		defer removeLoadSubscription(ip, subscription)
+
+		for {
+			load, err := stream.Recv()
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Log.Debugf("Load subscription to %s ended: %s", ip, err)
+				}
+				return
+			}
+			updateMachineFromLoad(ip, load)
+		}
+	}()
+}
+
+// removeLoadSubscription removes the ended subscription, unless it has been already replaced by a new one
+func removeLoadSubscription(ip string, subscription *loadSubscription) {
+	subscription.cancel()
+
+	loadSubscriptionsMutex.Lock()
+	defer loadSubscriptionsMutex.Unlock()
+
+	if loadSubscriptions[ip] == subscription {
+		delete(loadSubscriptions, ip)
+	}
+}
+
+// retainLoadSubscriptions ends the subscriptions to the machines not in the list
+func retainLoadSubscriptions(ips []string) {
+	alive := make(map[string]bool, len(ips))
+	for _, ip := range ips {
+		alive[ip] = true
+	}
+
+	loadSubscriptionsMutex.Lock()
+	defer loadSubscriptionsMutex.Unlock()
+
+	for ip, subscription := range loadSubscriptions {
+		if !alive[ip] {
+			subscription.cancel()
+			delete(loadSubscriptions, ip)
+		}
+	}
+}
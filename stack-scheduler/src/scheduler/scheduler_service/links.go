@@ -0,0 +1,131 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package scheduler_service
+
+import (
+	"scheduler/config"
+	"scheduler/service_discovery"
+	"sort"
+	"sync"
+	"time"
+)
+
+// linkThroughputMinBytes is the minimum size of a forward for measuring the throughput, the smaller ones last mostly
+// the round trip time
+const linkThroughputMinBytes = 64 * 1024
+
+// linkThroughputWeight is the weight of the last forward in the moving average of the throughput
+const linkThroughputWeight = 0.3
+
+// Link is the state of the link to a peer
+type Link struct {
+	Ip string `json:"ip"`
+	// Rtt is the ping in seconds measured by the service_discovery service, 0 if it is not known
+	Rtt float64 `json:"rtt"`
+	// Throughput is the moving average in bytes/s observed in the forwards, 0 if it is not measured yet
+	Throughput float64 `json:"throughput"`
+	// Samples is the number of forwards in which the throughput has been measured
+	Samples uint `json:"samples"`
+}
+
+var linksThroughput = make(map[string]*Link)
+var linksThroughputMutex sync.RWMutex
+
+// EstimateTransferTime returns the expected time in seconds for sending the passed bytes to the machine, that is the
+// round trip time and the transmission at the throughput observed in the previous forwards
+func EstimateTransferTime(host string, bytes int) float64 {
+	rtt, _ := service_discovery.GetMachinePing(host)
+
+	throughput := float64(config.GetLinkThroughputDefault())
+	linksThroughputMutex.RLock()
+	if link, exists := linksThroughput[host]; exists && link.Throughput > 0 {
+		throughput = link.Throughput
+	}
+	linksThroughputMutex.RUnlock()
+
+	return rtt + float64(bytes)/throughput
+}
+
+// GetLinks returns the state of the links to the peers to which jobs have been forwarded
+func GetLinks() []Link {
+	linksThroughputMutex.RLock()
+	defer linksThroughputMutex.RUnlock()
+
+	out := []Link{}
+	for ip, link := range linksThroughput {
+		l := *link
+		l.Rtt, _ = service_discovery.GetMachinePing(ip)
+		out = append(out, l)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Ip < out[j].Ip })
+	return out
+}
+
+// recordForwardTransfer measures the throughput of the link from a forward, the time spent by the job in the machine
+// is not part of the transfer
+func recordForwardTransfer(host string, res *PeerResponse, payloadBytes int, elapsed time.Duration) {
+	bytes := payloadBytes + len(res.Body)
+	if bytes < linkThroughputMinBytes || len(res.PeersList) == 0 {
+		return
+	}
+
+	// the machine adds itself as last in the list of the peers which handled the job
+	remoteTime := res.PeersList[len(res.PeersList)-1].Timings.TotalTime
+	if remoteTime == nil {
+		return
+	}
+	rtt, _ := service_discovery.GetMachinePing(host)
+	transferTime := elapsed.Seconds() - *remoteTime - rtt
+	if transferTime <= 0 {
+		return
+	}
+	throughput := float64(bytes) / transferTime
+
+	linksThroughputMutex.Lock()
+	defer linksThroughputMutex.Unlock()
+
+	link, exists := linksThroughput[host]
+	if !exists {
+		link = &Link{Ip: host}
+		linksThroughput[host] = link
+	}
+	if link.Throughput == 0 {
+		link.Throughput = throughput
+	} else {
+		link.Throughput = linkThroughputWeight*throughput + (1-linkThroughputWeight)*link.Throughput
+	}
+	link.Samples++
+}
+
+// retainLinks forgets the links to the machines not in the list
+func retainLinks(ips []string) {
+	alive := make(map[string]bool, len(ips))
+	for _, ip := range ips {
+		alive[ip] = true
+	}
+
+	linksThroughputMutex.Lock()
+	defer linksThroughputMutex.Unlock()
+
+	for ip := range linksThroughput {
+		if !alive[ip] {
+			delete(linksThroughput, ip)
+		}
+	}
+}
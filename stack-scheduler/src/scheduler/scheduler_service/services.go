@@ -19,38 +19,127 @@
 package scheduler_service
 
 import (
-	"scheduler/api/api_monitoring"
+	"encoding/json"
+	"scheduler/errors"
 	"scheduler/log"
+	"scheduler/peer_health"
 	"scheduler/types"
+	"scheduler/utils"
 	"strconv"
+	"strings"
+	"time"
 )
 
 // GetLoad allows to get the load of another machine, from a machine
 func GetLoad(host string) (int, *APIResponse, error) {
+	load, res, err := GetLoadDocument(host)
+	if err != nil {
+		return -1, res, err
+	}
+	return int(load.FunctionsRunning + load.QueueLength), nil, nil
+}
+
+// GetLoadDocument returns the load of another machine, the fields which the machine does not advertise are empty. The
+// outcome of the probe is recorded in the health of the machine.
+func GetLoadDocument(host string) (*types.Load, *APIResponse, error) {
+	startTime := time.Now()
+	load, res, err := getLoad(host)
+
+	switch err.(type) {
+	case nil:
+		peer_health.RecordSuccess(host, time.Since(startTime))
+	case ErrorMachineDraining:
+		// the machine replied, it only refuses new jobs
+	default:
+		peer_health.RecordFailure(host)
+	}
+
+	return load, res, err
+}
+
+func getLoad(host string) (*types.Load, *APIResponse, error) {
+	if client, ok := getGrpcClient(host); ok {
+		load, err := grpcGetLoad(client)
+		if err == nil {
+			updateMachineFromLoad(host, load)
+			if load.GetDraining() {
+				return nil, nil, ErrorMachineDraining{host}
+			}
+			return &types.Load{
+				FunctionsRunning:    uint(load.GetRunningFunctions()),
+				FunctionsRunningMax: uint(load.GetRunningFunctionsMax()),
+				QueueLength:         uint(load.GetQueueLength()),
+			}, nil, nil
+		}
+		fallbackFromGrpc(host, err)
+	}
+
 	res, err := monitoringLoadGetApiCall(host)
 	if err != nil {
 		log.Log.Debugf("Cannot get load from scheduler service: %s", err.Error())
-		return -1, res, err
+		return nil, res, err
 	}
 
-	currentRunningFunctions, err := strconv.Atoi(res.Headers.Get(api_monitoring.ApiMonitoringLoadHeaderKey))
+	updateMachineFunctions(host, res)
+
+	// peers which serve the load document are read from it, the older ones only from the headers
+	if load, ok := decodeLoad(res); ok {
+		if load.Draining {
+			return nil, res, ErrorMachineDraining{host}
+		}
+		return load, res, nil
+	}
+
+	currentRunningFunctions, err := strconv.Atoi(res.Headers.Get(utils.HttpHeaderP2PFaaSLoad))
 	if err != nil {
 		log.Log.Debugf("Cannot get load from scheduler service: %s", err.Error())
-		return -1, res, err
+		return nil, res, err
 	}
 
-	queueLen, err := strconv.Atoi(res.Headers.Get(api_monitoring.ApiMonitoringQueueLengthHeaderKey))
+	queueLen, err := strconv.Atoi(res.Headers.Get(utils.HttpHeaderP2PFaaSQueueLength))
 	if err != nil {
 		log.Log.Debugf("Cannot get load from scheduler service: %s", err.Error())
-		return -1, res, err
+		return nil, res, err
+	}
+
+	maxLoad, _ := strconv.Atoi(res.Headers.Get(utils.HttpHeaderP2PFaaSMaxLoad))
+
+	return &types.Load{
+		FunctionsRunning:    uint(currentRunningFunctions),
+		FunctionsRunningMax: uint(maxLoad),
+		QueueLength:         uint(queueLen),
+	}, res, nil
+}
+
+// decodeLoad returns the load document in the response of the load api, false if the peer did not serve it
+func decodeLoad(res *APIResponse) (*types.Load, bool) {
+	if !strings.HasPrefix(res.Headers.Get("Content-Type"), "application/json") {
+		return nil, false
 	}
 
-	return currentRunningFunctions + queueLen, nil, nil
+	var load types.Load
+	if err := json.Unmarshal(res.Body, &load); err != nil || load.Version < 1 {
+		return nil, false
+	}
+	return &load, true
 }
 
 // ExecuteFunction allows to request another machine to execute a function
-func ExecuteFunction(host string, peerRequest *types.PeerJobRequest) (*APIResponse, error) {
-	res, err := peerFunctionApiCall(host, peerRequest)
+func ExecuteFunction(host string, peerRequest *types.PeerJobRequest, payload []byte) (*PeerResponse, error) {
+	startTime := time.Now()
+	res, err := peerFunctionApiCall(host, peerRequest, payload)
+	if err == nil && res != nil && isPeerResponseDraining(res) {
+		// the machine replied, it only refuses new jobs, so the job must be sent to another machine
+		log.Log.Debugf("[R#%d,T%s] Peer %s is draining", peerRequest.ServiceIdRequest, peerRequest.ServiceIdTracing, host)
+		return res, ErrorMachineDraining{host}
+	}
+	if err != nil || res == nil {
+		peer_health.RecordFailure(host)
+	} else {
+		// the duration of the forward depends on the function, so it is not a latency of the machine
+		peer_health.RecordSuccess(host, 0)
+		recordForwardTransfer(host, res, len(payload), time.Since(startTime))
+	}
 	if err != nil {
 		log.Log.Errorf("[R#%d,T%s] Cannot execute function on peer: %s", peerRequest.ServiceIdRequest, peerRequest.ServiceIdTracing, err.Error())
 		return res, err
@@ -58,3 +147,54 @@ func ExecuteFunction(host string, peerRequest *types.PeerJobRequest) (*APIRespon
 
 	return res, nil
 }
+
+// isPeerResponseDraining checks if the peer rejected the job since it is draining
+func isPeerResponseDraining(res *PeerResponse) bool {
+	drainingStatusCode, _, _ := errors.GetErrorJson(errors.MachineDraining)
+	if res.StatusCode != drainingStatusCode {
+		return false
+	}
+
+	var errorReply errors.ErrorReply
+	err := json.Unmarshal(res.Body, &errorReply)
+	if err != nil {
+		return false
+	}
+
+	return errorReply.Code == errors.MachineDraining
+}
+
+// GetFunctions returns the functions deployed in another machine
+func GetFunctions(host string) ([]types.FaasFunction, error) {
+	res, err := systemFunctionsGetApiCall(host)
+	if err != nil {
+		log.Log.Debugf("Cannot get functions from scheduler service: %s", err.Error())
+		return nil, err
+	}
+	if res.StatusCode != 200 {
+		return nil, ErrorUnexpectedStatusCode{res.StatusCode, string(res.Body)}
+	}
+
+	var functions []types.FaasFunction
+	err = json.Unmarshal(res.Body, &functions)
+	if err != nil {
+		log.Log.Debugf("Cannot decode functions from scheduler service: %s", err.Error())
+		return nil, err
+	}
+
+	return functions, nil
+}
+
+// DeployFunction deploys the function only in another machine
+func DeployFunction(host string, function types.FaasFunction) (*APIResponse, error) {
+	res, err := systemFunctionsPostApiCall(host, function)
+	if err != nil {
+		log.Log.Debugf("Cannot deploy function on scheduler service: %s", err.Error())
+		return res, err
+	}
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return res, ErrorUnexpectedStatusCode{res.StatusCode, string(res.Body)}
+	}
+
+	return res, nil
+}
@@ -18,10 +18,21 @@
 
 package scheduler_service
 
-import "net/http"
+import (
+	"net/http"
+	"scheduler/types"
+)
 
 type APIResponse struct {
 	Headers    http.Header
 	Body       []byte
 	StatusCode int
 }
+
+// PeerResponse is the response of a peer to a job, independently of the peer protocol. Body is the output of the job,
+// not encoded.
+type PeerResponse struct {
+	APIResponse
+	PeersList []types.PeersListMember
+	Attempts  []types.ExecutionAttempt
+}
@@ -19,6 +19,7 @@
 package scheduler_service
 
 import (
+	"scheduler/gossip"
 	"scheduler/log"
 	"scheduler/service_discovery"
 	"scheduler/utils"
@@ -27,22 +28,24 @@ import (
 )
 
 // GetLeastLoadedMachineOfNRandom retrieves the least loaded machine from an array of ips, if all machines are full loaded,
-// the least queue is returned, and if there is no less loaded queue than us, an error is returned. This function returns
-// (ip, mean_probing_time, errors)
-func GetLeastLoadedMachineOfNRandom(n uint, currentLoad uint, checkQueues bool, cached bool) (string, float64, error) {
+// the least queue is returned, and if there is no less loaded queue than us, an error is returned. Only the machines
+// which host the function are considered, and the ones on which the function is warm are preferred. When the probe of a
+// machine fails, its last gossiped load is used if it is fresh. This function returns (ip, mean_probing_time, errors)
+func GetLeastLoadedMachineOfNRandom(n uint, currentLoad uint, checkQueues bool, cached bool, functionName string) (string, float64, error) {
 	startProbingTime := time.Now()
 
 	// get n random machines from service_discovery
-	machines, err := service_discovery.GetNRandomMachines(n, cached)
+	machines, err := service_discovery.GetNRandomMachinesHostingFunction(n, cached, functionName)
 	if err != nil {
 		log.Log.Errorf("Cannot get random machines from service_discovery service: %s", err)
 		return "", 0.0, err
 	}
 
 	log.Log.Debugf("len(machines)=%d", len(machines))
-	loads := make([]uint, n) // list of loads
+	loads := make([]uint, len(machines)) // list of loads
 	// queues := make([]float64, n) // percentage of queue fill
-	probeErr := make([]bool, n) // list of probe errors
+	probeErr := make([]bool, len(machines)) // list of probe errors
+	excluded := make([]bool, len(machines)) // list of machines which cannot execute the function now
 
 	wg := sync.WaitGroup{}
 	// get and compute the load of all the available machines in parallel
@@ -53,6 +56,12 @@ func GetLeastLoadedMachineOfNRandom(n uint, currentLoad uint, checkQueues bool,
 		i := i
 		go func() {
 			machineLoad, _, err := GetLoad(ip)
+			if _, draining := err.(ErrorMachineDraining); err != nil && !draining {
+				if digest, fresh := gossip.GetMachineLoad(ip); fresh {
+					log.Log.Debugf("Cannot probe machine %s, using its gossiped load", ip)
+					machineLoad, err = int(digest.Running+digest.Queue), nil
+				}
+			}
 			if err != nil {
 				log.Log.Errorf("Cannot get load from machine %s", ip)
 				probeErr[i] = true
@@ -71,6 +80,14 @@ func GetLeastLoadedMachineOfNRandom(n uint, currentLoad uint, checkQueues bool,
 				}
 			*/
 
+			// the probe refreshed the functions of the machine, which can have opened the breaker of the function
+			if !service_discovery.MachineHostsFunction(ip, functionName) {
+				log.Log.Debugf("Machine %s cannot execute %s now", ip, functionName)
+				excluded[i] = true
+				wg.Done()
+				return
+			}
+
 			loads[i] = uint(machineLoad)
 			// queues[i] = 0
 			probeErr[i] = false
@@ -85,14 +102,27 @@ func GetLeastLoadedMachineOfNRandom(n uint, currentLoad uint, checkQueues bool,
 
 	probingTime := time.Since(startProbingTime).Seconds()
 
+	// the excluded machines replied, so they are not probe errors but they are not candidates
+	candidates := 0
+	for i := range machines {
+		if !excluded[i] {
+			machines[candidates], loads[candidates], probeErr[candidates] = machines[i], loads[i], probeErr[i]
+			candidates += 1
+		}
+	}
+	machines, loads, probeErr = machines[:candidates], loads[:candidates], probeErr[:candidates]
+	if len(machines) == 0 {
+		return "", probingTime, NoLessLoadedMachine{"no machine can execute the function"}
+	}
+
 	// Check if we have enough correct loads
 	probeErrors := 0
-	for i := 0; i < int(n); i++ {
+	for i := 0; i < len(machines); i++ {
 		if probeErr[i] {
 			probeErrors += 1
 		}
 	}
-	if probeErrors == int(n) {
+	if probeErrors == len(machines) {
 		return "", probingTime, NoLessLoadedMachine{"all probe errors"}
 	}
 
@@ -118,8 +148,17 @@ func GetLeastLoadedMachineOfNRandom(n uint, currentLoad uint, checkQueues bool,
 			}
 		*/
 	} else {
-		// pick one random machine among the less loaded than us
+		// pick one random machine among the less loaded than us, preferring the ones on which the function is warm
 		valuableMachinesIds := utils.LoadsBelowSpecificLoad(loads, currentLoad)
+		var warmMachinesIds []uint
+		for _, id := range valuableMachinesIds {
+			if service_discovery.IsFunctionWarmOnMachine(machines[id], functionName) {
+				warmMachinesIds = append(warmMachinesIds, id)
+			}
+		}
+		if len(warmMachinesIds) > 0 {
+			valuableMachinesIds = warmMachinesIds
+		}
 		return machines[valuableMachinesIds[utils.GetRandomInteger(len(valuableMachinesIds))]], probingTime, nil
 	}
 }
@@ -21,10 +21,11 @@ package service_discovery
 import (
 	"fmt"
 	"scheduler/config"
+	"scheduler/mtls"
 )
 
 func getApiUrl() string {
-	return fmt.Sprintf("http://%s:%d", config.GetServiceDiscoveryListeningHost(), config.GetServiceDiscoveryListeningPort())
+	return fmt.Sprintf("%s://%s:%d", mtls.HttpScheme(), config.GetServiceDiscoveryListeningHost(), config.GetServiceDiscoveryListeningPort())
 }
 
 // getListApiUrl returns the api url of the list function for the service_discovery service
@@ -0,0 +1,136 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package service_discovery
+
+import (
+	"fmt"
+	"scheduler/config"
+	"scheduler/log"
+	"scheduler/types"
+	"scheduler/utils"
+	"sync"
+)
+
+// machineProtocol is the protocol negotiated with a machine which advertised it through the service_discovery service
+type machineProtocol struct {
+	// Capabilities are the ones advertised by both this node and the machine
+	Capabilities map[string]bool
+}
+
+// machinesProtocol is the protocol negotiated with every compatible machine of the last list
+var machinesProtocol = make(map[string]machineProtocol)
+
+// machinesIncompatible is the reason for which every incompatible machine of the last list is excluded
+var machinesIncompatible = make(map[string]string)
+var machinesProtocolMutex sync.RWMutex
+
+// GetCapabilities returns the capabilities which this node advertises to the other machines
+func GetCapabilities() []string {
+	capabilities := []string{types.CapabilityPayloadBinary, types.CapabilityLoadJson}
+	if utils.IsMachineHttp2Enabled() {
+		capabilities = append(capabilities, types.CapabilityHttp2)
+	}
+	if config.GetPeerTransport() == config.PeerTransportGrpc {
+		capabilities = append(capabilities, types.CapabilityGrpc)
+	}
+	return capabilities
+}
+
+// MachineSupports tells if the capability can be used with the machine. Machines which did not advertise their protocol
+// are not negotiated, so the capability is detected from their load.
+func MachineSupports(ip string, capability string) bool {
+	machinesProtocolMutex.RLock()
+	defer machinesProtocolMutex.RUnlock()
+
+	protocol, exists := machinesProtocol[ip]
+	return !exists || protocol.Capabilities[capability]
+}
+
+// negotiateMachinesProtocol negotiates the protocol with the machines of the list and returns the compatible ones, the
+// reason for which a machine is excluded is logged only when it changes
+func negotiateMachinesProtocol(machines []Machine) []Machine {
+	compatible := make([]Machine, 0, len(machines))
+	protocols := make(map[string]machineProtocol)
+	incompatible := make(map[string]string)
+
+	for _, machine := range machines {
+		protocol, reason := negotiateMachineProtocol(&machine)
+		if reason != "" {
+			incompatible[machine.IP] = reason
+			continue
+		}
+		if protocol != nil {
+			protocols[machine.IP] = *protocol
+		}
+		compatible = append(compatible, machine)
+	}
+
+	machinesProtocolMutex.Lock()
+	defer machinesProtocolMutex.Unlock()
+
+	for ip, reason := range incompatible {
+		if machinesIncompatible[ip] != reason {
+			log.Log.Warningf("Excluding machine %s from selection: %s", ip, reason)
+		}
+	}
+	for ip := range machinesIncompatible {
+		if _, exists := incompatible[ip]; !exists {
+			log.Log.Infof("Machine %s is compatible again", ip)
+		}
+	}
+
+	machinesProtocol = protocols
+	machinesIncompatible = incompatible
+
+	return compatible
+}
+
+// negotiateMachineProtocol returns the protocol to use with the machine, nil if it did not advertise it, or the reason
+// for which the machine is not compatible
+func negotiateMachineProtocol(machine *Machine) (*machineProtocol, string) {
+	if machine.ProtocolVersion < int(config.GetPeerMinProtocolVersion()) {
+		if machine.ProtocolVersion == 0 {
+			return nil, "it does not advertise its protocol version"
+		}
+		return nil, fmt.Sprintf("its protocol version %d is older than %d", machine.ProtocolVersion, config.GetPeerMinProtocolVersion())
+	}
+
+	advertised := make(map[string]bool, len(machine.Capabilities))
+	for _, capability := range machine.Capabilities {
+		advertised[capability] = true
+	}
+	for _, capability := range config.GetPeerRequiredCapabilities() {
+		if !advertised[capability] {
+			return nil, fmt.Sprintf("it does not support the required capability %s", capability)
+		}
+	}
+
+	if machine.ProtocolVersion == 0 {
+		return nil, ""
+	}
+
+	protocol := &machineProtocol{Capabilities: make(map[string]bool)}
+	for _, capability := range GetCapabilities() {
+		if advertised[capability] {
+			protocol.Capabilities[capability] = true
+		}
+	}
+
+	return protocol, ""
+}
@@ -25,6 +25,7 @@ package service_discovery
 import (
 	s_config "scheduler/config"
 	"scheduler/log"
+	"scheduler/types"
 	"time"
 )
 
@@ -42,6 +43,15 @@ func init() {
 		log.Log.Infof("Init machine as %s (%s) with service_discovery configuration ", Configuration.MachineId, Configuration.MachineIp)
 		break
 	}
+
+	// the other machines learn the protocol of this node from the service_discovery service, if it cannot be set they
+	// will detect it from the load
+	protocol := &ServiceProtocol{ProtocolVersion: types.ProtocolVersion, Capabilities: GetCapabilities()}
+	if err := SetProtocol(protocol); err != nil {
+		log.Log.Warningf("Cannot advertise protocol version %d with capabilities %v: %s", protocol.ProtocolVersion, protocol.Capabilities, err)
+	} else {
+		log.Log.Infof("Advertised protocol version %d with capabilities %v", protocol.ProtocolVersion, protocol.Capabilities)
+	}
 }
 
 func Start() {
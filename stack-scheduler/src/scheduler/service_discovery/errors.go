@@ -27,3 +27,18 @@ type ErrorCannotGetServerList struct {
 func (e ErrorCannotGetServerList) Error() string {
 	return fmt.Sprintf("Cannot get peers list: %s", e.err)
 }
+
+type ErrorNoMachineHostsFunction struct {
+	functionName string
+}
+
+func (e ErrorNoMachineHostsFunction) Error() string {
+	return fmt.Sprintf("No peer hosts function %s", e.functionName)
+}
+
+// ErrorAllMachinesEjected is returned when all the known peers are ejected since they are not healthy
+type ErrorAllMachinesEjected struct{}
+
+func (e ErrorAllMachinesEjected) Error() string {
+	return "All peers are ejected"
+}
@@ -0,0 +1,196 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package service_discovery
+
+import (
+	"scheduler/peer_health"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MachineFunctions are the functions advertised by a machine, as function name -> version
+type MachineFunctions struct {
+	// Functions is nil if the machine did not advertise its functions
+	Functions map[string]string `json:"functions"`
+	// Unavailable are the hosted functions which the machine cannot execute now, since their circuit breaker is open
+	Unavailable []string `json:"unavailable,omitempty"`
+	// Warm are the functions which do not pay a cold start on the machine
+	Warm      []string  `json:"warm,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+var machinesFunctions = make(map[string]MachineFunctions)
+var machinesFunctionsMutex sync.RWMutex
+
+// SetMachineFunctions records the functions advertised by the machine
+func SetMachineFunctions(ip string, functions map[string]string) {
+	machinesFunctionsMutex.Lock()
+	defer machinesFunctionsMutex.Unlock()
+
+	if functions == nil {
+		functions = make(map[string]string)
+	}
+	machineFunctions := machinesFunctions[ip]
+	machineFunctions.Functions = functions
+	machineFunctions.UpdatedAt = time.Now()
+	machinesFunctions[ip] = machineFunctions
+}
+
+// SetMachineUnavailableFunctions records the functions which the machine cannot execute now, they are advertised also
+// by the machines which cannot list their functions
+func SetMachineUnavailableFunctions(ip string, unavailable []string) {
+	machinesFunctionsMutex.Lock()
+	defer machinesFunctionsMutex.Unlock()
+
+	machineFunctions := machinesFunctions[ip]
+	machineFunctions.Unavailable = unavailable
+	machineFunctions.UpdatedAt = time.Now()
+	machinesFunctions[ip] = machineFunctions
+}
+
+// SetMachineWarmFunctions records the functions which do not pay a cold start on the machine
+func SetMachineWarmFunctions(ip string, warm []string) {
+	machinesFunctionsMutex.Lock()
+	defer machinesFunctionsMutex.Unlock()
+
+	machineFunctions := machinesFunctions[ip]
+	machineFunctions.Warm = warm
+	machineFunctions.UpdatedAt = time.Now()
+	machinesFunctions[ip] = machineFunctions
+}
+
+// IsFunctionWarmOnMachine tells if the machine advertised the function as warm
+func IsFunctionWarmOnMachine(ip string, functionName string) bool {
+	machinesFunctionsMutex.RLock()
+	defer machinesFunctionsMutex.RUnlock()
+
+	for _, warm := range machinesFunctions[ip].Warm {
+		if warm == functionName {
+			return true
+		}
+	}
+	return false
+}
+
+// RetainMachines forgets the functions, the peer protocol and the health of the machines not in the list
+func RetainMachines(ips []string) {
+	alive := make(map[string]bool, len(ips))
+	for _, ip := range ips {
+		alive[ip] = true
+	}
+
+	retainMachinesPeerProtocol(alive)
+	peer_health.Retain(ips)
+
+	machinesFunctionsMutex.Lock()
+	defer machinesFunctionsMutex.Unlock()
+
+	for ip := range machinesFunctions {
+		if !alive[ip] {
+			delete(machinesFunctions, ip)
+		}
+	}
+}
+
+// GetMachinesFunctions returns the functions advertised by every known machine
+func GetMachinesFunctions() map[string]MachineFunctions {
+	machinesFunctionsMutex.RLock()
+	defer machinesFunctionsMutex.RUnlock()
+
+	out := make(map[string]MachineFunctions, len(machinesFunctions))
+	for ip, machineFunctions := range machinesFunctions {
+		out[ip] = machineFunctions
+	}
+	return out
+}
+
+// MachineHostsFunction tells if the machine can execute the function. Machines which did not advertise their functions,
+// as the ones of backends unable to list them, are considered hosting every function, so only the machines whose
+// advertised list lacks the function or marks it unavailable are excluded.
+func MachineHostsFunction(ip string, functionName string) bool {
+	machinesFunctionsMutex.RLock()
+	defer machinesFunctionsMutex.RUnlock()
+
+	machineFunctions, exists := machinesFunctions[ip]
+	if !exists {
+		return true
+	}
+	if _, hosted := machineFunctions.Functions[functionName]; machineFunctions.Functions != nil && !hosted {
+		return false
+	}
+	for _, unavailable := range machineFunctions.Unavailable {
+		if unavailable == functionName {
+			return false
+		}
+	}
+	return true
+}
+
+// FilterMachinesHostingFunction returns the machines of the list which host the function
+func FilterMachinesHostingFunction(ips []string, functionName string) []string {
+	var out []string
+	for _, ip := range ips {
+		if MachineHostsFunction(ip, functionName) {
+			out = append(out, ip)
+		}
+	}
+	return out
+}
+
+// EncodeMachineFunctions encodes the functions in the "name=version,name=version" format used in headers
+func EncodeMachineFunctions(functions map[string]string) string {
+	var entries []string
+	for name, version := range functions {
+		entries = append(entries, name+"="+version)
+	}
+	sort.Strings(entries)
+	return strings.Join(entries, ",")
+}
+
+// DecodeFunctionsList decodes a "name,name" list of functions
+func DecodeFunctionsList(value string) []string {
+	var functions []string
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name != "" {
+			functions = append(functions, name)
+		}
+	}
+	return functions
+}
+
+// DecodeMachineFunctions decodes the functions encoded with EncodeMachineFunctions
+func DecodeMachineFunctions(value string) map[string]string {
+	functions := make(map[string]string)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) == 2 {
+			functions[parts[0]] = parts[1]
+		} else {
+			functions[parts[0]] = ""
+		}
+	}
+	return functions
+}
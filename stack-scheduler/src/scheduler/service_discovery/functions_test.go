@@ -0,0 +1,137 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package service_discovery
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"scheduler/config"
+	"testing"
+)
+
+// testDiscovery replies to the configuration requests of the init of the package, which waits for the service
+// discovery, package variables are initialized before it
+var testDiscovery = startTestDiscovery()
+
+func startTestDiscovery() *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"machine_ip":"10.0.0.100","machine_id":"test"}`))
+	}))
+	host, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	_ = os.Setenv(config.EnvServiceDiscoveryListeningHost, host)
+	_ = os.Setenv(config.EnvServiceDiscoveryListeningPort, port)
+	config.InitConfigurationStatic()
+	return server
+}
+
+func resetMachinesFunctions() {
+	machinesFunctionsMutex.Lock()
+	machinesFunctions = make(map[string]MachineFunctions)
+	machinesFunctionsMutex.Unlock()
+}
+
+func TestMachineHostsFunction(t *testing.T) {
+	resetMachinesFunctions()
+
+	SetMachineFunctions("10.0.0.1", map[string]string{"fn": "1", "other": "2"})
+	SetMachineFunctions("10.0.0.2", nil)
+
+	if !MachineHostsFunction("10.0.0.1", "fn") {
+		t.Fatalf("expected 10.0.0.1 to host fn")
+	}
+	if MachineHostsFunction("10.0.0.1", "missing") {
+		t.Fatalf("expected 10.0.0.1 not to host a function it did not advertise")
+	}
+	if MachineHostsFunction("10.0.0.2", "fn") {
+		t.Fatalf("expected 10.0.0.2 not to host fn since it advertised no function")
+	}
+	if !MachineHostsFunction("10.0.0.3", "fn") {
+		t.Fatalf("expected a machine which did not advertise its functions to host every function")
+	}
+}
+
+func TestMachineHostsFunctionUnavailable(t *testing.T) {
+	resetMachinesFunctions()
+
+	SetMachineFunctions("10.0.0.1", map[string]string{"fn": "1", "other": "2"})
+	SetMachineUnavailableFunctions("10.0.0.1", []string{"fn"})
+	// the machine cannot list its functions but advertises the unavailable ones
+	SetMachineUnavailableFunctions("10.0.0.2", []string{"fn"})
+
+	if MachineHostsFunction("10.0.0.1", "fn") {
+		t.Fatalf("expected 10.0.0.1 not to host the unavailable fn")
+	}
+	if !MachineHostsFunction("10.0.0.1", "other") {
+		t.Fatalf("expected 10.0.0.1 to host other")
+	}
+	if MachineHostsFunction("10.0.0.2", "fn") {
+		t.Fatalf("expected 10.0.0.2 not to host the unavailable fn")
+	}
+	if !MachineHostsFunction("10.0.0.2", "other") {
+		t.Fatalf("expected 10.0.0.2 to host every available function")
+	}
+
+	SetMachineUnavailableFunctions("10.0.0.1", nil)
+	if !MachineHostsFunction("10.0.0.1", "fn") {
+		t.Fatalf("expected 10.0.0.1 to host fn once it is available again")
+	}
+}
+
+func TestFilterMachinesHostingFunction(t *testing.T) {
+	resetMachinesFunctions()
+
+	SetMachineFunctions("10.0.0.1", map[string]string{"fn": "1"})
+	SetMachineFunctions("10.0.0.2", map[string]string{"other": "1"})
+	SetMachineUnavailableFunctions("10.0.0.3", []string{"fn"})
+
+	hosting := FilterMachinesHostingFunction([]string{"10.0.0.1", "10.0.0.2", "10.0.0.3", "10.0.0.4"}, "fn")
+	if !reflect.DeepEqual(hosting, []string{"10.0.0.1", "10.0.0.4"}) {
+		t.Fatalf("unexpected machines hosting fn %v", hosting)
+	}
+}
+
+func TestRetainMachinesForgetsFunctions(t *testing.T) {
+	resetMachinesFunctions()
+
+	SetMachineFunctions("10.0.0.1", map[string]string{"other": "1"})
+	SetMachineFunctions("10.0.0.2", map[string]string{"other": "1"})
+	RetainMachines([]string{"10.0.0.2"})
+
+	if !MachineHostsFunction("10.0.0.1", "fn") {
+		t.Fatalf("expected the functions of the removed machine to be forgotten")
+	}
+	if MachineHostsFunction("10.0.0.2", "fn") {
+		t.Fatalf("expected the functions of the retained machine to be kept")
+	}
+}
+
+func TestEncodeDecodeMachineFunctions(t *testing.T) {
+	functions := map[string]string{"fn": "1", "other": ""}
+
+	encoded := EncodeMachineFunctions(functions)
+	if encoded != "fn=1,other=" {
+		t.Fatalf("unexpected encoding %s", encoded)
+	}
+	if decoded := DecodeMachineFunctions(encoded + ", plain"); !reflect.DeepEqual(decoded, map[string]string{"fn": "1", "other": "", "plain": ""}) {
+		t.Fatalf("unexpected decoding %v", decoded)
+	}
+}
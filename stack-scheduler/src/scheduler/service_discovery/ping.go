@@ -0,0 +1,47 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package service_discovery
+
+import "sync"
+
+// machinesPing is the ping in seconds of every machine of the last list, as measured by the service_discovery service
+var machinesPing = make(map[string]float64)
+var machinesPingMutex sync.RWMutex
+
+// GetMachinePing returns the ping in seconds of the machine measured by the service_discovery service, false if it is
+// not known
+func GetMachinePing(ip string) (float64, bool) {
+	machinesPingMutex.RLock()
+	defer machinesPingMutex.RUnlock()
+
+	ping, exists := machinesPing[ip]
+	return ping, exists && ping > 0
+}
+
+func setMachinesPing(machines []Machine) {
+	ping := make(map[string]float64, len(machines))
+	for _, machine := range machines {
+		ping[machine.IP] = machine.Ping
+	}
+
+	machinesPingMutex.Lock()
+	defer machinesPingMutex.Unlock()
+
+	machinesPing = ping
+}
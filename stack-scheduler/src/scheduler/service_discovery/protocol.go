@@ -0,0 +1,97 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package service_discovery
+
+import (
+	"scheduler/types"
+	"sync"
+)
+
+// machinesPeerProtocol is the latest peer protocol advertised by every machine
+var machinesPeerProtocol = make(map[string]int)
+var machinesPeerProtocolMutex sync.RWMutex
+
+// machinesGrpcPort is the port of the grpc peer service of the machines which expose it
+var machinesGrpcPort = make(map[string]int)
+
+// SetMachinePeerProtocol records the latest peer protocol supported by the machine
+func SetMachinePeerProtocol(ip string, version int) {
+	machinesPeerProtocolMutex.Lock()
+	defer machinesPeerProtocolMutex.Unlock()
+
+	machinesPeerProtocol[ip] = version
+}
+
+// GetMachinePeerProtocol returns the peer protocol to use with the machine, which is the latest version supported by
+// both. Machines which did not advertise a version, or the binary payload capability, only support the json protocol.
+func GetMachinePeerProtocol(ip string) int {
+	machinesPeerProtocolMutex.RLock()
+	version, exists := machinesPeerProtocol[ip]
+	machinesPeerProtocolMutex.RUnlock()
+
+	if !exists || version < types.PeerProtocolVersionJson || !MachineSupports(ip, types.CapabilityPayloadBinary) {
+		return types.PeerProtocolVersionJson
+	}
+	if version > types.PeerProtocolVersion {
+		return types.PeerProtocolVersion
+	}
+	return version
+}
+
+// SetMachineGrpcPort records the port of the grpc peer service of the machine, 0 if it does not expose it
+func SetMachineGrpcPort(ip string, port int) {
+	machinesPeerProtocolMutex.Lock()
+	defer machinesPeerProtocolMutex.Unlock()
+
+	if port > 0 {
+		machinesGrpcPort[ip] = port
+	} else {
+		delete(machinesGrpcPort, ip)
+	}
+}
+
+// GetMachineGrpcPort returns the port of the grpc peer service of the machine, false if it did not advertise it
+func GetMachineGrpcPort(ip string) (int, bool) {
+	if !MachineSupports(ip, types.CapabilityGrpc) {
+		return 0, false
+	}
+
+	machinesPeerProtocolMutex.RLock()
+	defer machinesPeerProtocolMutex.RUnlock()
+
+	port, exists := machinesGrpcPort[ip]
+	return port, exists
+}
+
+// retainMachinesPeerProtocol forgets the protocol of the machines not in the list
+func retainMachinesPeerProtocol(alive map[string]bool) {
+	machinesPeerProtocolMutex.Lock()
+	defer machinesPeerProtocolMutex.Unlock()
+
+	for ip := range machinesPeerProtocol {
+		if !alive[ip] {
+			delete(machinesPeerProtocol, ip)
+		}
+	}
+	for ip := range machinesGrpcPort {
+		if !alive[ip] {
+			delete(machinesGrpcPort, ip)
+		}
+	}
+}
@@ -21,16 +21,18 @@ package service_discovery
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"math/rand"
 	"scheduler/log"
+	"scheduler/peer_health"
 	"scheduler/utils"
 	"time"
 )
 
-// GetMachinesIpsList get the list of known server by asking the backend stack-service that is running in the same
-// machine of this service
-func GetMachinesIpsList() ([]string, error) {
+// GetMachinesList get the list of known alive servers, with all their details, by asking the backend stack-service that
+// is running in the same machine of this service
+func GetMachinesList() ([]Machine, error) {
 	// get the backend
 	res, err := utils.HttpGet(getListApiUrl())
 	if err != nil {
@@ -39,7 +41,6 @@ func GetMachinesIpsList() ([]string, error) {
 	}
 
 	var machines []Machine
-	var values []string
 
 	response, _ := ioutil.ReadAll(res.Body)
 	_ = res.Body.Close()
@@ -50,6 +51,21 @@ func GetMachinesIpsList() ([]string, error) {
 		return nil, err
 	}
 
+	return machines, nil
+}
+
+// GetMachinesIpsList get the list of known server by asking the backend stack-service that is running in the same
+// machine of this service, the machines whose protocol is not compatible are excluded
+func GetMachinesIpsList() ([]string, error) {
+	machines, err := GetMachinesList()
+	if err != nil {
+		return nil, err
+	}
+	setMachinesPing(machines)
+	machines = negotiateMachinesProtocol(machines)
+
+	var values []string
+
 	machinesN := int64(0)
 	for _, machine := range machines {
 		values = append(values, machine.IP)
@@ -62,27 +78,63 @@ func GetMachinesIpsList() ([]string, error) {
 	return values, nil
 }
 
-// GetNRandomMachines returns N different random servers (ip addresses) from the list
+// GetNRandomMachines returns N different random servers (ip addresses) from the list, the ejected ones are excluded
 func GetNRandomMachines(n uint, cached bool) ([]string, error) {
 	if n == 0 {
 		return nil, nil
 	}
 
-	var err error
-	var list []string
+	list, err := getMachinesIpsList(cached)
+	if err != nil || len(list) == 0 {
+		return nil, &ErrorCannotGetServerList{err}
+	}
 
-	if cached {
-		list, err = GetMachinesIpsList()
-	} else {
-		list, err = GetCachedMachinesIpsList()
+	list = peer_health.FilterEjected(list)
+	if len(list) == 0 {
+		return nil, &ErrorAllMachinesEjected{}
 	}
 
+	return pickNRandomMachines(list, n), nil
+}
+
+// GetNRandomMachinesHostingFunction returns at most N different random servers (ip addresses) which can execute the
+// function, as told by MachineHostsFunction, and are not ejected
+func GetNRandomMachinesHostingFunction(n uint, cached bool, functionName string) ([]string, error) {
+	if n == 0 {
+		return nil, nil
+	}
+
+	list, err := getMachinesIpsList(cached)
 	if err != nil || len(list) == 0 {
 		return nil, &ErrorCannotGetServerList{err}
 	}
+
+	list = FilterMachinesHostingFunction(list, functionName)
+	if len(list) == 0 {
+		return nil, &ErrorNoMachineHostsFunction{functionName}
+	}
+	list = peer_health.FilterEjected(list)
+	if len(list) == 0 {
+		return nil, &ErrorAllMachinesEjected{}
+	}
+	if n > uint(len(list)) {
+		n = uint(len(list))
+	}
+
+	return pickNRandomMachines(list, n), nil
+}
+
+func getMachinesIpsList(cached bool) ([]string, error) {
+	if cached {
+		return GetMachinesIpsList()
+	}
+	return GetCachedMachinesIpsList()
+}
+
+func pickNRandomMachines(list []string, n uint) []string {
 	// if all machines are requested do not pick at random
 	if n == uint(len(list)) {
-		return list, nil
+		return list
 	}
 
 	randomSource := rand.NewSource(time.Now().UnixNano())
@@ -106,7 +158,7 @@ func GetNRandomMachines(n uint, cached bool) ([]string, error) {
 		picked = append(picked, randomI)
 		out = append(out, list[randomI])
 	}
-	return out, nil
+	return out
 }
 
 // GetMachineIpAtIndex returns the machine ip at index i
@@ -156,3 +208,24 @@ func GetConfiguration() (*ServiceConfiguration, error) {
 
 	return &discoveryConfig, nil
 }
+
+// SetProtocol advertises the protocol of this node through the service_discovery service
+func SetProtocol(protocol *ServiceProtocol) error {
+	protocolJson, err := json.Marshal(protocol)
+	if err != nil {
+		return err
+	}
+
+	res, err := utils.HttpPostJSON(getConfigurationApiUrl(), string(protocolJson))
+	if err != nil {
+		log.Log.Error("Cannot set protocol in backend")
+		return err
+	}
+	_, _ = io.Copy(ioutil.Discard, res.Body)
+	_ = res.Body.Close()
+
+	if res.StatusCode != 200 {
+		return fmt.Errorf("unexpected status code %d", res.StatusCode)
+	}
+	return nil
+}
@@ -19,10 +19,12 @@
 package service_discovery
 
 type ServiceConfiguration struct {
-	MachineIp       string   `json:"machine_ip" bson:"machine_ip"`
-	MachineId       string   `json:"machine_id" bson:"machine_id"`
-	MachineFogNetId string   `json:"machine_fog_net_id" bson:"machine_fog_net_id"`
-	InitServers     []string `json:"init_servers" bson:"init_servers"`
+	MachineIp       string `json:"machine_ip" bson:"machine_ip"`
+	MachineId       string `json:"machine_id" bson:"machine_id"`
+	MachineFogNetId string `json:"machine_fog_net_id" bson:"machine_fog_net_id"`
+	// MachineGroupName is the group of the machine, as it is set in the service_discovery service
+	MachineGroupName string   `json:"machine_group_name" bson:"machine_group_name"`
+	InitServers      []string `json:"init_servers" bson:"init_servers"`
 }
 
 type Machine struct {
@@ -40,4 +42,13 @@ type Machine struct {
 	// DeadPolls tells the number of consecutive times the machine timed out. This is set to 0 when the machine replies
 	// correctly
 	DeadPolls uint `json:"dead_polls" bson:"dead_polls"`
+	// ProtocolVersion and Capabilities are advertised by the machine, the version is 0 if it does not advertise them
+	ProtocolVersion int      `json:"protocol_version" bson:"protocol_version"`
+	Capabilities    []string `json:"capabilities" bson:"capabilities"`
+}
+
+// ServiceProtocol is the protocol of this node, which the service_discovery service advertises to the other machines
+type ServiceProtocol struct {
+	ProtocolVersion int      `json:"protocol_version"`
+	Capabilities    []string `json:"capabilities"`
 }
@@ -21,10 +21,11 @@ package service_learning
 import (
 	"fmt"
 	"scheduler/config"
+	"scheduler/mtls"
 )
 
 func getApiUrl() string {
-	return fmt.Sprintf("http://%s:%d", config.GetServiceLearningListeningHost(), config.GetServiceLearningListeningPort())
+	return fmt.Sprintf("%s://%s:%d", mtls.HttpScheme(), config.GetServiceLearningListeningHost(), config.GetServiceLearningListeningPort())
 }
 
 func getApiUrlTrain() string {
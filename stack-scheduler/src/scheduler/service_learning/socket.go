@@ -23,6 +23,7 @@ import (
 	"github.com/gorilla/websocket"
 	"scheduler/config"
 	"scheduler/log"
+	"scheduler/mtls"
 	"scheduler/utils"
 	"strconv"
 	"strings"
@@ -155,11 +156,20 @@ func socketConnect() (*websocket.Conn, error) {
 	var err error
 	var socket *websocket.Conn
 
-	socketUrl := fmt.Sprintf("ws://%s:8765", config.GetServiceLearningListeningHost()) //  + "/socket"
+	socketUrl := fmt.Sprintf("%s://%s:8765", mtls.WsScheme(), config.GetServiceLearningListeningHost()) //  + "/socket"
 
 	log.Log.Debugf("Trying to connect to websocket to: %s", socketUrl)
 
-	socket, _, err = websocket.DefaultDialer.Dial(socketUrl, nil)
+	dialer := websocket.DefaultDialer
+	if mtls.Enabled() {
+		dialer = &websocket.Dialer{
+			Proxy:            websocket.DefaultDialer.Proxy,
+			HandshakeTimeout: websocket.DefaultDialer.HandshakeTimeout,
+			TLSClientConfig:  mtls.ClientConfig(),
+		}
+	}
+
+	socket, _, err = dialer.Dial(socketUrl, nil)
 	if err != nil {
 		log.Log.Errorf("Error connecting to websocket server:", err)
 		return nil, err
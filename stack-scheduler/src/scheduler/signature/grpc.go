@@ -0,0 +1,59 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package signature
+
+import (
+	"context"
+	"google.golang.org/grpc/metadata"
+	"strings"
+)
+
+// MethodGrpc is the method of the signatures of grpc calls, whose uri is the full grpc method. The messages are
+// streamed, so the body is not covered by the signature of grpc calls.
+const MethodGrpc = "GRPC"
+
+// AppendToOutgoingContext adds the signature of the call to the metadata sent with it
+func AppendToOutgoingContext(ctx context.Context, fullMethod string) context.Context {
+	signature := Sign(MethodGrpc, fullMethod, nil)
+	return metadata.AppendToOutgoingContext(ctx,
+		strings.ToLower(HttpHeaderKeyId), signature.KeyId,
+		strings.ToLower(HttpHeaderTimestamp), signature.Timestamp,
+		strings.ToLower(HttpHeaderNonce), signature.Nonce,
+		strings.ToLower(HttpHeaderSignature), signature.Value,
+	)
+}
+
+// VerifyIncomingContext checks the signature in the metadata of the call
+func VerifyIncomingContext(ctx context.Context, fullMethod string) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	get := func(key string) string {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+
+	signature := &Signature{
+		KeyId:     get(HttpHeaderKeyId),
+		Timestamp: get(HttpHeaderTimestamp),
+		Nonce:     get(HttpHeaderNonce),
+		Value:     get(HttpHeaderSignature),
+	}
+	return Verify(signature, MethodGrpc, fullMethod, nil)
+}
@@ -0,0 +1,67 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package signature
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+)
+
+const HttpHeaderKeyId = "X-P2pfaas-Signature-Key"
+const HttpHeaderTimestamp = "X-P2pfaas-Signature-Timestamp"
+const HttpHeaderNonce = "X-P2pfaas-Signature-Nonce"
+const HttpHeaderSignature = "X-P2pfaas-Signature"
+
+// SignRequest adds the signature headers to the request, body must be the one of the request
+func SignRequest(req *http.Request, body []byte) {
+	SetHeaders(req.Header, Sign(req.Method, req.URL.RequestURI(), body))
+}
+
+// VerifyRequest checks the signature of the request, the body is read and replaced so that it can be read again
+func VerifyRequest(req *http.Request) error {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	return Verify(FromHeaders(req.Header), req.Method, req.URL.RequestURI(), body)
+}
+
+func SetHeaders(header http.Header, signature *Signature) {
+	header.Set(HttpHeaderKeyId, signature.KeyId)
+	header.Set(HttpHeaderTimestamp, signature.Timestamp)
+	header.Set(HttpHeaderNonce, signature.Nonce)
+	header.Set(HttpHeaderSignature, signature.Value)
+}
+
+func FromHeaders(header http.Header) *Signature {
+	return &Signature{
+		KeyId:     header.Get(HttpHeaderKeyId),
+		Timestamp: header.Get(HttpHeaderTimestamp),
+		Nonce:     header.Get(HttpHeaderNonce),
+		Value:     header.Get(HttpHeaderSignature),
+	}
+}
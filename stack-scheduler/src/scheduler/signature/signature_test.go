@@ -0,0 +1,165 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package signature
+
+import (
+	"bytes"
+	"context"
+	"google.golang.org/grpc/metadata"
+	"io/ioutil"
+	"net/http"
+	"scheduler/config"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func startTestSignature(t *testing.T, keys string) {
+	t.Setenv(config.EnvClusterKeys, keys)
+	t.Setenv(config.EnvSignatureWindow, "1000")
+	config.InitConfigurationStatic()
+}
+
+// signAt signs the request with the first cluster key as if it was signed at the passed time
+func signAt(method string, uri string, body []byte, signedAt time.Time) *Signature {
+	signature := Sign(method, uri, body)
+	signature.Timestamp = strconv.FormatInt(signedAt.UnixNano()/int64(time.Millisecond), 10)
+	signature.Value = compute(config.GetClusterKeys()[0].Secret, method, uri, body, signature)
+	return signature
+}
+
+func newTestRequest(t *testing.T, body string) *http.Request {
+	req, err := http.NewRequest("POST", "http://node/peer/function/fn?x=1", bytes.NewBufferString(body))
+	if err != nil {
+		t.Fatalf("cannot create the request: %s", err.Error())
+	}
+	SignRequest(req, []byte(body))
+	return req
+}
+
+func TestVerifyRequest(t *testing.T) {
+	startTestSignature(t, "k1:secret1")
+
+	req := newTestRequest(t, "payload")
+	if err := VerifyRequest(req); err != nil {
+		t.Fatalf("expected a valid signature, got %s", err.Error())
+	}
+	body, _ := ioutil.ReadAll(req.Body)
+	if string(body) != "payload" {
+		t.Fatalf("expected the body to be readable again, got %q", body)
+	}
+}
+
+func TestVerifyRejectsTamperedRequest(t *testing.T) {
+	startTestSignature(t, "k1:secret1")
+
+	req := newTestRequest(t, "payload")
+	req.Body = ioutil.NopCloser(bytes.NewBufferString("tampered"))
+	if _, ok := VerifyRequest(req).(ErrorInvalid); !ok {
+		t.Fatalf("expected a tampered body to be rejected")
+	}
+
+	signature := Sign("POST", "/peer/function/fn", nil)
+	if _, ok := Verify(signature, "POST", "/peer/function/other", nil).(ErrorInvalid); !ok {
+		t.Fatalf("expected a tampered uri to be rejected")
+	}
+}
+
+func TestVerifyRejectsMissingAndUnknownKey(t *testing.T) {
+	startTestSignature(t, "k1:secret1")
+
+	if _, ok := Verify(&Signature{}, "GET", "/", nil).(ErrorMissing); !ok {
+		t.Fatalf("expected a missing signature to be rejected")
+	}
+
+	signature := Sign("GET", "/", nil)
+	startTestSignature(t, "k2:secret2")
+	if _, ok := Verify(signature, "GET", "/", nil).(ErrorUnknownKey); !ok {
+		t.Fatalf("expected a signature with an unknown key to be rejected")
+	}
+}
+
+func TestVerifyWithRotatedKey(t *testing.T) {
+	startTestSignature(t, "old:secret1")
+	signature := Sign("GET", "/", nil)
+
+	startTestSignature(t, "new:secret2,old:secret1")
+	if err := Verify(signature, "GET", "/", nil); err != nil {
+		t.Fatalf("expected a signature with the old key to be valid, got %s", err.Error())
+	}
+}
+
+func TestVerifyRejectsStaleTimestamp(t *testing.T) {
+	startTestSignature(t, "k1:secret1")
+
+	stale := signAt("GET", "/", nil, time.Now().Add(-2*time.Second))
+	if _, ok := Verify(stale, "GET", "/", nil).(ErrorExpired); !ok {
+		t.Fatalf("expected a stale signature to be rejected")
+	}
+	future := signAt("GET", "/", nil, time.Now().Add(2*time.Second))
+	if _, ok := Verify(future, "GET", "/", nil).(ErrorExpired); !ok {
+		t.Fatalf("expected a signature from the future to be rejected")
+	}
+	recent := signAt("GET", "/", nil, time.Now().Add(-500*time.Millisecond))
+	if err := Verify(recent, "GET", "/", nil); err != nil {
+		t.Fatalf("expected a signature within the window to be valid, got %s", err.Error())
+	}
+}
+
+func TestVerifyRejectsReplayedRequest(t *testing.T) {
+	startTestSignature(t, "k1:secret1")
+
+	signature := Sign("POST", "/peer/function/fn", []byte("payload"))
+	if err := Verify(signature, "POST", "/peer/function/fn", []byte("payload")); err != nil {
+		t.Fatalf("expected a valid signature, got %s", err.Error())
+	}
+	if _, ok := Verify(signature, "POST", "/peer/function/fn", []byte("payload")).(ErrorReplayed); !ok {
+		t.Fatalf("expected a replayed request to be rejected")
+	}
+
+	// a forged request with the same nonce does not mark it as seen
+	forged := Sign("POST", "/peer/function/fn", nil)
+	forgedValue := forged.Value
+	forged.Value = "forged"
+	if _, ok := Verify(forged, "POST", "/peer/function/fn", nil).(ErrorInvalid); !ok {
+		t.Fatalf("expected a forged request to be rejected")
+	}
+	forged.Value = forgedValue
+	if err := Verify(forged, "POST", "/peer/function/fn", nil); err != nil {
+		t.Fatalf("expected the genuine request to be valid, got %s", err.Error())
+	}
+}
+
+func TestVerifyIncomingContext(t *testing.T) {
+	startTestSignature(t, "k1:secret1")
+
+	outgoing := AppendToOutgoingContext(context.Background(), "/peer.Peer/Execute")
+	md, _ := metadata.FromOutgoingContext(outgoing)
+	incoming := metadata.NewIncomingContext(context.Background(), md)
+
+	if err := VerifyIncomingContext(incoming, "/peer.Peer/Execute"); err != nil {
+		t.Fatalf("expected a valid signature, got %s", err.Error())
+	}
+	if _, ok := VerifyIncomingContext(incoming, "/peer.Peer/Execute").(ErrorReplayed); !ok {
+		t.Fatalf("expected a replayed call to be rejected")
+	}
+	if _, ok := VerifyIncomingContext(context.Background(), "/peer.Peer/Execute").(ErrorMissing); !ok {
+		t.Fatalf("expected an unsigned call to be rejected")
+	}
+}
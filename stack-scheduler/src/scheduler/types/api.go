@@ -19,23 +19,80 @@
 package types
 
 import (
+	"io"
 	"net/http"
 	"time"
 )
 
+// LoadVersion is the version of the Load document served by the load api, it is increased when fields change meaning
+const LoadVersion = 1
+
+// Load is the document served by the load api
 type Load struct {
+	Version int `json:"version"`
 	// general info
 	SchedulerName string `json:"scheduler_name"`
 	// functions-related
-	FunctionsDeployed      uint `json:"functions_deployed"`
-	FunctionsTotalReplicas uint `json:"functions_total_replicas"`
-	FunctionsRunning       uint `json:"functions_running"`
-	FunctionsRunningMax    uint `json:"functions_running_max"`
+	FunctionsRunning    uint `json:"functions_running"`
+	FunctionsRunningMax uint `json:"functions_running_max"`
 	// queue-related
+	QueueLength    uint `json:"queue_length"`
 	QueueLengthMax uint `json:"queue_max_length"`
-	QueueFill      int  `json:"queue_fill"`
+	// RunningOfTypes and QueuedOfTypes are the running and queued jobs of every job type
+	RunningOfTypes map[int64]int64 `json:"running_of_types"`
+	QueuedOfTypes  map[int64]int64 `json:"queued_of_types"`
+	// Functions is the load of every function which has been executed
+	Functions map[string]FunctionLoad `json:"functions"`
+	// FunctionsDeployed are the deployed functions as name -> version, omitted if they cannot be retrieved
+	FunctionsDeployed map[string]string `json:"functions_deployed,omitempty"`
+	// FunctionsUnavailable are the functions whose circuit breaker is open
+	FunctionsUnavailable []string `json:"functions_unavailable"`
+	// Draining is set when the node does not accept jobs from peers
+	Draining bool `json:"draining"`
+	// FunctionsWarm are the functions with a replica which already served a call, they do not pay a cold start
+	FunctionsWarm []string `json:"functions_warm"`
+}
+
+// FunctionLoad is the load of a single function
+type FunctionLoad struct {
+	Running uint `json:"running"`
+	Queued  uint `json:"queued"`
+	// LatencyEwma is the moving average in seconds of the time spent by jobs in the queue and in execution
+	LatencyEwma float64 `json:"latency_ewma"`
+	// ExecutionTimeEwma is the moving average in seconds of the execution time of jobs
+	ExecutionTimeEwma float64 `json:"execution_time_ewma"`
+	// Warm is set when a new call of the function is not expected to pay a cold start
+	Warm bool `json:"warm"`
 }
 
+// PeerProtocolVersionJson is the peer protocol in which the job request and response are JSON objects, with the payload
+// and the body base64 encoded
+const PeerProtocolVersionJson = 1
+
+// PeerProtocolVersionBinary is the peer protocol in which the payload and the body are sent as raw bytes, and the other
+// fields of the job request and response are sent as JSON in the X-P2pfaas-Peer-Job header
+const PeerProtocolVersionBinary = 2
+
+// PeerProtocolVersion is the latest peer protocol supported by this node
+const PeerProtocolVersion = PeerProtocolVersionBinary
+
+// ProtocolVersion is the version of the apis between nodes, advertised through the service_discovery service. It is
+// increased when a job request, a job response or the load change in a way which older nodes cannot read.
+const ProtocolVersion = 1
+
+// the capabilities are the optional features of the protocol which a node advertises, two nodes use the ones which
+// both advertise
+const (
+	// CapabilityPayloadBinary is the binary peer protocol
+	CapabilityPayloadBinary = "payload-binary"
+	// CapabilityLoadJson is the load document served by the load api
+	CapabilityLoadJson = "load-json"
+	// CapabilityHttp2 is the peer api over h2c or http/2
+	CapabilityHttp2 = "http2"
+	// CapabilityGrpc is the grpc peer service
+	CapabilityGrpc = "grpc"
+)
+
 type PeerJobRequest struct {
 	// Function    faas_containers-openfaas.Function     `json:"function"`     // the function that we want to execute
 	ServiceIdRequest uint64            `json:"service_id_request"` // the service request id
@@ -43,15 +100,24 @@ type PeerJobRequest struct {
 	FunctionName     string            `json:"function_name"`      // the function name to execute
 	Hops             int               `json:"hops"`               // number of times the job is forwarded
 	PeersList        []PeersListMember `json:"peers_list"`         // list of peers that handled the job
-	Payload          string            `json:"payload"`            // the payload of the request in base64 string
+	Payload          string            `json:"payload"`            // the payload of the request in base64 string, only in the json protocol
 	ContentType      string            `json:"content_type"`       // the mime type of the payload
 	Headers          map[string]string `json:"headers"`            // the headers to add to the peer job request
 }
 
 type PeerJobResponse struct {
-	PeersList  []PeersListMember `json:"peers_list"`  // list of peers that handled the job
-	Body       string            `json:"body"`        // base64 encoded
-	StatusCode int               `json:"status_code"` // job response status code
+	PeersList  []PeersListMember  `json:"peers_list"`         // list of peers that handled the job
+	Body       string             `json:"body"`               // base64 encoded, only in the json protocol
+	StatusCode int                `json:"status_code"`        // job response status code
+	Attempts   []ExecutionAttempt `json:"attempts,omitempty"` // the executions of the job, more than one if retried
+}
+
+// ExecutionAttempt is an execution of a job, a job is executed more times when it is retried
+type ExecutionAttempt struct {
+	MachineIp  string  `json:"machine_ip"`
+	StatusCode int     `json:"status_code,omitempty"` // the status code of the function, 0 if it did not reply
+	Error      string  `json:"error,omitempty"`       // the class of the error when the function did not reply
+	Time       float64 `json:"time"`                  // the duration of the attempt in seconds
 }
 
 type PeersListMember struct {
@@ -72,10 +138,13 @@ type Timings struct {
 	TotalTime      *float64 `json:"total_time,omitempty"`      // elapsed time from job arrival til its completed execution
 	SchedulingTime *float64 `json:"scheduling_time,omitempty"` // elapsed time for a job to be scheduled
 	ProbingTime    *float64 `json:"probing_time,omitempty"`    // elapsed time for a job to probe other nodes
+	ColdStart      *bool    `json:"cold_start,omitempty"`      // if the execution paid a cold start, only for the executing node
 }
 
 type APIResponse struct {
 	Headers    http.Header `json:"headers"`
 	Body       []byte      `json:"body"`
 	StatusCode int         `json:"status_code"`
+	// BodyStream is the body of a streamed response, when it is set Body is empty and it must be closed
+	BodyStream io.ReadCloser `json:"-"`
 }
@@ -18,10 +18,60 @@
 
 package types
 
-import "net/http"
+import (
+	"io"
+	"net/http"
+)
 
 type FaasApiResponse struct {
 	Headers    http.Header
 	Body       []byte
 	StatusCode int
+	// ExecutionTime is the execution time of the function as it is told by the faas backend, nil if not available
+	ExecutionTime *float64
+	// ColdStart tells if the execution paid a cold start, nil if the faas backend cannot tell it
+	ColdStart *bool
+	// BodyStream is the body not read yet of a streamed response, when it is set Body is empty and it must be closed
+	BodyStream io.ReadCloser
+}
+
+// FaasService is the payload for deploying a function
+type FaasService struct {
+	OpenFaaSFunction FaasFunction `json:"openfaas_service,omitempty" bson:"openfaas_service"`
+	Deadline         uint64       `json:"deadline,omitempty" bson:"deadline"`
+}
+
+type FaasMachineResources struct {
+	Memory string `json:"memory,omitempty" bson:"memory"`
+	CPU    string `json:"cpu,omitempty" bson:"cpu"`
+}
+
+// FaasFunction describes a function, it follows the OpenFaaS function format which is used by all the faas backends
+type FaasFunction struct {
+	Name         string               `json:"name,omitempty" bson:"name"`
+	Service      string               `json:"service,omitempty" bson:"service"`
+	Network      string               `json:"network,omitempty" bson:"network"`
+	Image        string               `json:"image,omitempty" bson:"image"`
+	EnvProcess   string               `json:"envProcess,omitempty" bson:"envProcess"`
+	EnvVars      map[string]string    `json:"envVars,omitempty" bson:"envVars"`
+	Constraints  []string             `json:"constraints,omitempty" bson:"constraints"`
+	Labels       map[string]string    `json:"labels,omitempty" bson:"labels"`
+	Annotations  []string             `json:"annotations,omitempty" bson:"annotations"`
+	Secrets      []string             `json:"secrets,omitempty" bson:"secrets"`
+	RegistryAuth string               `json:"registryAuth,omitempty" bson:"registryAuth"`
+	Limits       FaasMachineResources `json:"limits,omitempty" bson:"limits"`
+	Requests     FaasMachineResources `json:"requests,omitempty" bson:"requests"`
+
+	InvocationCount   uint `json:"invocationCount,omitempty" bson:"invocationCount"`
+	Replicas          uint `json:"replicas,omitempty" bson:"replicas"`
+	AvailableReplicas uint `json:"availableReplicas,omitempty" bson:"availableReplicas"`
+}
+
+type FaasFunctionRemovePayload struct {
+	FunctionName string `json:"functionName" bson:"functionName"`
+}
+
+type FaasFunctionScalePayload struct {
+	Service  string `json:"service,omitempty" bson:"service"`
+	Replicas uint   `json:"replicas,omitempty" bson:"replicas"`
 }
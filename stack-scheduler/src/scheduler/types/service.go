@@ -18,6 +18,8 @@
 
 package types
 
+import "io"
+
 type ServiceRequest struct {
 	Id                 uint64 // unique id assigned to the request
 	IdTracing          string
@@ -28,4 +30,36 @@ type ServiceRequest struct {
 	Headers            *map[string]string
 	External           bool // If the service request comes from another node and not user
 	ExternalJobRequest *PeerJobRequest
+	Async              bool   // If the client does not wait for the result of the execution
+	JobLogId           string // Id of the request in the job log, if recorded
+	Stream             bool   // If the response of the function can be streamed to the client
+	// PayloadStream is the payload not read yet, when it is set Payload is empty. It is read only when the job is
+	// executed locally, otherwise it is buffered with ReadPayload.
+	PayloadStream io.Reader
+	// PayloadStreamLength is the length of the payload stream declared by the client, -1 if it is not known
+	PayloadStreamLength int64
+}
+
+// GetPayloadLength returns the length of the payload, also if it is not read yet, -1 if it is not known
+func (r *ServiceRequest) GetPayloadLength() int64 {
+	if r.PayloadStream != nil {
+		return r.PayloadStreamLength
+	}
+	return int64(len(r.Payload))
+}
+
+// ReadPayload reads the payload stream, if any, in Payload
+func (r *ServiceRequest) ReadPayload() error {
+	if r.PayloadStream == nil {
+		return nil
+	}
+
+	payload, err := io.ReadAll(r.PayloadStream)
+	if err != nil {
+		return err
+	}
+
+	r.Payload = payload
+	r.PayloadStream = nil
+	return nil
 }
@@ -20,6 +20,7 @@ package utils
 
 import (
 	"bytes"
+	"context"
 	"io"
 	"net/http"
 	"scheduler/config"
@@ -34,6 +35,7 @@ const HttpHeaderP2PFaaSProbingTime = "X-P2pfaas-Timing-Probing-Time-Seconds"
 const HttpHeaderP2PFaaSSchedulingTime = "X-P2pfaas-Timing-Scheduling-Time-Seconds"
 const HttpHeaderP2PFaaSProbeMessagesTime = "X-P2pfaas-Timing-Probe-Messages"
 const HttpHeaderP2PFaaSExternallyExecuted = "X-P2pfaas-Externally-Executed"
+const HttpHeaderP2PFaaSColdStart = "X-P2pfaas-Cold-Start"
 const HttpHeaderP2PFaaSHops = "X-P2pfaas-Hops"
 const HttpHeaderP2PFaaSPeersListIp = "X-P2pfaas-Peers-List-Ip"
 const HttpHeaderP2PFaaSPeersListId = "X-P2pfaas-Peers-List-Id"
@@ -42,6 +44,13 @@ const HttpHeaderP2PFaaSTotalTimingsList = "X-P2pfaas-Timing-Total-Seconds-List"
 const HttpHeaderP2PFaaSProbingTimingsList = "X-P2pfaas-Timing-Probing-Seconds-List"
 const HttpHeaderP2PFaaSSchedulingTimingsList = "X-P2pfaas-Timing-Scheduling-Seconds-List"
 
+// HttpHeaderP2PFaaSAttempts is the number of executions of a retried job, the other attempts headers list the
+// duration, the node and the outcome of every execution
+const HttpHeaderP2PFaaSAttempts = "X-P2pfaas-Attempts"
+const HttpHeaderP2PFaaSAttemptsTimingsList = "X-P2pfaas-Timing-Attempts-Seconds-List"
+const HttpHeaderP2PFaaSAttemptsIpList = "X-P2pfaas-Attempts-Ip-List"
+const HttpHeaderP2PFaaSAttemptsStatusList = "X-P2pfaas-Attempts-Status-List"
+
 // HttpHeaderP2PFaaSSchedulerBypass when set to request will always schedule the request internally
 const HttpHeaderP2PFaaSSchedulerBypass = "X-P2pfaas-Scheduler-Bypass"
 
@@ -53,6 +62,47 @@ const HttpHeaderP2PFaaSSchedulerReject = "X-P2pfaas-Scheduler-Reject"
 
 const HttpHeaderP2PFaaSSchedulerTracingId = "X-P2pfaas-Scheduler-Task-Tracing-Id"
 
+// HttpHeaderP2PFaaSPeerProtocol is the version of the peer protocol of a job request or response, and the latest version
+// supported by a node in the load api
+const HttpHeaderP2PFaaSPeerProtocol = "X-P2pfaas-Peer-Protocol"
+
+// HttpHeaderP2PFaaSProtocolVersion is the version of the apis between nodes of the machine which sent the request
+const HttpHeaderP2PFaaSProtocolVersion = "X-P2pfaas-Protocol-Version"
+
+// GrpcMetadataP2PFaaSProtocolVersion is the same of HttpHeaderP2PFaaSProtocolVersion for the grpc calls
+const GrpcMetadataP2PFaaSProtocolVersion = "x-p2pfaas-protocol-version"
+
+// HttpHeaderP2PFaaSPeerHttp2 is set by the load api of a node which accepts requests from other machines over h2c
+const HttpHeaderP2PFaaSPeerHttp2 = "X-P2pfaas-Peer-Http2"
+
+// HttpHeaderP2PFaaSPeerGrpcPort is set by the load api of a node which exposes the grpc peer service, to its port
+const HttpHeaderP2PFaaSPeerGrpcPort = "X-P2pfaas-Peer-Grpc-Port"
+
+// the load api sends the load also in these headers, for the peers which do not read the load document
+const HttpHeaderP2PFaaSLoad = "X-P2PFaaS-Load"
+const HttpHeaderP2PFaaSMaxLoad = "X-P2PFaaS-MaxLoad"
+const HttpHeaderP2PFaaSQueueLength = "X-P2PFog-Queue-Length"
+const HttpHeaderP2PFaaSFunctions = "X-P2PFaaS-Functions"
+
+// HttpHeaderP2PFaaSFunctionsUnavailable lists the functions whose circuit breaker is open, peers must not send them to
+// the node
+const HttpHeaderP2PFaaSFunctionsUnavailable = "X-P2PFaaS-Functions-Unavailable"
+
+// HttpHeaderP2PFaaSFunctionsWarm lists the functions which do not pay a cold start on the node
+const HttpHeaderP2PFaaSFunctionsWarm = "X-P2PFaaS-Functions-Warm"
+
+// HttpHeaderP2PFaaSPeerJob carries the job request or response as JSON in the binary peer protocol
+const HttpHeaderP2PFaaSPeerJob = "X-P2pfaas-Peer-Job"
+
+// HttpHeaderP2PFaaSCacheHit is set when the reply is the memoized result of a previous execution
+const HttpHeaderP2PFaaSCacheHit = "X-P2pfaas-Cache-Hit"
+
+// HttpHeaderCallbackUrl is the url to which the result of an async execution is posted
+const HttpHeaderCallbackUrl = "X-Callback-Url"
+const HttpHeaderCallId = "X-Call-Id"
+const HttpHeaderFunctionStatus = "X-Function-Status"
+const HttpHeaderFunctionName = "X-Function-Name"
+
 type ErrorHttpCannotCreateRequest struct{}
 
 func (e ErrorHttpCannotCreateRequest) Error() string {
@@ -73,6 +123,33 @@ type HttpHeader struct {
  */
 
 func HttpPost(url string, payload []byte, contentType string) (*http.Response, error) {
+	return httpPost(httpClient, context.Background(), url, payload, contentType)
+}
+
+// HttpPostWithContext posts the payload without a timeout, the request is aborted when the context is done
+func HttpPostWithContext(ctx context.Context, url string, payload []byte, contentType string) (*http.Response, error) {
+	return httpPost(functionHttpClient, ctx, url, payload, contentType)
+}
+
+func httpPost(client *http.Client, ctx context.Context, url string, payload []byte, contentType string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(payload))
+	if err != nil {
+		return nil, ErrorHttpCannotCreateRequest{}
+	}
+
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		log.Log.Debugf("cannot POST to %s: %s", url, err.Error())
+	}
+
+	return res, err
+}
+
+func HttpPostWithHeaders(url string, payload []byte, contentType string, headers []HttpHeader) (*http.Response, error) {
 	req, err := http.NewRequest("POST", url, bytes.NewBuffer(payload))
 	if err != nil {
 		return nil, ErrorHttpCannotCreateRequest{}
@@ -82,6 +159,11 @@ func HttpPost(url string, payload []byte, contentType string) (*http.Response, e
 		req.Header.Set("Content-Type", contentType)
 	}
 
+	// set the headers
+	for _, h := range headers {
+		req.Header.Add(h.Key, h.Value)
+	}
+
 	res, err := httpClient.Do(req)
 	if err != nil {
 		log.Log.Debugf("cannot POST to %s: %s", url, err.Error())
@@ -106,12 +188,21 @@ func HttpPostJSON(url string, json string) (*http.Response, error) {
 }
 
 func HttpGet(url string) (*http.Response, error) {
-	req, err := http.NewRequest("GET", url, nil)
+	return httpGet(httpClient, context.Background(), url)
+}
+
+// HttpGetWithContext gets the passed url without a timeout, the request is aborted when the context is done
+func HttpGetWithContext(ctx context.Context, url string) (*http.Response, error) {
+	return httpGet(functionHttpClient, ctx, url)
+}
+
+func httpGet(client *http.Client, ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if req == nil {
 		return nil, ErrorHttpCannotCreateRequest{}
 	}
 
-	res, err := httpClient.Do(req)
+	res, err := client.Do(req)
 	if err != nil {
 		log.Log.Debugf("Cannot GET to %s: %s", url, err.Error())
 	}
@@ -147,7 +238,7 @@ func HttpMachineGet(url string) (*http.Response, error) {
 
 	req.Header.Add("User-Agent", config.UserAgentMachine)
 
-	res, err := httpClient.Do(req)
+	res, err := doMachineRequest(req, nil)
 	if err != nil {
 		log.Log.Debugf("Cannot GET to %s: %s", url, err.Error())
 	}
@@ -164,7 +255,7 @@ func HttpMachinePostJSON(url string, json string) (*http.Response, error) {
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Add("User-Agent", config.UserAgentMachine)
 
-	res, err := httpClient.Do(req)
+	res, err := doMachineRequest(req, []byte(json))
 	if err != nil {
 		log.Log.Debugf("Cannot POST to %s: %s", url, err.Error())
 	}
@@ -188,7 +279,32 @@ func HttpMachinePostJSONWithHeaders(url string, json string, headers *[]HttpHead
 		}
 	}
 	
-	res, err := httpClient.Do(req)
+	res, err := doMachineRequest(req, []byte(json))
+	if err != nil {
+		log.Log.Debugf("Cannot POST to %s: %s", url, err.Error())
+	}
+
+	return res, err
+}
+
+// HttpMachinePostWithHeaders posts the payload as raw bytes to another machine
+func HttpMachinePostWithHeaders(url string, payload []byte, contentType string, headers []HttpHeader) (*http.Response, error) {
+	req, err := http.NewRequest("POST", url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, ErrorHttpCannotCreateRequest{}
+	}
+
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Add("User-Agent", config.UserAgentMachine)
+
+	// set the headers
+	for _, h := range headers {
+		req.Header.Add(h.Key, h.Value)
+	}
+
+	res, err := doMachineRequest(req, payload)
 	if err != nil {
 		log.Log.Debugf("Cannot POST to %s: %s", url, err.Error())
 	}
@@ -0,0 +1,127 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package utils
+
+import (
+	"context"
+	"crypto/tls"
+	"golang.org/x/net/http2"
+	"net"
+	"net/http"
+	"scheduler/config"
+	"scheduler/mtls"
+	"scheduler/signature"
+	"scheduler/types"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// machineHttpClient is used for the requests to other machines, it keeps a pool of idle connections to every peer
+var machineHttpClient *http.Client
+
+// machineHttp2Client sends the requests to other machines over h2c, or over http/2 with tls when mtls is enabled, so
+// that probes and forwards to the same peer are multiplexed on a single connection
+var machineHttp2Client *http.Client
+
+var machinesHttp2 = make(map[string]bool)
+var machinesHttp2Mutex sync.RWMutex
+
+func init() {
+	idleConnTimeout := time.Duration(config.GetPeerIdleConnTimeout()) * time.Millisecond
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+
+	machineHttpClient = &http.Client{
+		Transport: &http.Transport{
+			DisableKeepAlives:   !config.GetPeerKeepAlive(),
+			DialContext:         dialer.DialContext,
+			MaxIdleConnsPerHost: int(config.GetPeerMaxIdleConnsPerHost()),
+			IdleConnTimeout:     idleConnTimeout,
+			TLSClientConfig:     getTlsClientConfig(),
+		},
+		Timeout: 30 * time.Second,
+	}
+
+	http2Transport := &http2.Transport{
+		// idle connections are closed by the peer after its idle timeout, while a connection to a peer which left
+		// the network is detected by pinging it
+		ReadIdleTimeout: 15 * time.Second,
+		PingTimeout:     5 * time.Second,
+	}
+	if mtls.Enabled() {
+		http2Transport.TLSClientConfig = mtls.ClientConfig()
+	} else {
+		// h2c is plain http/2, so the tls dial is replaced with a plain one
+		http2Transport.AllowHTTP = true
+		http2Transport.DialTLSContext = func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			return dialer.DialContext(ctx, network, addr)
+		}
+	}
+
+	machineHttp2Client = &http.Client{
+		Transport: http2Transport,
+		Timeout:   30 * time.Second,
+	}
+}
+
+// IsMachineHttp2Enabled tells if this node can accept requests from other machines over h2c
+func IsMachineHttp2Enabled() bool {
+	return config.GetPeerKeepAlive() && config.GetPeerHttp2Enabled()
+}
+
+// SetMachineHttp2 records if the machine accepts requests over h2c
+func SetMachineHttp2(ip string, enabled bool) {
+	machinesHttp2Mutex.Lock()
+	defer machinesHttp2Mutex.Unlock()
+
+	if enabled {
+		machinesHttp2[ip] = true
+	} else {
+		delete(machinesHttp2, ip)
+	}
+}
+
+// doMachineRequest sends the request to another machine, signing it when signatures are enabled. The body must be the
+// one of the request.
+func doMachineRequest(req *http.Request, body []byte) (*http.Response, error) {
+	req.Header.Set(HttpHeaderP2PFaaSProtocolVersion, strconv.Itoa(types.ProtocolVersion))
+	if signature.Enabled() {
+		signature.SignRequest(req, body)
+	}
+	return getMachineHttpClient(req).Do(req)
+}
+
+// getMachineHttpClient returns the client for the request to another machine, h2c is used only when both this node
+// and the machine enabled it
+func getMachineHttpClient(req *http.Request) *http.Client {
+	if !IsMachineHttp2Enabled() {
+		return machineHttpClient
+	}
+
+	machinesHttp2Mutex.RLock()
+	defer machinesHttp2Mutex.RUnlock()
+
+	if machinesHttp2[req.URL.Hostname()] {
+		return machineHttp2Client
+	}
+	return machineHttpClient
+}
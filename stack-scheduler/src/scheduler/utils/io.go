@@ -0,0 +1,119 @@
+/*
+ * P2PFaaS - A framework for FaaS Load Balancing
+ * Copyright (c) 2019 - 2022. Gabriele Proietti Mattia <pm.gabriele@outlook.com>
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package utils
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+type ErrorBodyTooLarge struct {
+	MaxSize int64
+}
+
+func (e ErrorBodyTooLarge) Error() string {
+	return fmt.Sprintf("body exceeds the maximum size of %d bytes", e.MaxSize)
+}
+
+// LimitedReader reads from the underlying reader and fails with ErrorBodyTooLarge when more than maxSize bytes are
+// read, a maxSize of 0 disables the limit
+type LimitedReader struct {
+	reader   io.Reader
+	maxSize  int64
+	read     int64
+	exceeded bool
+}
+
+func NewLimitedReader(reader io.Reader, maxSize int64) *LimitedReader {
+	return &LimitedReader{reader: reader, maxSize: maxSize}
+}
+
+func (l *LimitedReader) Read(p []byte) (int, error) {
+	if l.exceeded {
+		return 0, ErrorBodyTooLarge{l.maxSize}
+	}
+	if l.maxSize > 0 && int64(len(p)) > l.maxSize-l.read+1 {
+		// read at most one byte more than allowed for detecting if the limit is exceeded
+		p = p[:l.maxSize-l.read+1]
+	}
+
+	n, err := l.reader.Read(p)
+	l.read += int64(n)
+	if l.maxSize > 0 && l.read > l.maxSize {
+		l.exceeded = true
+		return n - int(l.read-l.maxSize), ErrorBodyTooLarge{l.maxSize}
+	}
+	return n, err
+}
+
+// Exceeded tells if the reader has been read beyond its limit
+func (l *LimitedReader) Exceeded() bool {
+	return l.exceeded
+}
+
+// ClosableReader is a reader which can be detached from the underlying reader, after Close is called reads fail. This
+// is used for not reading the body of an http request after its handler returned.
+type ClosableReader struct {
+	reader io.Reader
+	closed bool
+	mutex  sync.Mutex
+}
+
+func NewClosableReader(reader io.Reader) *ClosableReader {
+	return &ClosableReader{reader: reader}
+}
+
+func (c *ClosableReader) Read(p []byte) (int, error) {
+	c.mutex.Lock()
+	closed := c.closed
+	c.mutex.Unlock()
+
+	if closed {
+		return 0, io.ErrClosedPipe
+	}
+	return c.reader.Read(p)
+}
+
+func (c *ClosableReader) Close() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.closed = true
+	return nil
+}
+
+// readCloserWithCallback calls the callback once when the reader is closed
+type readCloserWithCallback struct {
+	io.ReadCloser
+	callback func()
+	once     sync.Once
+}
+
+// NewReadCloserWithCallback returns a ReadCloser which calls the callback after the passed one has been closed, the
+// callback is called only once even if Close is called many times
+func NewReadCloserWithCallback(readCloser io.ReadCloser, callback func()) io.ReadCloser {
+	return &readCloserWithCallback{ReadCloser: readCloser, callback: callback}
+}
+
+func (r *readCloserWithCallback) Close() error {
+	err := r.ReadCloser.Close()
+	r.once.Do(r.callback)
+	return err
+}
@@ -19,6 +19,9 @@
 package utils
 
 import (
+	crand "crypto/rand"
+	"encoding/hex"
+	"fmt"
 	"math/rand"
 	"time"
 )
@@ -31,3 +34,10 @@ func GetRandomInteger(max int) int {
 	randomGenerator := rand.New(randomSource)
 	return randomGenerator.Int() % max
 }
+
+// GenerateUniqueId returns a random id which is unique with high probability, also across restarts
+func GenerateUniqueId() string {
+	randomBytes := make([]byte, 8)
+	_, _ = crand.Read(randomBytes)
+	return fmt.Sprintf("%x-%s", time.Now().UnixNano(), hex.EncodeToString(randomBytes))
+}
@@ -20,13 +20,19 @@
 package utils
 
 import (
+	"crypto/tls"
 	"net"
 	"net/http"
+	"scheduler/mtls"
 	"time"
 )
 
 var httpClient *http.Client
 
+// functionHttpClient is the client of the calls to the functions, it has no timeout since the calls are aborted when
+// the execution timeout of the function expires
+var functionHttpClient *http.Client
+
 func init() {
 	httpClient = &http.Client{
 		Transport: &http.Transport{
@@ -34,7 +40,25 @@ func init() {
 			DialContext: (&net.Dialer{
 				Timeout: 30 * time.Second,
 			}).DialContext,
+			TLSClientConfig: getTlsClientConfig(),
 		},
 		Timeout: 30 * time.Second,
 	}
+	functionHttpClient = &http.Client{
+		Transport: &http.Transport{
+			DisableKeepAlives: true,
+			DialContext: (&net.Dialer{
+				Timeout: 30 * time.Second,
+			}).DialContext,
+			TLSClientConfig: getTlsClientConfig(),
+		},
+	}
+}
+
+// getTlsClientConfig returns the tls configuration for the requests to the other services, nil if tls is disabled
+func getTlsClientConfig() *tls.Config {
+	if !mtls.Enabled() {
+		return nil
+	}
+	return mtls.ClientConfig()
 }